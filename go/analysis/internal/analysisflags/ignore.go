@@ -0,0 +1,54 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/internal/analysisinternal"
+)
+
+// IgnoreAnalyzer is the pseudo-analyzer under which the drivers report
+// the unused and malformed //lint:ignore directives. It is never run.
+var IgnoreAnalyzer = &analysis.Analyzer{
+	Name: analysisinternal.IgnoreAnalyzerName,
+	Doc: `report unused and malformed //lint:ignore directives
+
+A directive of the form
+
+	//lint:ignore analyzer[,analyzer...] reason
+
+suppresses the diagnostics of the named analyzers on its line, or on the
+next line if it is on a line of its own, or in the whole declaration if
+it is in the doc comment of a declaration. The reason is required.`,
+}
+
+// Ignore removes from the diagnostics of each analyzer run on a package
+// those suppressed by the //lint:ignore directives of its files, and
+// returns the diagnostics of the unused and malformed directives.
+//
+// The results map each analyzer run successfully on the package to its
+// diagnostics, and are updated in place.
+func Ignore(fset *token.FileSet, files []*ast.File, results map[*analysis.Analyzer][]analysis.Diagnostic) []analysis.Diagnostic {
+	ig := analysisinternal.ParseIgnores(fset, files)
+	ran := make(map[string]bool)
+	for a, diags := range results {
+		ran[a.Name] = true
+		var kept []analysis.Diagnostic
+		for _, diag := range diags {
+			if !ig.Ignored(a.Name, fset.PositionFor(diag.Pos, false)) {
+				kept = append(kept, diag)
+			}
+		}
+		results[a] = kept
+	}
+	var problems []analysis.Diagnostic
+	for _, p := range ig.Problems(func(name string) bool { return ran[name] }) {
+		problems = append(problems, analysis.Diagnostic{Pos: p.Pos, End: p.End, Message: p.Message})
+	}
+	return problems
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/analysis/internal/analysisflags"
+)
+
+func TestIgnore(t *testing.T) {
+	const src = `package p
+
+func f() {
+	var bar string //lint:ignore a,b reason
+	//lint:ignore a reason
+	_ = bar
+	_ = bar
+	if bar != "" { //lint:ignore b reason
+		_ = bar
+	} //lint:ignore a
+}
+
+// g is ignored.
+//
+//lint:ignore a the whole declaration
+func g() {
+	var bar string
+	_ = bar
+}
+
+//lint:ignore
+var _ = 0 //lint:ignore c,d reason
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both analyzers report each occurrence of bar.
+	var diags []analysis.Diagnostic
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == "bar" {
+			diags = append(diags, analysis.Diagnostic{Pos: id.Pos(), End: id.End(), Message: "bar"})
+		}
+		return true
+	})
+	a := &analysis.Analyzer{Name: "a"}
+	b := &analysis.Analyzer{Name: "b"}
+	c := &analysis.Analyzer{Name: "c"}
+	results := map[*analysis.Analyzer][]analysis.Diagnostic{a: diags, b: diags, c: nil}
+
+	problems := analysisflags.Ignore(fset, []*ast.File{f}, results)
+
+	lines := func(diags []analysis.Diagnostic) []int {
+		var lines []int
+		for _, d := range diags {
+			lines = append(lines, fset.Position(d.Pos).Line)
+		}
+		return lines
+	}
+	for _, test := range []struct {
+		a    *analysis.Analyzer
+		want []int
+	}{
+		{a, []int{7, 8, 9}},
+		{b, []int{6, 7, 9, 17, 18}},
+		{c, nil},
+	} {
+		if got := lines(results[test.a]); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("lines of the diagnostics of %s: got %v, want %v", test.a.Name, got, test.want)
+		}
+	}
+
+	type problem struct {
+		line int
+		msg  string
+	}
+	var got []problem
+	for _, p := range problems {
+		got = append(got, problem{fset.Position(p.Pos).Line, p.Message})
+	}
+	want := []problem{
+		{10, "//lint:ignore directive for a lacks a reason"},
+		{21, "malformed //lint:ignore directive: want //lint:ignore analyzer reason"},
+		{22, "this //lint:ignore directive never matches a diagnostic of c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got problems %v, want %v", got, want)
+	}
+}
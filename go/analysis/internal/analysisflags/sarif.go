@@ -0,0 +1,363 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kent0106/gotools/go/analysis"
+)
+
+// A SARIFLog accumulates the results of analyses and prints them as a
+// log in the Static Analysis Results Interchange Format (SARIF) 2.1.0.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+//
+// The zero value is an empty log ready to use.
+type SARIFLog struct {
+	rules         []sarifRule
+	ruleIndex     map[string]int // index in rules of each analyzer name
+	results       []sarifResult
+	seen          map[string]bool // keys of the results, for de-duplication
+	notifications []sarifNotification
+	files         map[string][]byte // contents of the files, for columns
+}
+
+// Add adds the result of analyzer a on a package.
+// The result is either a list of diagnostics or an error.
+//
+// Diagnostics reported at the same location by the same analyzer with
+// the same message, as in the source files that belong to multiple
+// packages such as foo and foo.test, are added only once.
+func (l *SARIFLog) Add(fset *token.FileSet, a *analysis.Analyzer, diags []analysis.Diagnostic, err error) {
+	index := l.rule(a)
+	if err != nil {
+		l.notifications = append(l.notifications, sarifNotification{
+			Level:          "error",
+			Message:        sarifMessage{Text: err.Error()},
+			AssociatedRule: &sarifReference{ID: a.Name, Index: index},
+		})
+		return
+	}
+	for _, diag := range diags {
+		res := sarifResult{
+			RuleID:    a.Name,
+			RuleIndex: index,
+			Level:     "warning",
+			Message:   sarifMessage{Text: diag.Message},
+			Locations: []sarifLocation{l.location(fset, diag.Pos, diag.End, "")},
+		}
+		if diag.Category != "" {
+			res.Properties = map[string]string{"category": diag.Category}
+		}
+		for _, rel := range diag.Related {
+			res.RelatedLocations = append(res.RelatedLocations, l.location(fset, rel.Pos, rel.End, rel.Message))
+		}
+		for _, fix := range diag.SuggestedFixes {
+			res.Fixes = append(res.Fixes, l.fix(fset, fix))
+		}
+
+		data, err := json.Marshal(res)
+		if err != nil {
+			log.Panicf("internal error: SARIF marshaling failed: %v", err)
+		}
+		if l.seen == nil {
+			l.seen = make(map[string]bool)
+		}
+		if key := string(data); !l.seen[key] {
+			l.seen[key] = true
+			l.results = append(l.results, res)
+		}
+	}
+}
+
+// Len returns the number of results in the log.
+func (l *SARIFLog) Len() int { return len(l.results) }
+
+// Print prints the log to stdout.
+func (l *SARIFLog) Print() {
+	if err := l.Write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Write writes the log to w.
+func (l *SARIFLog) Write(w io.Writer) error {
+	results := l.results
+	if results == nil {
+		// An empty list of results means that no problems were found.
+		results = []sarifResult{}
+	}
+	rules := l.rules
+	if rules == nil {
+		rules = []sarifRule{}
+	}
+	doc := sarifDoc{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:  strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe"),
+				Rules: rules,
+			}},
+			Invocations: []sarifInvocation{{
+				ExecutionSuccessful:        len(l.notifications) == 0,
+				ToolExecutionNotifications: l.notifications,
+			}},
+			ColumnKind: "utf16CodeUnits",
+			Results:    results,
+		}},
+	}
+	data, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		log.Panicf("internal error: SARIF marshaling failed: %v", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// rule returns the index of the rule describing analyzer a, adding it to
+// the log if needed.
+func (l *SARIFLog) rule(a *analysis.Analyzer) int {
+	if i, ok := l.ruleIndex[a.Name]; ok {
+		return i
+	}
+	if l.ruleIndex == nil {
+		l.ruleIndex = make(map[string]int)
+	}
+	i := len(l.rules)
+	l.ruleIndex[a.Name] = i
+	l.rules = append(l.rules, sarifRule{
+		ID:               a.Name,
+		Name:             a.Name,
+		ShortDescription: sarifMessage{Text: strings.Split(a.Doc, "\n\n")[0]},
+		FullDescription:  sarifMessage{Text: a.Doc},
+	})
+	return i
+}
+
+// location returns the location of the range [pos, end), which is the
+// position pos alone if end is invalid.
+func (l *SARIFLog) location(fset *token.FileSet, pos, end token.Pos, message string) sarifLocation {
+	posn := fset.Position(pos)
+	loc := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: fileURI(posn.Filename)},
+			Region:           l.region(fset, pos, end),
+		},
+	}
+	if message != "" {
+		loc.Message = &sarifMessage{Text: message}
+	}
+	return loc
+}
+
+// fix returns the SARIF form of a suggested fix, whose edits are grouped
+// by file.
+func (l *SARIFLog) fix(fset *token.FileSet, fix analysis.SuggestedFix) sarifFix {
+	changes := make(map[string]*sarifArtifactChange)
+	var files []string
+	for _, edit := range fix.TextEdits {
+		end := edit.End
+		if !end.IsValid() {
+			end = edit.Pos // pure insertion
+		}
+		filename := fset.Position(edit.Pos).Filename
+		change, ok := changes[filename]
+		if !ok {
+			change = &sarifArtifactChange{
+				ArtifactLocation: sarifArtifactLocation{URI: fileURI(filename)},
+			}
+			changes[filename] = change
+			files = append(files, filename)
+		}
+		change.Replacements = append(change.Replacements, sarifReplacement{
+			DeletedRegion:   l.region(fset, edit.Pos, end),
+			InsertedContent: &sarifArtifactContent{Text: string(edit.NewText)},
+		})
+	}
+	sort.Strings(files)
+	res := sarifFix{Description: sarifMessage{Text: fix.Message}}
+	for _, filename := range files {
+		res.ArtifactChanges = append(res.ArtifactChanges, *changes[filename])
+	}
+	return res
+}
+
+// region returns the region of the range [pos, end), which is the
+// position pos alone if end is invalid. Its columns are counted in
+// UTF-16 code units, as SARIF requires by default, when the file can be
+// read, and in bytes otherwise.
+func (l *SARIFLog) region(fset *token.FileSet, pos, end token.Pos) sarifRegion {
+	posn := fset.Position(pos)
+	r := sarifRegion{
+		StartLine:   posn.Line,
+		StartColumn: l.column(posn),
+		ByteOffset:  posn.Offset,
+	}
+	if end.IsValid() {
+		endPosn := fset.Position(end)
+		r.EndLine = endPosn.Line
+		r.EndColumn = l.column(endPosn)
+		length := endPosn.Offset - posn.Offset
+		r.ByteLength = &length
+	}
+	return r
+}
+
+// column returns the column of posn in UTF-16 code units.
+func (l *SARIFLog) column(posn token.Position) int {
+	content, ok := l.files[posn.Filename]
+	if !ok {
+		content, _ = ioutil.ReadFile(posn.Filename)
+		if l.files == nil {
+			l.files = make(map[string][]byte)
+		}
+		l.files[posn.Filename] = content
+	}
+	start := posn.Offset - (posn.Column - 1)
+	if start < 0 || posn.Offset > len(content) {
+		return posn.Column
+	}
+	col := 1
+	for line := content[start:posn.Offset]; len(line) > 0; {
+		r, size := utf8.DecodeRune(line)
+		if r >= 0x10000 {
+			col += 2 // surrogate pair
+		} else {
+			col++
+		}
+		line = line[size:]
+	}
+	return col
+}
+
+// fileURI returns the file URI of filename.
+func fileURI(filename string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	path := filepath.ToSlash(filename)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // a Windows path, such as C:/foo
+	}
+	u := url.URL{Scheme: "file", Path: path}
+	return u.String()
+}
+
+// SARIF log types.
+// Only the properties used by the analysis drivers are declared.
+
+type sarifDoc struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	ColumnKind  string            `json:"columnKind"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level          string          `json:"level"`
+	Message        sarifMessage    `json:"message"`
+	AssociatedRule *sarifReference `json:"associatedRule,omitempty"`
+}
+
+type sarifReference struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string            `json:"ruleId"`
+	RuleIndex        int               `json:"ruleIndex"`
+	Level            string            `json:"level"`
+	Message          sarifMessage      `json:"message"`
+	Locations        []sarifLocation   `json:"locations"`
+	RelatedLocations []sarifLocation   `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix        `json:"fixes,omitempty"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int  `json:"startLine"`
+	StartColumn int  `json:"startColumn"`
+	EndLine     int  `json:"endLine,omitempty"`
+	EndColumn   int  `json:"endColumn,omitempty"`
+	ByteOffset  int  `json:"byteOffset"`
+	ByteLength  *int `json:"byteLength,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion           `json:"deletedRegion"`
+	InsertedContent *sarifArtifactContent `json:"insertedContent,omitempty"`
+}
+
+type sarifArtifactContent struct {
+	Text string `json:"text"`
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/analysis/internal/analysisflags"
+)
+
+func TestSARIF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "a.go")
+	src := "package a\n\nvar s = \"😀\" + x\n"
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	file := fset.AddFile(filename, -1, len(src))
+	file.SetLinesForContent([]byte(src))
+	pos := func(substr string) token.Pos {
+		return file.Pos(strings.Index(src, substr))
+	}
+
+	a := &analysis.Analyzer{Name: "a", Doc: "check a\n\nThe a analyzer checks a."}
+	b := &analysis.Analyzer{Name: "b", Doc: "check b"}
+	diag := analysis.Diagnostic{
+		Pos:      pos("x"),
+		End:      pos("x") + 1,
+		Category: "cat",
+		Message:  "undeclared x",
+		Related: []analysis.RelatedInformation{
+			{Pos: pos("s"), End: pos("s") + 1, Message: "s"},
+		},
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "remove x",
+			TextEdits: []analysis.TextEdit{
+				{Pos: pos(" + x"), End: pos(" + x") + 4},
+				{Pos: pos("var"), NewText: []byte("// x\n")},
+			},
+		}},
+	}
+	var log analysisflags.SARIFLog
+	log.Add(fset, a, []analysis.Diagnostic{diag}, nil)
+	log.Add(fset, a, []analysis.Diagnostic{diag}, nil) // duplicate
+	log.Add(fset, b, nil, errors.New("b failed"))
+	if got := log.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+
+	var buf bytes.Buffer
+	if err := log.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.Bytes())
+	}
+	// get returns the value at the given path of keys and indexes.
+	get := func(path ...interface{}) interface{} {
+		var v interface{} = doc
+		for _, p := range path {
+			switch p := p.(type) {
+			case string:
+				v = v.(map[string]interface{})[p]
+			case int:
+				v = v.([]interface{})[p]
+			}
+		}
+		return v
+	}
+	uri := "file://" + filepath.ToSlash(filename)
+	for _, test := range []struct {
+		path []interface{}
+		want interface{}
+	}{
+		{[]interface{}{"version"}, "2.1.0"},
+		{[]interface{}{"runs", 0, "tool", "driver", "rules", 0, "id"}, "a"},
+		{[]interface{}{"runs", 0, "tool", "driver", "rules", 0, "shortDescription", "text"}, "check a"},
+		{[]interface{}{"runs", 0, "tool", "driver", "rules", 1, "id"}, "b"},
+		{[]interface{}{"runs", 0, "invocations", 0, "executionSuccessful"}, false},
+		{[]interface{}{"runs", 0, "invocations", 0, "toolExecutionNotifications", 0, "message", "text"}, "b failed"},
+		{[]interface{}{"runs", 0, "results", 0, "ruleId"}, "a"},
+		{[]interface{}{"runs", 0, "results", 0, "message", "text"}, "undeclared x"},
+		{[]interface{}{"runs", 0, "results", 0, "properties", "category"}, "cat"},
+		{[]interface{}{"runs", 0, "results", 0, "locations", 0, "physicalLocation", "artifactLocation", "uri"}, uri},
+		{[]interface{}{"runs", 0, "results", 0, "locations", 0, "physicalLocation", "region"}, map[string]interface{}{
+			// The emoji is two UTF-16 code units and four bytes long.
+			"startLine": 3.0, "startColumn": 16.0, "endLine": 3.0, "endColumn": 17.0, "byteOffset": 28.0, "byteLength": 1.0,
+		}},
+		{[]interface{}{"runs", 0, "results", 0, "relatedLocations", 0, "message", "text"}, "s"},
+		{[]interface{}{"runs", 0, "results", 0, "relatedLocations", 0, "physicalLocation", "region", "startColumn"}, 5.0},
+		{[]interface{}{"runs", 0, "results", 0, "fixes", 0, "description", "text"}, "remove x"},
+		{[]interface{}{"runs", 0, "results", 0, "fixes", 0, "artifactChanges", 0, "replacements"}, []interface{}{
+			map[string]interface{}{
+				"deletedRegion": map[string]interface{}{
+					"startLine": 3.0, "startColumn": 13.0, "endLine": 3.0, "endColumn": 17.0, "byteOffset": 25.0, "byteLength": 4.0,
+				},
+				"insertedContent": map[string]interface{}{"text": ""},
+			},
+			map[string]interface{}{
+				"deletedRegion": map[string]interface{}{
+					"startLine": 3.0, "startColumn": 1.0, "endLine": 3.0, "endColumn": 1.0, "byteOffset": 11.0, "byteLength": 0.0,
+				},
+				"insertedContent": map[string]interface{}{"text": "// x\n"},
+			},
+		}},
+	} {
+		if got := get(test.path...); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
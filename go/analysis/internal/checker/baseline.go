@@ -0,0 +1,200 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"sort"
+
+	"github.com/kent0106/gotools/go/analysis"
+)
+
+// A baseline records the diagnostics known to be reported on a code base,
+// which are not reported again. This allows the adoption of new analyzers
+// without first addressing all of their existing findings.
+//
+// Diagnostics are identified by a fingerprint of the name of the
+// analyzer, the path of the package, the declaration enclosing the
+// diagnostic and its message, but not its position, so that a baseline
+// remains valid as the lines of the files move. A baseline counts the
+// diagnostics of each fingerprint: when a declaration is reported more
+// times than recorded, all of its diagnostics are reported again.
+type baseline struct {
+	Version     int             `json:"version"`
+	Diagnostics []baselineEntry `json:"diagnostics"`
+}
+
+const baselineVersion = 1
+
+// A baselineEntry records the diagnostics of a fingerprint.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Analyzer    string `json:"analyzer"`
+	Package     string `json:"package"`
+	Decl        string `json:"decl,omitempty"`
+	Message     string `json:"message"`
+	Count       int    `json:"count"`
+}
+
+// readBaseline reads the baseline file filename and returns the number
+// of diagnostics recorded for each fingerprint.
+func readBaseline(filename string) (map[string]int, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("cannot decode baseline file %s: %v", filename, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("baseline file %s has version %d, want %d", filename, b.Version, baselineVersion)
+	}
+	counts := make(map[string]int)
+	for _, e := range b.Diagnostics {
+		counts[e.Fingerprint] += e.Count
+	}
+	return counts, nil
+}
+
+// writeBaseline writes the diagnostics of the root actions to the
+// baseline file filename.
+func writeBaseline(filename string, roots []*action) error {
+	entries := make(map[string]*baselineEntry)
+	forEachRootDiagnostic(roots, func(act *action, diag *analysis.Diagnostic) {
+		e := newBaselineEntry(act, diag)
+		if prev, ok := entries[e.Fingerprint]; ok {
+			prev.Count++
+		} else {
+			e.Count = 1
+			entries[e.Fingerprint] = &e
+		}
+	})
+	b := baseline{Version: baselineVersion, Diagnostics: []baselineEntry{}}
+	for _, e := range entries {
+		b.Diagnostics = append(b.Diagnostics, *e)
+	}
+	sort.Slice(b.Diagnostics, func(i, j int) bool {
+		x, y := b.Diagnostics[i], b.Diagnostics[j]
+		if x.Package != y.Package {
+			return x.Package < y.Package
+		}
+		if x.Analyzer != y.Analyzer {
+			return x.Analyzer < y.Analyzer
+		}
+		if x.Decl != y.Decl {
+			return x.Decl < y.Decl
+		}
+		return x.Message < y.Message
+	})
+	data, err := json.MarshalIndent(b, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// filterBaseline removes from the root actions the diagnostics recorded
+// in the baseline.
+func filterBaseline(roots []*action, counts map[string]int) {
+	// Count the diagnostics of each fingerprint first, so that all of
+	// them are reported when there are more than recorded.
+	found := make(map[string]int)
+	forEachRootDiagnostic(roots, func(act *action, diag *analysis.Diagnostic) {
+		found[newBaselineEntry(act, diag).Fingerprint]++
+	})
+	for _, act := range roots {
+		var diags []analysis.Diagnostic
+		for _, diag := range act.diagnostics {
+			fp := newBaselineEntry(act, &diag).Fingerprint
+			if found[fp] > counts[fp] {
+				diags = append(diags, diag)
+			}
+		}
+		act.diagnostics = diags
+	}
+}
+
+// forEachRootDiagnostic calls f for each diagnostic of the root actions,
+// once per position, analyzer and message so as to not count twice the
+// diagnostics in source files that belong to multiple packages, such as
+// foo and foo.test.
+func forEachRootDiagnostic(roots []*action, f func(*action, *analysis.Diagnostic)) {
+	type key struct {
+		pos token.Position
+		*analysis.Analyzer
+		message string
+	}
+	seen := make(map[key]bool)
+	for _, act := range roots {
+		for i := range act.diagnostics {
+			diag := &act.diagnostics[i]
+			k := key{act.pkg.Fset.Position(diag.Pos), act.a, diag.Message}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			f(act, diag)
+		}
+	}
+}
+
+// newBaselineEntry returns the baseline entry of a diagnostic of act.
+func newBaselineEntry(act *action, diag *analysis.Diagnostic) baselineEntry {
+	e := baselineEntry{
+		Analyzer: act.a.Name,
+		Package:  act.pkg.PkgPath,
+		Decl:     enclosingDecl(act.pkg.Syntax, diag.Pos),
+		Message:  diag.Message,
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s", e.Analyzer, e.Package, e.Decl, e.Message)
+	e.Fingerprint = fmt.Sprintf("%x", h.Sum(nil))
+	return e
+}
+
+// enclosingDecl returns a description of the top-level declaration
+// enclosing pos, such as "func (*T).M" or "type T", or "" if pos is not
+// within a declaration.
+func enclosingDecl(files []*ast.File, pos token.Pos) string {
+	for _, f := range files {
+		if pos < f.Pos() || pos > f.End() {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if pos < decl.Pos() || pos >= decl.End() {
+				continue
+			}
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					return fmt.Sprintf("func (%s).%s", types.ExprString(decl.Recv.List[0].Type), decl.Name.Name)
+				}
+				return "func " + decl.Name.Name
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if pos < spec.Pos() || pos >= spec.End() {
+						continue
+					}
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						return "type " + spec.Name.Name
+					case *ast.ValueSpec:
+						return decl.Tok.String() + " " + spec.Names[0].Name
+					}
+				}
+				return decl.Tok.String()
+			}
+		}
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/analysis/analysistest"
+	"github.com/kent0106/gotools/go/analysis/internal/checker"
+	"github.com/kent0106/gotools/internal/testenv"
+)
+
+func TestBaseline(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	from = "bar"
+	to = "baz"
+	defer func() {
+		checker.Baseline = ""
+		checker.WriteBaseline = ""
+	}()
+	checker.Fix = false
+
+	files := map[string]string{
+		"baseline/test.go": `package baseline
+
+func Foo() {
+	var bar string
+	_ = bar
+}
+`}
+	testdata, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	path := filepath.Join(testdata, "src/baseline/test.go")
+	baseline := filepath.Join(testdata, "baseline.json")
+	run := func(src string) int {
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return checker.Run([]string{"file=" + path}, []*analysis.Analyzer{analyzer})
+	}
+
+	checker.WriteBaseline = baseline
+	if got := run(files["baseline/test.go"]); got != 0 {
+		t.Fatalf("writing the baseline: exit code %d, want 0", got)
+	}
+	data, err := ioutil.ReadFile(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b struct {
+		Diagnostics []struct {
+			Analyzer, Package, Decl, Message string
+			Count                            int
+		}
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Diagnostics) != 1 {
+		t.Fatalf("got %d baseline entries, want 1:\n%s", len(b.Diagnostics), data)
+	}
+	if e := b.Diagnostics[0]; e.Analyzer != "rename" || e.Decl != "func Foo" || e.Count != 2 {
+		t.Errorf("unexpected baseline entry %+v", e)
+	}
+
+	checker.WriteBaseline = ""
+	checker.Baseline = baseline
+	for _, test := range []struct {
+		src  string
+		want int
+	}{
+		// The lines of the diagnostics moved.
+		{`package baseline
+
+// Foo renames.
+func Foo() {
+
+	var bar string
+	_ = bar
+}
+`, 0},
+		// A new diagnostic in another declaration.
+		{`package baseline
+
+func Foo() {
+	var bar string
+	_ = bar
+}
+
+func Qux() {
+	var bar string
+	_ = bar
+}
+`, 3},
+		// A new diagnostic in the same declaration.
+		{`package baseline
+
+func Foo() {
+	var bar string
+	_ = bar
+	_ = bar
+}
+`, 3},
+	} {
+		if got := run(test.src); got != test.want {
+			t.Errorf("exit code %d, want %d, for:\n%s", got, test.want, test.src)
+		}
+	}
+}
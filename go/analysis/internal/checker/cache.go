@@ -0,0 +1,516 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"flag"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/packages"
+	"github.com/kent0106/gotools/go/types/objectpath"
+)
+
+// The analysis cache records on disk the facts and diagnostics of the
+// actions on each package, so that repeated runs only reanalyze the
+// packages that changed and their dependents, as "go vet" does thanks to
+// the build cache.
+//
+// The entry of a package is keyed by a hash of the analysis tool, of the
+// analyzers applied to the package and their flags, and of the package,
+// which covers the contents of its files and, recursively, the hashes of
+// its imports. Either all the actions on a package are loaded from the
+// cache, or all are run: the results of the analyzers, which are
+// consumed by the analyzers requiring them, are not cached.
+
+// cacheVersion is changed when the format of the entries changes.
+const cacheVersion = "checker-cache-v1"
+
+// A packageCache is the cache entry of a package.
+type packageCache struct {
+	key     string // or "" if the package is not cacheable
+	pkg     *packages.Package
+	actions []*action
+
+	once    sync.Once
+	results map[*analysis.Analyzer]*cachedResults // on a hit
+}
+
+// cachedResults are the outputs of an action loaded from the cache.
+type cachedResults struct {
+	objectFacts  map[objectFactKey]analysis.Fact
+	packageFacts map[packageFactKey]analysis.Fact
+	diagnostics  []analysis.Diagnostic
+}
+
+// newPackageCaches returns the cache entries of the packages of the
+// actions, and associates each action with the entry of its package.
+func newPackageCaches(actions []*action) []*packageCache {
+	tool, err := toolID()
+	if err != nil {
+		log.Printf("disabling the analysis cache: %v", err)
+		return nil
+	}
+	byPkg := make(map[*packages.Package]*packageCache)
+	var caches []*packageCache
+	for _, act := range actions {
+		pc, ok := byPkg[act.pkg]
+		if !ok {
+			pc = &packageCache{pkg: act.pkg}
+			byPkg[act.pkg] = pc
+			caches = append(caches, pc)
+		}
+		pc.actions = append(pc.actions, act)
+		act.cache = pc
+	}
+
+	hashes := make(map[*packages.Package]string)
+	for _, pc := range caches {
+		hash := packageHash(pc.pkg, hashes)
+		if hash == "" {
+			continue
+		}
+		var names []string
+		analyzers := make(map[string]*analysis.Analyzer)
+		for _, act := range pc.actions {
+			names = append(names, act.a.Name)
+			analyzers[act.a.Name] = act.a
+		}
+		sort.Strings(names)
+		h := sha256.New()
+		fmt.Fprintf(h, "%s\n%s\n%s\n", cacheVersion, tool, hash)
+		for _, name := range names {
+			fmt.Fprintf(h, "analyzer %s\n", name)
+			analyzers[name].Flags.VisitAll(func(f *flag.Flag) {
+				fmt.Fprintf(h, "flag %s=%s\n", f.Name, f.Value)
+			})
+		}
+		pc.key = fmt.Sprintf("%x", h.Sum(nil))
+	}
+	return caches
+}
+
+// toolID returns a hash of the running executable.
+func toolID() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// packageHash returns a hash of the files of pkg and of the hashes of its
+// imports, or "" if pkg is not cacheable.
+func packageHash(pkg *packages.Package, hashes map[*packages.Package]string) string {
+	if hash, ok := hashes[pkg]; ok {
+		return hash
+	}
+	hashes[pkg] = "" // in case of an import cycle
+	if pkg.IllTyped {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "package %s %s\n", pkg.ID, pkg.PkgPath)
+	fmt.Fprintf(h, "sizes %v\n", pkg.TypesSizes)
+	for _, files := range [][]string{pkg.CompiledGoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+		for _, filename := range files {
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return ""
+			}
+			fmt.Fprintf(h, "file %s %x\n", filename, sha256.Sum256(data))
+		}
+	}
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		hash := packageHash(pkg.Imports[path], hashes)
+		if hash == "" {
+			return ""
+		}
+		fmt.Fprintf(h, "import %s %s\n", path, hash)
+	}
+	hash := fmt.Sprintf("%x", h.Sum(nil))
+	hashes[pkg] = hash
+	return hash
+}
+
+func (pc *packageCache) filename() string {
+	return filepath.Join(CacheDir, pc.key[:2], pc.key)
+}
+
+// loadCached loads the outputs of act from the cache, and reports
+// whether it succeeded.
+func (act *action) loadCached() bool {
+	pc := act.cache
+	if pc == nil || pc.key == "" {
+		return false
+	}
+	pc.once.Do(pc.load)
+	res := pc.results[act.a]
+	if res == nil {
+		return false
+	}
+	act.objectFacts = res.objectFacts
+	act.packageFacts = res.packageFacts
+	act.diagnostics = res.diagnostics
+	return true
+}
+
+// load reads and decodes the cache entry of the package, if any.
+func (pc *packageCache) load() {
+	data, err := ioutil.ReadFile(pc.filename())
+	if err != nil {
+		if dbg('v') {
+			log.Printf("cache miss for %s", pc.pkg)
+		}
+		return
+	}
+	var entry cacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		log.Printf("invalid cache entry for %s: %v", pc.pkg, err)
+		return
+	}
+	results, err := pc.decode(&entry)
+	if err != nil {
+		log.Printf("invalid cache entry for %s: %v", pc.pkg, err)
+		return
+	}
+	if dbg('v') {
+		log.Printf("cache hit for %s", pc.pkg)
+	}
+	pc.results = results
+}
+
+// store writes the cache entry of the package if all of its actions
+// were run successfully.
+func (pc *packageCache) store() {
+	if pc.key == "" || pc.results != nil {
+		return
+	}
+	for _, act := range pc.actions {
+		if act.pass == nil || act.err != nil {
+			return // not run, or failed
+		}
+	}
+	entry, err := pc.encode()
+	if err != nil {
+		if dbg('v') {
+			log.Printf("not caching %s: %v", pc.pkg, err)
+		}
+		return
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		log.Printf("not caching %s: %v", pc.pkg, err)
+		return
+	}
+	if err := writeFileAtomically(pc.filename(), buf.Bytes()); err != nil {
+		log.Printf("not caching %s: %v", pc.pkg, err)
+	}
+}
+
+// writeFileAtomically writes a file so that concurrent readers see
+// either no file or all of its contents.
+func writeFileAtomically(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// A cacheEntry is the serialized form of the outputs of the actions on
+// a package.
+type cacheEntry struct {
+	Actions []cachedAction
+}
+
+type cachedAction struct {
+	Analyzer    string
+	Facts       []cachedFact
+	Diagnostics []cachedDiagnostic
+}
+
+type cachedFact struct {
+	PkgPath string
+	Object  objectpath.Path // or "" for a package fact
+	Type    int             // index in the FactTypes of the analyzer
+	Data    []byte          // gob encoding of the fact
+}
+
+type cachedDiagnostic struct {
+	Pos, End       cachedPos
+	Category       string
+	Message        string
+	SuggestedFixes []cachedFix
+	Related        []cachedRelated
+}
+
+type cachedFix struct {
+	Message   string
+	TextEdits []cachedEdit
+}
+
+type cachedEdit struct {
+	Pos, End cachedPos
+	NewText  []byte
+}
+
+type cachedRelated struct {
+	Pos, End cachedPos
+	Message  string
+}
+
+// A cachedPos is a position in a file, or token.NoPos if File is empty.
+type cachedPos struct {
+	File   string
+	Offset int
+}
+
+// encode returns the cache entry of the actions on the package.
+func (pc *packageCache) encode() (*cacheEntry, error) {
+	fset := pc.pkg.Fset
+	pos := func(p token.Pos) cachedPos {
+		if !p.IsValid() {
+			return cachedPos{}
+		}
+		posn := fset.PositionFor(p, false)
+		return cachedPos{File: posn.Filename, Offset: posn.Offset}
+	}
+	entry := new(cacheEntry)
+	for _, act := range pc.actions {
+		ca := cachedAction{Analyzer: act.a.Name}
+
+		// Only the facts inherited by the dependents are recorded.
+		for key, fact := range act.objectFacts {
+			if !exportedFrom(key.obj, pc.pkg.Types) {
+				continue
+			}
+			path, err := objectpath.For(key.obj)
+			if err != nil {
+				continue // not accessible from the API of its package
+			}
+			cf, err := encodeFact(act.a, key.obj.Pkg().Path(), path, fact)
+			if err != nil {
+				return nil, err
+			}
+			ca.Facts = append(ca.Facts, cf)
+		}
+		for key, fact := range act.packageFacts {
+			cf, err := encodeFact(act.a, key.pkg.Path(), "", fact)
+			if err != nil {
+				return nil, err
+			}
+			ca.Facts = append(ca.Facts, cf)
+		}
+
+		for _, diag := range act.diagnostics {
+			cd := cachedDiagnostic{
+				Pos:      pos(diag.Pos),
+				End:      pos(diag.End),
+				Category: diag.Category,
+				Message:  diag.Message,
+			}
+			for _, fix := range diag.SuggestedFixes {
+				cf := cachedFix{Message: fix.Message}
+				for _, edit := range fix.TextEdits {
+					cf.TextEdits = append(cf.TextEdits, cachedEdit{pos(edit.Pos), pos(edit.End), edit.NewText})
+				}
+				cd.SuggestedFixes = append(cd.SuggestedFixes, cf)
+			}
+			for _, rel := range diag.Related {
+				cd.Related = append(cd.Related, cachedRelated{pos(rel.Pos), pos(rel.End), rel.Message})
+			}
+			ca.Diagnostics = append(ca.Diagnostics, cd)
+		}
+		entry.Actions = append(entry.Actions, ca)
+	}
+	return entry, nil
+}
+
+func encodeFact(a *analysis.Analyzer, pkgPath string, path objectpath.Path, fact analysis.Fact) (cachedFact, error) {
+	typ := -1
+	for i, f := range a.FactTypes {
+		if reflect.TypeOf(f) == reflect.TypeOf(fact) {
+			typ = i
+		}
+	}
+	if typ < 0 {
+		return cachedFact{}, fmt.Errorf("%s exported a %T fact, which is not among its FactTypes", a, fact)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(fact); err != nil {
+		return cachedFact{}, fmt.Errorf("encoding %T fact: %v", fact, err)
+	}
+	return cachedFact{PkgPath: pkgPath, Object: path, Type: typ, Data: buf.Bytes()}, nil
+}
+
+// decode returns the outputs of the actions on the package recorded in
+// the cache entry.
+func (pc *packageCache) decode(entry *cacheEntry) (map[*analysis.Analyzer]*cachedResults, error) {
+	// The packages about which facts may be recorded.
+	pkgs := make(map[string]*types.Package)
+	var addPackage func(pkg *types.Package)
+	addPackage = func(pkg *types.Package) {
+		if _, ok := pkgs[pkg.Path()]; !ok {
+			pkgs[pkg.Path()] = pkg
+			for _, imp := range pkg.Imports() {
+				addPackage(imp)
+			}
+		}
+	}
+	addPackage(pc.pkg.Types)
+
+	// The files in which diagnostics may be reported, which may include
+	// non-Go files not yet added to the file set.
+	fset := pc.pkg.Fset
+	var files map[string]*token.File
+	pos := func(p cachedPos) (token.Pos, error) {
+		if p.File == "" {
+			return token.NoPos, nil
+		}
+		if files == nil {
+			files = make(map[string]*token.File)
+			fset.Iterate(func(f *token.File) bool {
+				files[f.Name()] = f
+				return true
+			})
+		}
+		f := files[p.File]
+		if f == nil {
+			data, err := ioutil.ReadFile(p.File)
+			if err != nil {
+				return token.NoPos, err
+			}
+			f = fset.AddFile(p.File, -1, len(data))
+			f.SetLinesForContent(data)
+			files[p.File] = f
+		}
+		if p.Offset > f.Size() {
+			return token.NoPos, fmt.Errorf("offset %d out of range in %s", p.Offset, p.File)
+		}
+		return f.Pos(p.Offset), nil
+	}
+
+	analyzers := make(map[string]*analysis.Analyzer)
+	for _, act := range pc.actions {
+		analyzers[act.a.Name] = act.a
+	}
+	results := make(map[*analysis.Analyzer]*cachedResults)
+	for _, ca := range entry.Actions {
+		a := analyzers[ca.Analyzer]
+		if a == nil {
+			return nil, fmt.Errorf("unexpected analyzer %s", ca.Analyzer)
+		}
+		res := &cachedResults{
+			objectFacts:  make(map[objectFactKey]analysis.Fact),
+			packageFacts: make(map[packageFactKey]analysis.Fact),
+		}
+		for _, cf := range ca.Facts {
+			pkg := pkgs[cf.PkgPath]
+			if pkg == nil || cf.Type < 0 || cf.Type >= len(a.FactTypes) {
+				return nil, fmt.Errorf("invalid fact about %s", cf.PkgPath)
+			}
+			typ := reflect.TypeOf(a.FactTypes[cf.Type])
+			fact := reflect.New(typ.Elem()).Interface().(analysis.Fact)
+			if err := gob.NewDecoder(bytes.NewReader(cf.Data)).Decode(fact); err != nil {
+				return nil, fmt.Errorf("decoding %T fact: %v", fact, err)
+			}
+			if cf.Object == "" {
+				res.packageFacts[packageFactKey{pkg, typ}] = fact
+				continue
+			}
+			obj, err := objectpath.Object(pkg, cf.Object)
+			if err != nil {
+				return nil, err
+			}
+			res.objectFacts[objectFactKey{obj, typ}] = fact
+		}
+		for _, cd := range ca.Diagnostics {
+			var diag analysis.Diagnostic
+			var err error
+			if diag.Pos, err = pos(cd.Pos); err != nil {
+				return nil, err
+			}
+			if diag.End, err = pos(cd.End); err != nil {
+				return nil, err
+			}
+			diag.Category = cd.Category
+			diag.Message = cd.Message
+			for _, cf := range cd.SuggestedFixes {
+				fix := analysis.SuggestedFix{Message: cf.Message}
+				for _, ce := range cf.TextEdits {
+					edit := analysis.TextEdit{NewText: ce.NewText}
+					if edit.Pos, err = pos(ce.Pos); err != nil {
+						return nil, err
+					}
+					if edit.End, err = pos(ce.End); err != nil {
+						return nil, err
+					}
+					fix.TextEdits = append(fix.TextEdits, edit)
+				}
+				diag.SuggestedFixes = append(diag.SuggestedFixes, fix)
+			}
+			for _, cr := range cd.Related {
+				rel := analysis.RelatedInformation{Message: cr.Message}
+				if rel.Pos, err = pos(cr.Pos); err != nil {
+					return nil, err
+				}
+				if rel.End, err = pos(cr.End); err != nil {
+					return nil, err
+				}
+				diag.Related = append(diag.Related, rel)
+			}
+			res.diagnostics = append(res.diagnostics, diag)
+		}
+		results[a] = res
+	}
+	for _, act := range pc.actions {
+		if results[act.a] == nil {
+			return nil, fmt.Errorf("missing analyzer %s", act.a)
+		}
+	}
+	return results, nil
+}
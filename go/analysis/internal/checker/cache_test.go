@@ -0,0 +1,113 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/analysis/analysistest"
+	"github.com/kent0106/gotools/go/analysis/internal/checker"
+	"github.com/kent0106/gotools/internal/testenv"
+)
+
+func TestCache(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	files := map[string]string{
+		"a/a.go": `package a
+
+import "b"
+
+var _ = b.B
+`,
+		"b/b.go": `package b
+
+var B string
+`,
+	}
+	testdata, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	for _, env := range []string{"GOPATH", "GO111MODULE"} {
+		defer os.Setenv(env, os.Getenv(env))
+	}
+	os.Setenv("GOPATH", testdata)
+	os.Setenv("GO111MODULE", "off")
+
+	checker.Fix = false
+	checker.CacheDir = filepath.Join(testdata, "cache")
+	defer func() { checker.CacheDir = "" }()
+
+	for _, test := range []struct {
+		name     string
+		edit     string // file to edit before the run
+		analyzed []string
+	}{
+		{"first run", "", []string{"a", "b"}},
+		{"unchanged", "", nil},
+		{"changed a", "a/a.go", []string{"a"}},
+		{"changed b", "b/b.go", []string{"a", "b"}},
+		{"unchanged again", "", nil},
+	} {
+		if test.edit != "" {
+			path := filepath.Join(testdata, "src", test.edit)
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, append(data, "\n// edited\n"...), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		analyzed = nil
+		// The diagnostic of a depends on the fact about b.
+		if got := checker.Run([]string{"a"}, []*analysis.Analyzer{factAnalyzer}); got != 3 {
+			t.Errorf("%s: exit code %d, want 3", test.name, got)
+		}
+		sort.Strings(analyzed)
+		if !reflect.DeepEqual(analyzed, test.analyzed) {
+			t.Errorf("%s: analyzed %v, want %v", test.name, analyzed, test.analyzed)
+		}
+	}
+}
+
+var (
+	analyzedMu sync.Mutex
+	analyzed   []string // paths of the analyzed packages
+)
+
+// pkgFact records the name of a package.
+type pkgFact struct{ Name string }
+
+func (*pkgFact) AFact() {}
+
+var factAnalyzer = &analysis.Analyzer{
+	Name:      "importfact",
+	Doc:       "report the imports of packages",
+	FactTypes: []analysis.Fact{new(pkgFact)},
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		analyzedMu.Lock()
+		analyzed = append(analyzed, pass.Pkg.Path())
+		analyzedMu.Unlock()
+
+		pass.ExportPackageFact(&pkgFact{Name: pass.Pkg.Name()})
+		for _, imp := range pass.Pkg.Imports() {
+			var fact pkgFact
+			if pass.ImportPackageFact(imp, &fact) {
+				pass.Reportf(pass.Files[0].Package, "imports package %s", fact.Name)
+			}
+		}
+		return nil, nil
+	},
+}
@@ -25,6 +25,7 @@ import (
 	"runtime/pprof"
 	"runtime/trace"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +34,8 @@ import (
 	"github.com/kent0106/gotools/go/analysis/internal/analysisflags"
 	"github.com/kent0106/gotools/go/packages"
 	"github.com/kent0106/gotools/internal/analysisinternal"
+	"github.com/kent0106/gotools/internal/lsp/diff"
+	"github.com/kent0106/gotools/internal/lsp/diff/myers"
 	"github.com/kent0106/gotools/internal/span"
 )
 
@@ -50,8 +53,23 @@ var (
 	// Log files for optional performance tracing.
 	CPUProfile, MemProfile, Trace string
 
-	// Fix determines whether to apply all suggested fixes.
-	Fix bool
+	// Fix determines whether to apply all suggested fixes, or only those
+	// of the analyzers named in FixAnalyzers if it is not empty.
+	Fix          bool
+	FixAnalyzers []string
+
+	// Diff causes the suggested fixes to be printed as unified diffs
+	// instead of being applied.
+	Diff bool
+
+	// Baseline is the name of a baseline file recording the diagnostics
+	// not to report, and WriteBaseline the name of the baseline file to
+	// write all diagnostics to instead of reporting them.
+	Baseline, WriteBaseline string
+
+	// CacheDir is the directory of the on-disk cache of the facts and
+	// diagnostics of the analyzed packages, if any.
+	CacheDir string
 )
 
 // RegisterFlags registers command-line flags used by the analysis driver.
@@ -65,7 +83,46 @@ func RegisterFlags() {
 	flag.StringVar(&MemProfile, "memprofile", "", "write memory profile to this file")
 	flag.StringVar(&Trace, "trace", "", "write trace log to this file")
 
-	flag.BoolVar(&Fix, "fix", false, "apply all suggested fixes")
+	flag.Var(fixFlag{}, "fix", "apply all suggested fixes, or those of the analyzers named in a comma-separated list (-fix=name,...)")
+	flag.BoolVar(&Diff, "diff", false, "with -fix, print unified diffs of the fixes instead of applying them")
+
+	flag.StringVar(&Baseline, "baseline", "", "do not report the diagnostics recorded in this baseline file")
+	flag.StringVar(&WriteBaseline, "write-baseline", "", "write the diagnostics to this baseline file instead of reporting them")
+
+	flag.StringVar(&CacheDir, "cache", "", "cache the facts and diagnostics of the analyzed packages in this directory")
+}
+
+// fixFlag is the -fix flag, which is either a boolean or a
+// comma-separated list of the names of the analyzers whose fixes apply.
+type fixFlag struct{}
+
+func (fixFlag) IsBoolFlag() bool { return true }
+
+func (fixFlag) Get() interface{} { return Fix }
+
+func (fixFlag) String() string {
+	if Fix && len(FixAnalyzers) > 0 {
+		return strings.Join(FixAnalyzers, ",")
+	}
+	return strconv.FormatBool(Fix)
+}
+
+func (fixFlag) Set(s string) error {
+	if b, err := strconv.ParseBool(s); err == nil {
+		Fix, FixAnalyzers = b, nil
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no analyzer named in %q", s)
+	}
+	Fix, FixAnalyzers = true, names
+	return nil
 }
 
 // Run loads the packages specified by args using go/packages,
@@ -117,6 +174,18 @@ func Run(args []string, analyzers []*analysis.Analyzer) (exitcode int) {
 		}()
 	}
 
+	// Check the analyzers whose fixes apply.
+	for _, name := range FixAnalyzers {
+		found := false
+		for _, a := range analyzers {
+			found = found || a.Name == name
+		}
+		if !found {
+			log.Printf("-fix: no analyzer named %s", name)
+			return 1
+		}
+	}
+
 	// Load the packages.
 	if dbg('v') {
 		log.SetPrefix("")
@@ -135,6 +204,25 @@ func Run(args []string, analyzers []*analysis.Analyzer) (exitcode int) {
 
 	// Print the results.
 	roots := analyze(initial, analyzers)
+	roots = applyIgnores(roots)
+
+	if WriteBaseline != "" {
+		if err := writeBaseline(WriteBaseline, roots); err != nil {
+			log.Print(err)
+			return 1
+		}
+		// Report analysis errors only.
+		for _, act := range roots {
+			act.diagnostics = nil
+		}
+	} else if Baseline != "" {
+		counts, err := readBaseline(Baseline)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		filterBaseline(roots, counts)
+	}
 
 	if Fix {
 		applyFixes(roots)
@@ -258,127 +346,165 @@ func analyze(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action
 		}
 	}
 
+	// Associate the actions with the cache entries of their packages.
+	var caches []*packageCache
+	if CacheDir != "" {
+		all := make([]*action, 0, len(actions))
+		for _, act := range actions {
+			all = append(all, act)
+		}
+		caches = newPackageCaches(all)
+	}
+
 	// Execute the graph in parallel.
 	execAll(roots)
 
+	for _, pc := range caches {
+		pc.store()
+	}
+
 	return roots
 }
 
+// applyFixes applies the suggested fixes of the diagnostics of the root
+// actions, or prints them as unified diffs if Diff is set. If
+// FixAnalyzers is not empty, only the fixes of the named analyzers are
+// applied.
+//
+// A fix is applied entirely or not at all: a fix with an edit
+// overlapping an edit of a fix applied before it is not applied but
+// reported instead, as is a malformed fix. Fixes whose edits were all
+// applied already, such as those reported in source files that belong
+// to multiple packages, are skipped silently.
 func applyFixes(roots []*action) {
-	visited := make(map[*action]bool)
-	var apply func(*action) error
-	var visitAll func(actions []*action) error
-	visitAll = func(actions []*action) error {
-		for _, act := range actions {
-			if !visited[act] {
-				visited[act] = true
-				visitAll(act.deps)
-				if err := apply(act); err != nil {
-					return err
-				}
+	selected := func(a *analysis.Analyzer) bool {
+		if len(FixAnalyzers) == 0 {
+			return true
+		}
+		for _, name := range FixAnalyzers {
+			if name == a.Name {
+				return true
 			}
 		}
-		return nil
+		return false
 	}
 
-	// TODO(matloob): Is this tree business too complicated? (After all this is Go!)
-	// Just create a set (map) of edits, sort by pos and call it a day?
 	type offsetedit struct {
 		start, end int
 		newText    []byte
-	} // TextEdit using byteOffsets instead of pos
-	type node struct {
-		edit        offsetedit
-		left, right *node
+	} // TextEdit using byte offsets instead of pos
+
+	// The applied edits of each file, sorted by offsets.
+	editsForFile := make(map[*token.File][]offsetedit)
+
+	// conflicts reports whether an edit of a file overlaps an applied
+	// edit, and whether it is identical to an applied edit.
+	conflicts := func(file *token.File, edit offsetedit) (conflict, duplicate bool) {
+		for _, e := range editsForFile[file] {
+			switch {
+			case e.start == edit.start && e.end == edit.end && bytes.Equal(e.newText, edit.newText):
+				duplicate = true
+			case e.start < edit.end && edit.start < e.end,
+				// Two insertions at the same offset must not be reordered.
+				e.start == e.end && edit.start == edit.end && e.start == edit.start:
+				return true, false
+			}
+		}
+		return false, duplicate
 	}
 
-	var insert func(tree **node, edit offsetedit) error
-	insert = func(treeptr **node, edit offsetedit) error {
-		if *treeptr == nil {
-			*treeptr = &node{edit, nil, nil}
-			return nil
+	// addFix applies the edits of a fix, or returns the reason why the
+	// fix cannot be applied.
+	addFix := func(act *action, sf analysis.SuggestedFix) error {
+		type fileedit struct {
+			file *token.File
+			offsetedit
 		}
-		tree := *treeptr
-		if edit.end <= tree.edit.start {
-			return insert(&tree.left, edit)
-		} else if edit.start >= tree.edit.end {
-			return insert(&tree.right, edit)
+		var edits []fileedit
+		for _, edit := range sf.TextEdits {
+			end := edit.End
+			if !end.IsValid() {
+				end = edit.Pos // pure insertion
+			}
+			// Validate the edit.
+			if edit.Pos > end {
+				return fmt.Errorf("malformed edit: pos (%v) > end (%v)", edit.Pos, end)
+			}
+			file, endfile := act.pkg.Fset.File(edit.Pos), act.pkg.Fset.File(end)
+			if file == nil || endfile == nil || file != endfile {
+				return fmt.Errorf("malformed edit spanning files")
+			}
+			edits = append(edits, fileedit{file, offsetedit{file.Offset(edit.Pos), file.Offset(end), edit.NewText}})
 		}
-
-		// Overlapping text edit.
-		return fmt.Errorf("analyses applying overlapping text edits affecting pos range (%v, %v) and (%v, %v)",
-			edit.start, edit.end, tree.edit.start, tree.edit.end)
-
+		// Edits of the same fix must not overlap either.
+		for i, x := range edits {
+			for _, y := range edits[:i] {
+				if x.file == y.file && x.start < y.end && y.start < x.end {
+					return fmt.Errorf("malformed fix with overlapping edits")
+				}
+			}
+		}
+		var added []fileedit
+		for _, edit := range edits {
+			conflict, duplicate := conflicts(edit.file, edit.offsetedit)
+			if conflict {
+				return fmt.Errorf("conflicts with another fix")
+			}
+			if !duplicate {
+				added = append(added, edit)
+			}
+		}
+		for _, edit := range added {
+			editsForFile[edit.file] = append(editsForFile[edit.file], edit.offsetedit)
+		}
+		return nil
 	}
 
-	editsForFile := make(map[*token.File]*node)
-
-	apply = func(act *action) error {
+	for _, act := range roots {
+		if !selected(act.a) {
+			continue
+		}
 		for _, diag := range act.diagnostics {
 			for _, sf := range diag.SuggestedFixes {
-				for _, edit := range sf.TextEdits {
-					// Validate the edit.
-					if edit.Pos > edit.End {
-						return fmt.Errorf(
-							"diagnostic for analysis %v contains Suggested Fix with malformed edit: pos (%v) > end (%v)",
-							act.a.Name, edit.Pos, edit.End)
-					}
-					file, endfile := act.pkg.Fset.File(edit.Pos), act.pkg.Fset.File(edit.End)
-					if file == nil || endfile == nil || file != endfile {
-						return (fmt.Errorf(
-							"diagnostic for analysis %v contains Suggested Fix with malformed spanning files %v and %v",
-							act.a.Name, file.Name(), endfile.Name()))
-					}
-					start, end := file.Offset(edit.Pos), file.Offset(edit.End)
-
-					// TODO(matloob): Validate that edits do not affect other packages.
-					root := editsForFile[file]
-					if err := insert(&root, offsetedit{start, end, edit.NewText}); err != nil {
-						return err
-					}
-					editsForFile[file] = root // In case the root changed
+				if err := addFix(act, sf); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %s: not applying fix %q: %v\n",
+						act.pkg.Fset.Position(diag.Pos), act.a.Name, sf.Message, err)
 				}
 			}
 		}
-		return nil
 	}
 
-	visitAll(roots)
+	var files []*token.File
+	for f := range editsForFile {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
 
 	fset := token.NewFileSet() // Shared by parse calls below
 	// Now we've got a set of valid edits for each file. Get the new file contents.
-	for f, tree := range editsForFile {
+	for _, f := range files {
+		edits := editsForFile[f]
+		sort.SliceStable(edits, func(i, j int) bool {
+			if edits[i].start != edits[j].start {
+				return edits[i].start < edits[j].start
+			}
+			return edits[i].end < edits[j].end
+		})
+
 		contents, err := ioutil.ReadFile(f.Name())
 		if err != nil {
 			log.Fatal(err)
 		}
 
 		cur := 0 // current position in the file
-
 		var out bytes.Buffer
-
-		var recurse func(*node)
-		recurse = func(node *node) {
-			if node.left != nil {
-				recurse(node.left)
-			}
-
-			edit := node.edit
-			if edit.start > cur {
-				out.Write(contents[cur:edit.start])
-				out.Write(edit.newText)
-			}
+		for _, edit := range edits {
+			out.Write(contents[cur:edit.start])
+			out.Write(edit.newText)
 			cur = edit.end
-
-			if node.right != nil {
-				recurse(node.right)
-			}
 		}
-		recurse(tree)
 		// Write out the rest of the file.
-		if cur < len(contents) {
-			out.Write(contents[cur:])
-		}
+		out.Write(contents[cur:])
 
 		// Try to format the file.
 		ff, err := parser.ParseFile(fset, f.Name(), out.Bytes(), parser.ParseComments)
@@ -389,8 +515,57 @@ func applyFixes(roots []*action) {
 			}
 		}
 
-		ioutil.WriteFile(f.Name(), out.Bytes(), 0644)
+		if Diff {
+			uri := span.URIFromPath(f.Name())
+			diffs, err := myers.ComputeEdits(uri, string(contents), out.String())
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Print(diff.ToUnified(f.Name()+".orig", f.Name(), string(contents), diffs))
+			continue
+		}
+		if err := ioutil.WriteFile(f.Name(), out.Bytes(), 0644); err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+// applyIgnores removes from the root actions the diagnostics suppressed
+// by //lint:ignore directives, and returns the root actions with, for
+// each package with unused or malformed directives, an action of the
+// pseudo-analyzer reporting them.
+func applyIgnores(roots []*action) []*action {
+	var pkgs []*packages.Package
+	byPkg := make(map[*packages.Package][]*action)
+	for _, act := range roots {
+		if _, ok := byPkg[act.pkg]; !ok {
+			pkgs = append(pkgs, act.pkg)
+		}
+		byPkg[act.pkg] = append(byPkg[act.pkg], act)
+	}
+	for _, pkg := range pkgs {
+		results := make(map[*analysis.Analyzer][]analysis.Diagnostic)
+		for _, act := range byPkg[pkg] {
+			if act.err == nil {
+				results[act.a] = act.diagnostics
+			}
+		}
+		problems := analysisflags.Ignore(pkg.Fset, pkg.Syntax, results)
+		for _, act := range byPkg[pkg] {
+			if act.err == nil {
+				act.diagnostics = results[act.a]
+			}
+		}
+		if len(problems) > 0 {
+			roots = append(roots, &action{
+				a:           analysisflags.IgnoreAnalyzer,
+				pkg:         pkg,
+				isroot:      true,
+				diagnostics: problems,
+			})
+		}
 	}
+	return roots
 }
 
 // printDiagnostics prints the diagnostics for the root packages in either
@@ -431,6 +606,22 @@ func printDiagnostics(roots []*action) (exitcode int) {
 		}
 		visitAll(roots)
 		tree.Print()
+	} else if analysisflags.SARIF {
+		// SARIF output
+		var sarif analysisflags.SARIFLog
+		print = func(act *action) {
+			var diags []analysis.Diagnostic
+			if act.isroot {
+				diags = act.diagnostics
+			} else if act.err == nil {
+				// Only the analyzers that were requested, or that
+				// failed, are described by rules.
+				return
+			}
+			sarif.Add(act.pkg.Fset, act.a, diags, act.err)
+		}
+		visitAll(roots)
+		sarif.Print()
 	} else {
 		// plain text output
 
@@ -542,6 +733,7 @@ type action struct {
 	diagnostics  []analysis.Diagnostic
 	err          error
 	duration     time.Duration
+	cache        *packageCache // or nil
 }
 
 type objectFactKey struct {
@@ -579,6 +771,12 @@ func execAll(actions []*action) {
 func (act *action) exec() { act.once.Do(act.execOnce) }
 
 func (act *action) execOnce() {
+	// Load the outputs from the cache if the package is unchanged,
+	// without analyzing dependencies.
+	if act.loadCached() {
+		return
+	}
+
 	// Analyze dependencies.
 	execAll(act.deps)
 
@@ -0,0 +1,168 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/analysis/analysistest"
+	"github.com/kent0106/gotools/go/analysis/internal/checker"
+	"github.com/kent0106/gotools/internal/testenv"
+)
+
+// renamer returns an analyzer suggesting to rename the identifiers from
+// to to.
+func renamer(name, from, to string) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: name,
+		Doc:  fmt.Sprintf("rename %s to %s", from, to),
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			for _, f := range pass.Files {
+				ast.Inspect(f, func(n ast.Node) bool {
+					if id, ok := n.(*ast.Ident); ok && id.Name == from {
+						msg := fmt.Sprintf("renaming %q to %q", from, to)
+						pass.Report(analysis.Diagnostic{
+							Pos:     id.Pos(),
+							End:     id.End(),
+							Message: msg,
+							SuggestedFixes: []analysis.SuggestedFix{{
+								Message:   msg,
+								TextEdits: []analysis.TextEdit{{Pos: id.Pos(), End: id.End(), NewText: []byte(to)}},
+							}},
+						})
+					}
+					return true
+				})
+			}
+			return nil, nil
+		},
+	}
+}
+
+var registerFlags sync.Once
+
+func TestSelectiveFixes(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	const src = `package fix
+
+func Foo() {
+	var bar string
+	_ = bar
+}
+`
+	analyzers := []*analysis.Analyzer{
+		renamer("baz", "bar", "baz"),
+		renamer("qux", "bar", "qux"), // conflicts with baz
+		renamer("foo", "Foo", "Fooo"),
+	}
+	defer func() {
+		checker.Fix, checker.FixAnalyzers, checker.Diff = false, nil, false
+	}()
+
+	for _, test := range []struct {
+		name     string
+		fix      string // value of the -fix flag
+		diff     bool
+		want     string // contents of the file after the run
+		wantDiff string // unified diff printed by the run
+	}{
+		{
+			name: "all",
+			fix:  "true",
+			want: `package fix
+
+func Fooo() {
+	var baz string
+	_ = baz
+}
+`,
+		},
+		{
+			name: "filtered",
+			fix:  "qux",
+			want: `package fix
+
+func Foo() {
+	var qux string
+	_ = qux
+}
+`,
+		},
+		{
+			name: "diff",
+			fix:  "baz,foo",
+			diff: true,
+			want: src,
+			wantDiff: `
+@@ -1,6 +1,6 @@
+ package fix
+` + " " + `
+-func Foo() {
+-	var bar string
+-	_ = bar
++func Fooo() {
++	var baz string
++	_ = baz
+ }
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			testdata, cleanup, err := analysistest.WriteFiles(map[string]string{"fix/test.go": src})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+			path := filepath.Join(testdata, "src/fix/test.go")
+
+			registerFlags.Do(checker.RegisterFlags)
+			if err := flag.Set("fix", test.fix); err != nil {
+				t.Fatal(err)
+			}
+			checker.Diff = test.diff
+
+			// Capture the diffs printed to stdout.
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stdout := os.Stdout
+			os.Stdout = w
+			checker.Run([]string{"file=" + path}, analyzers)
+			os.Stdout = stdout
+			w.Close()
+			out, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("contents of rewritten file\ngot: %s\nwant: %s", got, test.want)
+			}
+			// Skip the header of the diff, which names temporary files.
+			gotDiff := string(out)
+			if i := strings.Index(gotDiff, "\n@@"); i >= 0 {
+				gotDiff = gotDiff[i:]
+			}
+			if test.wantDiff != "" && gotDiff != test.wantDiff {
+				t.Errorf("diff\ngot: %s\nwant: %s", gotDiff, test.wantDiff)
+			}
+		})
+	}
+}
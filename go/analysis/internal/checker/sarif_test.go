@@ -0,0 +1,85 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/analysis/analysistest"
+	"github.com/kent0106/gotools/go/analysis/internal/analysisflags"
+	"github.com/kent0106/gotools/go/analysis/internal/checker"
+	"github.com/kent0106/gotools/internal/testenv"
+)
+
+func TestSARIF(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	testdata, cleanup, err := analysistest.WriteFiles(map[string]string{"sarif/test.go": `package sarif
+
+func Foo() {
+	var bar string
+	_ = bar
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	path := filepath.Join(testdata, "src/sarif/test.go")
+
+	from, to = "bar", "baz"
+	checker.Fix, checker.FixAnalyzers = false, nil
+	analysisflags.SARIF = true
+	defer func() { analysisflags.SARIF = false }()
+
+	// Capture the log printed to stdout.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	checker.Run([]string{"file=" + path}, []*analysis.Analyzer{analyzer})
+	os.Stdout = stdout
+	w.Close()
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out, &log); err != nil {
+		t.Fatalf("invalid SARIF log: %v\n%s", err, out)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(log.Runs))
+	}
+	// The rule of the inspect analyzer required by rename is omitted.
+	run := log.Runs[0]
+	if rules := run.Tool.Driver.Rules; len(rules) != 1 || rules[0].ID != "rename" {
+		t.Errorf("got rules %v, want only rename", rules)
+	}
+	if len(run.Results) != 2 {
+		t.Errorf("got %d results, want 2", len(run.Results))
+	}
+}
@@ -143,6 +143,13 @@ func Run(configFile string, analyzers []*analysis.Analyzer) {
 				tree.Add(fset, cfg.ID, res.a.Name, res.diagnostics, res.err)
 			}
 			tree.Print()
+		} else if analysisflags.SARIF {
+			// SARIF output
+			var sarif analysisflags.SARIFLog
+			for _, res := range results {
+				sarif.Add(fset, res.a, res.diagnostics, res.err)
+			}
+			sarif.Print()
 		} else {
 			// plain text
 			exit := 0
@@ -379,6 +386,25 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		results[i].diagnostics = act.diagnostics
 	}
 
+	if !cfg.VetxOnly {
+		// Apply the //lint:ignore directives.
+		diags := make(map[*analysis.Analyzer][]analysis.Diagnostic)
+		for _, res := range results {
+			if res.err == nil {
+				diags[res.a] = res.diagnostics
+			}
+		}
+		problems := analysisflags.Ignore(fset, files, diags)
+		for i := range results {
+			if results[i].err == nil {
+				results[i].diagnostics = diags[results[i].a]
+			}
+		}
+		if len(problems) > 0 {
+			results = append(results, result{a: analysisflags.IgnoreAnalyzer, diagnostics: problems})
+		}
+	}
+
 	data := facts.Encode()
 	if err := ioutil.WriteFile(cfg.VetxOutput, data, 0666); err != nil {
 		return nil, fmt.Errorf("failed to write analysis facts: %v", err)
@@ -114,8 +114,8 @@ func loadAPI() (*source.APIJSON, error) {
 		catName := strings.TrimSuffix(category.Type().Name(), "Options")
 		api.Options[catName] = opts
 
-		// Hardcode the expected values for the analyses and code lenses
-		// settings, since their keys are not enums.
+		// Hardcode the expected values for the analyses, code lenses and
+		// inlay hints settings, since their keys are not enums.
 		for _, opt := range opts {
 			switch opt.Name {
 			case "analyses":
@@ -143,6 +143,25 @@ func loadAPI() (*source.APIJSON, error) {
 						Default: def,
 					})
 				}
+			case "hints":
+				reflectField := category.FieldByName(upperFirst(opt.Name))
+				var names []string
+				for name := range source.AllInlayHints {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					def, err := formatDefaultFromEnumBoolMap(reflectField, name)
+					if err != nil {
+						return nil, err
+					}
+					key := fmt.Sprintf("%q", name)
+					opt.EnumKeys.Keys = append(opt.EnumKeys.Keys, source.EnumKey{
+						Name:    key,
+						Doc:     fmt.Sprintf("`%s`: %s", key, source.AllInlayHints[name].Doc),
+						Default: def,
+					})
+				}
 			}
 		}
 	}
@@ -653,7 +672,7 @@ func collectGroups(opts []*source.OptionJSON) []optionsGroup {
 }
 
 func hardcodedEnumKeys(name string) bool {
-	return name == "analyses" || name == "codelenses"
+	return name == "analyses" || name == "codelenses" || name == "hints"
 }
 
 func writeBullet(w io.Writer, title string, level int) {
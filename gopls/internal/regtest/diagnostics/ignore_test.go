@@ -0,0 +1,53 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"testing"
+
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+)
+
+func TestLintIgnore(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- main.go --
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Printf("%d", "a") //lint:ignore printf testing the suppression
+	fmt.Printf("%d", "b")
+	//lint:ignore printf nothing to suppress
+	fmt.Println("c")
+	//lint:ignore printf
+	fmt.Println("d")
+}
+
+// Reported is not suppressed.
+//
+//lint:ignore unknownanalyzer run by another tool
+func Reported() {
+	fmt.Printf("%d", "e")
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+		env.Await(
+			OnceMet(
+				env.DiagnosticAtRegexpWithMessage("main.go", `fmt.Printf\("%d", "b"\)`, "wrong type"),
+				env.DiagnosticAtRegexpWithMessage("main.go", `fmt.Printf\("%d", "e"\)`, "wrong type"),
+				env.NoDiagnosticAtRegexp("main.go", `fmt.Printf\("%d", "a"\)`),
+				env.DiagnosticAtRegexpWithMessage("main.go", "//lint:ignore printf nothing", "never matches a diagnostic of printf"),
+				env.DiagnosticAtRegexpWithMessage("main.go", "//lint:ignore printf\n", "lacks a reason"),
+				env.NoDiagnosticAtRegexp("main.go", "//lint:ignore unknownanalyzer"),
+			),
+		)
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+)
+
+func TestPullDiagnostics(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+func _() {
+	x := 1
+}
+-- b/b.go --
+package b
+
+func B() {}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		report := env.Diagnostic("a/a.go", "")
+		if report.Kind != "full" {
+			t.Fatalf("got %q report, want full", report.Kind)
+		}
+		if len(report.Items) != 1 || !strings.Contains(report.Items[0].Message, "not used") {
+			t.Fatalf("got diagnostics %v, want one unused variable error", report.Items)
+		}
+		if got := env.Diagnostic("a/a.go", report.ResultID); got.Kind != "unchanged" || got.ResultID != report.ResultID {
+			t.Errorf("got %q report with result ID %q, want unchanged report with ID %q", got.Kind, got.ResultID, report.ResultID)
+		}
+
+		reports := env.WorkspaceDiagnostic(nil)
+		if len(reports) != 1 || !strings.HasSuffix(string(reports[0].URI), "a/a.go") {
+			t.Fatalf("got workspace reports %v, want a report for a/a.go", reports)
+		}
+		if reports[0].ResultID != report.ResultID {
+			t.Errorf("got workspace result ID %q, want %q", reports[0].ResultID, report.ResultID)
+		}
+
+		// Fixing the error clears the diagnostics of a file for which the
+		// client holds a result.
+		env.OpenFile("a/a.go")
+		env.RegexpReplace("a/a.go", "x := 1", "_ = 1")
+		reports = env.WorkspaceDiagnostic(map[string]string{"a/a.go": report.ResultID})
+		if len(reports) != 1 || reports[0].Kind != "full" || len(reports[0].Items) != 0 {
+			t.Fatalf("got workspace reports %v, want an empty full report for a/a.go", reports)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+	"github.com/kent0106/gotools/internal/lsp/tests"
+)
+
+func TestAddTest(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+import "context"
+
+type Cache struct{}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func Sum(name string, xs ...int) int {
+	return 0
+}
+
+func reset() {}
+-- b/b.go --
+package b
+
+func Div(a, b int) (int, error) {
+	return a / b, nil
+}
+-- b/b_test.go --
+package b_test
+
+import "testing"
+
+func TestOther(t *testing.T) {}
+`
+	const wantA = `package a
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCache_Get(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       *Cache
+		ctx     context.Context
+		key     string
+		want    []byte
+		want1   bool
+		wantErr bool
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, err := tt.c.Get(tt.ctx, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Cache.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cache.Get() = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("Cache.Get() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name string
+		arg0 string
+		xs   []int
+		want int
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sum(tt.arg0, tt.xs...); got != tt.want {
+				t.Errorf("Sum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_reset(t *testing.T) {
+	tests := []struct {
+		name string
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset()
+		})
+	}
+}
+`
+	const wantB = `package b_test
+
+import (
+	"testing"
+
+	"mod.com/b"
+)
+
+func TestOther(t *testing.T) {}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       int
+		b       int
+		want    int
+		wantErr bool
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.Div(tt.a, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Div() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Div() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		addTest := func(path, re string) {
+			t.Helper()
+			env.OpenFile(path)
+			pos := env.RegexpSearch(path, re).ToProtocolPosition()
+			actions, err := env.Editor.CodeAction(env.Ctx, path, &protocol.Range{Start: pos, End: pos}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, a := range actions {
+				if a.Kind == protocol.GoAddTest {
+					env.ApplyCodeAction(a)
+					return
+				}
+			}
+			t.Fatalf("no add test code action at %q in %v", re, actions)
+		}
+		addTest("a/a.go", "Get")
+		// The missing test file is created on disk before it is edited.
+		env.ReadWorkspaceFile("a/a_test.go")
+		addTest("a/a.go", "func Sum")
+		addTest("a/a.go", "reset")
+		if got := env.Editor.BufferText("a/a_test.go"); got != wantA {
+			t.Errorf("a/a_test.go: unexpected content:\n%s", tests.Diff(t, wantA, got))
+		}
+		addTest("b/b.go", "Div")
+		if got := env.Editor.BufferText("b/b_test.go"); got != wantB {
+			t.Errorf("b/b_test.go: unexpected content:\n%s", tests.Diff(t, wantB, got))
+		}
+	})
+}
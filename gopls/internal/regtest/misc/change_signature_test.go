@@ -0,0 +1,173 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/command"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+	"github.com/kent0106/gotools/internal/lsp/tests"
+)
+
+func TestChangeSignature(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+type Shape interface {
+	Scale(x, y float64) Shape
+}
+
+type Rect struct{ W, H float64 }
+
+func (r Rect) Scale(x, y float64) Shape {
+	return Rect{r.W * x, r.H * y}
+}
+
+func Sum(a, b int, rest ...int) int {
+	for _, r := range rest {
+		a += r
+	}
+	return a + b
+}
+-- b/b.go --
+package b
+
+import "mod.com/a"
+
+func _(s a.Shape) {
+	_ = s.Scale(1, 2)
+	_ = a.Rect{}.Scale(3, 4)
+	_ = a.Sum(1, a.Sum(2, 3), 4, 5)
+}
+`
+	const wantA = `package a
+
+type Shape interface {
+	Scale(y, x float64) Shape
+}
+
+type Rect struct{ W, H float64 }
+
+func (r Rect) Scale(y, x float64) Shape {
+	return Rect{r.W * x, r.H * y}
+}
+
+func Sum(a, b, scale int, rest ...int) int {
+	for _, r := range rest {
+		a += r
+	}
+	return a + b
+}
+`
+	const wantB = `package b
+
+import "mod.com/a"
+
+func _(s a.Shape) {
+	_ = s.Scale(2, 1)
+	_ = a.Rect{}.Scale(4, 3)
+	_ = a.Sum(1, a.Sum(2, 3, 1), 1, 4, 5)
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		changeSignature := func(re string, params []command.ChangeSignatureParam) error {
+			pos := env.RegexpSearch("a/a.go", re)
+			cmd, err := command.NewChangeSignatureCommand("Change signature", command.ChangeSignatureArgs{
+				URI:      env.Sandbox.Workdir.URI("a/a.go"),
+				Position: pos.ToProtocolPosition(),
+				Params:   params,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = env.Editor.ExecuteCommand(env.Ctx, &protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			})
+			return err
+		}
+
+		// Swapping the parameters of the concrete method updates the interface.
+		if err := changeSignature(`\) (Scale)`, []command.ChangeSignatureParam{{OldIndex: 1}, {OldIndex: 0}}); err != nil {
+			t.Fatal(err)
+		}
+		// Adding a parameter updates the nested calls.
+		scale := command.ChangeSignatureParam{OldIndex: -1, Name: "scale", Type: "int", Value: "1"}
+		if err := changeSignature(`func (Sum)`, []command.ChangeSignatureParam{{OldIndex: 0}, {OldIndex: 1}, scale, {OldIndex: 2}}); err != nil {
+			t.Fatal(err)
+		}
+		// Removing a used parameter fails.
+		if err := changeSignature(`func (Sum)`, []command.ChangeSignatureParam{{OldIndex: 1}, {OldIndex: 2}, {OldIndex: 3}}); err == nil {
+			t.Error("removing the used parameter a succeeded")
+		}
+		if got := env.Editor.BufferText("a/a.go"); got != wantA {
+			t.Errorf("unexpected a/a.go:\n%s", tests.Diff(t, wantA, got))
+		}
+		if got := env.Editor.BufferText("b/b.go"); got != wantB {
+			t.Errorf("unexpected b/b.go:\n%s", tests.Diff(t, wantB, got))
+		}
+	})
+}
+
+func TestRemoveUnusedParameter(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+func Greet(name, greeting string, loud bool) string {
+	greeting += ", "
+	return greeting + name
+}
+-- b/b.go --
+package b
+
+import "mod.com/a"
+
+var _ = a.Greet("gopher", "hello", len("x") > 0)
+`
+	const wantA = `package a
+
+func Greet(name, greeting string) string {
+	greeting += ", "
+	return greeting + name
+}
+`
+	const wantB = `package b
+
+import "mod.com/a"
+
+var _ = a.Greet("gopher", "hello")
+`
+	WithOptions(EditorConfig{AllExperiments: true}).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.OpenFile("b/b.go")
+		var d protocol.PublishDiagnosticsParams
+		env.Await(
+			OnceMet(
+				env.DiagnosticAtRegexpWithMessage("a/a.go", "loud", "unused parameter"),
+				ReadDiagnostics("a/a.go", &d),
+			),
+		)
+		env.ApplyQuickFixes("a/a.go", d.Diagnostics)
+		if got := env.Editor.BufferText("a/a.go"); got != wantA {
+			t.Errorf("unexpected a/a.go:\n%s", tests.Diff(t, wantA, got))
+		}
+		if got := env.Editor.BufferText("b/b.go"); got != wantB {
+			t.Errorf("unexpected b/b.go:\n%s", tests.Diff(t, wantB, got))
+		}
+	})
+}
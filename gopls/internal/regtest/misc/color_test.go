@@ -0,0 +1,64 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+)
+
+func TestDocumentColor(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a.go --
+package a
+
+import "image/color"
+
+var (
+	Red    = color.RGBA{R: 0xff, A: 0xff}
+	Half   = color.NRGBA{0, 0x80, 0, 0x80}
+	Blue   = "#0000ff"
+	Other  = color.RGBA{R: uint8(len(Blue))}
+	Elided = []color.RGBA{{255, 0, 0, 255}}
+)
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a.go")
+		colors := env.DocumentColor("a.go")
+		want := []protocol.Color{
+			{Red: 1, Alpha: 1},
+			{Green: 128.0 / 255, Alpha: 128.0 / 255},
+			{Blue: 1, Alpha: 1},
+			{Red: 1, Alpha: 1},
+		}
+		if len(colors) != len(want) {
+			t.Fatalf("got %d colors, want %d: %v", len(colors), len(want), colors)
+		}
+		for i, c := range colors {
+			if c.Color != want[i] {
+				t.Errorf("color %d: got %v, want %v", i, c.Color, want[i])
+			}
+		}
+
+		green := protocol.Color{Green: 1, Alpha: 0.5}
+		for i, wantText := range []string{
+			"color.RGBA{R: 0x00, G: 0x80, B: 0x00, A: 0x80}",
+			"color.NRGBA{R: 0x00, G: 0xff, B: 0x00, A: 0x80}",
+			`"#00ff0080"`,
+			"{R: 0x00, G: 0x80, B: 0x00, A: 0x80}",
+		} {
+			presentations := env.ColorPresentation("a.go", colors[i].Range, green)
+			if len(presentations) != 1 || presentations[0].TextEdit.NewText != wantText {
+				t.Errorf("got presentations %v, want %q", presentations, wantText)
+			}
+		}
+	})
+}
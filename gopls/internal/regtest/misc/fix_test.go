@@ -61,6 +61,127 @@ func Foo() {
 	})
 }
 
+// When the client supports resolving code actions, fill struct code actions
+// are returned without edits, which are computed when the action is resolved.
+func TestResolveFillStruct(t *testing.T) {
+	const basic = `
+-- go.mod --
+module mod.com
+
+go 1.14
+-- main.go --
+package main
+
+type Info struct {
+	Words []string
+}
+
+func Foo() {
+	_ = Info{}
+}
+`
+	WithOptions(
+		EditorConfig{CodeActionResolveSupport: true},
+	).Run(t, basic, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+		pos := env.RegexpSearch("main.go", "Info{}").ToProtocolPosition()
+		actions, err := env.Editor.CodeAction(env.Ctx, "main.go", &protocol.Range{Start: pos, End: pos}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fill *protocol.CodeAction
+		for i, a := range actions {
+			if a.Kind == protocol.RefactorRewrite {
+				fill = &actions[i]
+			}
+		}
+		if fill == nil {
+			t.Fatalf("no fill struct code action in %v", actions)
+		}
+		if fill.Command != nil || len(fill.Edit.DocumentChanges) > 0 || fill.Data == nil {
+			t.Fatalf("got code action %+v, want an action to resolve", fill)
+		}
+		resolved, err := env.Editor.Server.ResolveCodeAction(env.Ctx, fill)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resolved.Edit.DocumentChanges) != 1 {
+			t.Fatalf("got %d document changes for the resolved action, want 1", len(resolved.Edit.DocumentChanges))
+		}
+	})
+}
+
+// When the client supports resolving code actions, the organization of
+// imports and the suggested fixes of analyzers are also returned without
+// edits.
+func TestResolveImportsAndFixes(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- main.go --
+package main
+
+import "fmt"
+
+type T struct{ A int }
+
+func main() {
+	_ = strings.ToUpper("x")
+	_ = []T{T{A: 1}}
+}
+`
+	const want = `package main
+
+import "strings"
+
+type T struct{ A int }
+
+func main() {
+	_ = strings.ToUpper("x")
+	_ = []T{{A: 1}}
+}
+`
+	WithOptions(
+		EditorConfig{CodeActionResolveSupport: true},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+		// resolvable returns the code action of the given kind, which must
+		// be returned without edits.
+		resolvable := func(actions []protocol.CodeAction, kind protocol.CodeActionKind) protocol.CodeAction {
+			t.Helper()
+			for _, a := range actions {
+				if a.Kind != kind {
+					continue
+				}
+				if len(a.Edit.DocumentChanges) > 0 || a.Data == nil {
+					t.Fatalf("got code action %q with edits, want an action to resolve", a.Title)
+				}
+				return a
+			}
+			t.Fatalf("no %s code action in %v", kind, actions)
+			return protocol.CodeAction{}
+		}
+
+		actions, err := env.Editor.CodeAction(env.Ctx, "main.go", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		env.ApplyCodeAction(resolvable(actions, protocol.SourceOrganizeImports))
+
+		var d protocol.PublishDiagnosticsParams
+		env.Await(OnceMet(
+			env.DiagnosticAtRegexp("main.go", `T{A`),
+			ReadDiagnostics("main.go", &d),
+		))
+		env.ApplyCodeAction(resolvable(env.CodeAction("main.go", d.Diagnostics), protocol.QuickFix))
+		if got := env.Editor.BufferText("main.go"); got != want {
+			t.Errorf("unexpected content:\n%s", tests.Diff(t, want, got))
+		}
+	})
+}
+
 func TestFillReturns(t *testing.T) {
 	const files = `
 -- go.mod --
@@ -0,0 +1,148 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+	"github.com/kent0106/gotools/internal/lsp/source"
+	"github.com/kent0106/gotools/internal/lsp/tests"
+)
+
+const inlayHintFiles = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+const (
+	A = iota
+	B
+	C = "c"
+	D = A + 10
+	E = "ééééééééééééééééééééééééééééé"
+	F = C + E
+)
+
+type Point struct{ X, Y int }
+
+func add(x, y int, rest ...int) int { return x + y }
+
+func _() {
+	x := 1
+	y := add(x, 2, 3, 4)
+	p := Point{1, y}
+	for i, v := range []string{} {
+		_, _ = i, v
+	}
+	var q = &Point{X: 1}
+	_, _ = p, q
+}
+`
+
+func TestInlayHints(t *testing.T) {
+	const want = `package a
+
+const (
+	A = iota <= 0>
+	B <= 1>
+	C = "c"
+	D = A + 10 <= 10>
+	E = "ééééééééééééééééééééééééééééé"
+	F = C + E <= "céééééééééééééééééééééééé...>
+)
+
+type Point struct{ X, Y int }
+
+func add(x, y int, rest ...int) int { return x + y }
+
+func _() {
+	x <int> := 1
+	y <int> := add(x, <y:> 2, <rest...:> 3, 4)
+	p <Point> := Point{<X:> 1, <Y:> y}
+	for i <int>, v <string> := range []string{} {
+		_, _ = i, v
+	}
+	var q <*Point> = &Point{X: 1}
+	_, _ = p, q
+}
+`
+	all := make(map[string]bool)
+	for name := range source.AllInlayHints {
+		all[name] = true
+	}
+	WithOptions(EditorConfig{InlayHints: all}).Run(t, inlayHintFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		got := renderInlayHints(env.Editor.BufferText("a/a.go"), env.InlayHint("a/a.go"))
+		if got != want {
+			t.Errorf("unexpected inlay hints:\n%s", tests.Diff(t, want, got))
+		}
+	})
+}
+
+func TestInlayHintsDisabled(t *testing.T) {
+	config := EditorConfig{InlayHints: map[string]bool{source.ParameterNames: true}}
+	WithOptions(config).Run(t, inlayHintFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		hints := env.InlayHint("a/a.go")
+		if len(hints) != 2 {
+			t.Errorf("got %d parameter name hints, want 2", len(hints))
+		}
+		for _, h := range hints {
+			if h.Kind != protocol.Parameter {
+				t.Errorf("got hint %q of kind %v, want only parameter names", h.Label[0].Value, h.Kind)
+			}
+		}
+	})
+	Run(t, inlayHintFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		if hints := env.InlayHint("a/a.go"); len(hints) != 0 {
+			t.Errorf("got %d hints by default, want none", len(hints))
+		}
+	})
+}
+
+// renderInlayHints inserts the labels of hints, in angle brackets, into the
+// ASCII text.
+func renderInlayHints(text string, hints []protocol.InlayHint) string {
+	type insertion struct {
+		offset int
+		text   string
+	}
+	lines := strings.SplitAfter(text, "\n")
+	var inserts []insertion
+	for _, h := range hints {
+		offset := 0
+		for _, l := range lines[:h.Position.Line] {
+			offset += len(l)
+		}
+		offset += int(h.Position.Character)
+		var label string
+		for _, part := range h.Label {
+			label += part.Value
+		}
+		label = "<" + label + ">"
+		if h.PaddingLeft {
+			label = " " + label
+		}
+		if h.PaddingRight {
+			label += " "
+		}
+		inserts = append(inserts, insertion{offset, label})
+	}
+	sort.SliceStable(inserts, func(i, j int) bool {
+		return inserts[i].offset > inserts[j].offset
+	})
+	for _, ins := range inserts {
+		text = text[:ins.offset] + ins.text + text[ins.offset:]
+	}
+	return text
+}
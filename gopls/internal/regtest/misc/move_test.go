@@ -0,0 +1,267 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/command"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+	"github.com/kent0106/gotools/internal/lsp/tests"
+)
+
+// moveDeclaration moves the declaration named by the first match of re in
+// path to the file dest.
+func moveDeclaration(env *Env, path, re, dest string) error {
+	env.T.Helper()
+	cmd, err := command.NewMoveDeclarationCommand("Move declaration", command.MoveDeclarationArgs{
+		URI:      env.Sandbox.Workdir.URI(path),
+		Position: env.RegexpSearch(path, re).ToProtocolPosition(),
+		Dest:     env.Sandbox.Workdir.URI(dest),
+	})
+	if err != nil {
+		env.T.Fatal(err)
+	}
+	_, err = env.Editor.ExecuteCommand(env.Ctx, &protocol.ExecuteCommandParams{
+		Command:   cmd.Command,
+		Arguments: cmd.Arguments,
+	})
+	return err
+}
+
+func TestMoveDeclarationToFile(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (p Point) Sum() int {
+	return p.X + p.Y
+}
+
+// Point is a point.
+type Point struct{ X, Y int }
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+-- a/b.go --
+package a
+
+var Origin = Point{}
+`
+	const wantA = `package a
+
+import (
+	"strings"
+)
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+`
+	const wantPoint = `package a
+
+import "fmt"
+
+// Point is a point.
+type Point struct{ X, Y int }
+
+func (p Point) Sum() int {
+	return p.X + p.Y
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		if err := moveDeclaration(env, "a/a.go", "Point struct", "a/point.go"); err != nil {
+			t.Fatal(err)
+		}
+		// The missing destination file is created on disk before it is edited.
+		env.ReadWorkspaceFile("a/point.go")
+		if got := env.Editor.BufferText("a/a.go"); got != wantA {
+			t.Errorf("unexpected a/a.go:\n%s", tests.Diff(t, wantA, got))
+		}
+		if got := env.Editor.BufferText("a/point.go"); got != wantPoint {
+			t.Errorf("unexpected a/point.go:\n%s", tests.Diff(t, wantPoint, got))
+		}
+	})
+}
+
+func TestMoveDeclarationToPackage(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+import "mod.com/b"
+
+const (
+	Max = 10
+	Min = 0 // the minimum
+)
+
+const scale = 2
+
+func Clamp(x int) int {
+	if x > b.Limit {
+		return b.Limit
+	}
+	return x
+}
+
+func Double(x int) int {
+	return x * scale
+}
+
+func Bound() int {
+	return Max
+}
+
+func helper() {}
+
+func Use() int {
+	helper()
+	return Clamp(Max) + Bound()
+}
+
+type shape interface{ area() int }
+
+type Square struct{}
+
+func (Square) area() int { return 0 }
+
+var _ shape = Square{}
+-- b/b.go --
+package b
+
+const Limit = 5
+-- c/c.go --
+package c
+
+import "mod.com/a"
+
+var _ = a.Clamp(a.Max)
+-- d/d.go --
+package d
+
+import "mod.com/a"
+
+var _ = a.Clamp(1)
+`
+	const wantA = `package a
+
+import "mod.com/b"
+
+const (
+	Max = 10
+)
+
+const scale = 2
+
+func Double(x int) int {
+	return x * scale
+}
+
+func Bound() int {
+	return Max
+}
+
+func helper() {}
+
+func Use() int {
+	helper()
+	return b.Clamp(Max) + Bound()
+}
+
+type shape interface{ area() int }
+
+type Square struct{}
+
+func (Square) area() int { return 0 }
+
+var _ shape = Square{}
+`
+	const wantB = `package b
+
+const Limit = 5
+
+func Clamp(x int) int {
+	if x > Limit {
+		return Limit
+	}
+	return x
+}
+
+const Min = 0 // the minimum
+`
+	const wantC = `package c
+
+import (
+	"mod.com/a"
+	"mod.com/b"
+)
+
+var _ = b.Clamp(a.Max)
+`
+	const wantD = `package d
+
+import "mod.com/b"
+
+var _ = b.Clamp(1)
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		if err := moveDeclaration(env, "a/a.go", "func (Clamp)", "b/b.go"); err != nil {
+			t.Fatal(err)
+		}
+		if err := moveDeclaration(env, "a/a.go", "Min", "b/b.go"); err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range []struct {
+			re, want string
+		}{
+			{"func (Double)", "unexported const scale"},
+			{"func (helper)", "unexported func helper is used"},
+			{"func (Bound)", "import cycle"},
+			{"type (Square)", "unexported method area"},
+		} {
+			err := moveDeclaration(env, "a/a.go", test.re, "b/b.go")
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("moving %s: got error %v, want an error containing %q", test.re, err, test.want)
+			}
+		}
+		for path, want := range map[string]string{
+			"a/a.go": wantA,
+			"b/b.go": wantB,
+			"c/c.go": wantC,
+			"d/d.go": wantD,
+		} {
+			if got := env.Editor.BufferText(path); got != want {
+				t.Errorf("unexpected %s:\n%s", path, tests.Diff(t, want, got))
+			}
+		}
+	})
+}
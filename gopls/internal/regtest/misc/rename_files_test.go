@@ -0,0 +1,149 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+)
+
+func TestWillRenameDirectory(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- old/old.go --
+package old
+
+func Hello() string { return "hello" }
+-- old/old_test.go --
+package old_test
+
+import (
+	"testing"
+
+	"mod.com/old"
+)
+
+func TestHello(t *testing.T) {
+	_ = old.Hello()
+}
+-- old/sub/sub.go --
+package sub
+
+import "mod.com/old"
+
+var S = old.Hello()
+-- main.go --
+package main
+
+import (
+	"fmt"
+
+	"mod.com/old"
+	"mod.com/old/sub"
+)
+
+func main() {
+	fmt.Println(old.Hello(), sub.S)
+}
+-- shadow/shadow.go --
+package shadow
+
+import "mod.com/old"
+
+func F(renamed int) string {
+	return old.Hello()
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.Await(env.DoneWithOpen())
+		env.WillRenameFiles("old", "renamed")
+
+		const wantMain = `package main
+
+import (
+	"fmt"
+
+	"mod.com/renamed"
+	"mod.com/renamed/sub"
+)
+
+func main() {
+	fmt.Println(renamed.Hello(), sub.S)
+}
+`
+		if got := env.Editor.BufferText("main.go"); got != wantMain {
+			t.Errorf("main.go after rename:\n%s", got)
+		}
+		const wantOld = `package renamed
+
+func Hello() string { return "hello" }
+`
+		if got := env.Editor.BufferText("old/old.go"); got != wantOld {
+			t.Errorf("old/old.go after rename:\n%s", got)
+		}
+		const wantTest = `package renamed_test
+
+import (
+	"testing"
+
+	"mod.com/renamed"
+)
+
+func TestHello(t *testing.T) {
+	_ = renamed.Hello()
+}
+`
+		if got := env.Editor.BufferText("old/old_test.go"); got != wantTest {
+			t.Errorf("old/old_test.go after rename:\n%s", got)
+		}
+		const wantSub = `package sub
+
+import "mod.com/renamed"
+
+var S = renamed.Hello()
+`
+		if got := env.Editor.BufferText("old/sub/sub.go"); got != wantSub {
+			t.Errorf("old/sub/sub.go after rename:\n%s", got)
+		}
+		// The parameter named "renamed" would capture the qualifier, so the
+		// import keeps its old name.
+		const wantShadow = `package shadow
+
+import old "mod.com/renamed"
+
+func F(renamed int) string {
+	return old.Hello()
+}
+`
+		if got := env.Editor.BufferText("shadow/shadow.go"); got != wantShadow {
+			t.Errorf("shadow/shadow.go after rename:\n%s", got)
+		}
+	})
+}
+
+func TestDidRenameDirectory(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- old/old.go --
+package old
+
+var _ int = ""
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.Await(env.DiagnosticAtRegexp("old/old.go", `""`))
+		env.RenameFiles("old", "renamed")
+		env.Await(
+			EmptyDiagnostics("old/old.go"),
+			env.DiagnosticAtRegexp("renamed/old.go", `""`),
+		)
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+	"github.com/kent0106/gotools/internal/lsp/tests"
+)
+
+func TestRewriteIf(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+func Sign(x int) int {
+	if x < 0 && x != -1 {
+		// negative
+		return -1
+	} else {
+		return 1
+	}
+}
+
+func Name(n int) string {
+	if n == 1 {
+		return "one"
+	} else if n == 2 || n == 3 {
+		// a few
+		return "few"
+	} else {
+		return "many"
+	}
+}
+
+func Nested(a, b bool, s []string) {
+	if a {
+		if b || len(s) > 0 {
+			s = append(s, ` + "`x\ny`" + `)
+		}
+	}
+}
+
+func Check(err error) error {
+	if err != nil {
+		return err
+	} else {
+		x := 1
+		_ = x
+	}
+	return nil
+}
+
+func Loop(xs []int) {
+	for _, x := range xs {
+		if x > 0 {
+			println(x)
+		} else {
+			continue
+		}
+	}
+}
+
+func Either(x, y, z bool) int {
+	if !(x || y) || z {
+		return 1
+	} else {
+		return 2
+	}
+}
+
+func Neither(x, y, z bool) int {
+	if z || !(x || y) {
+		return 1
+	} else {
+		return 2
+	}
+}
+`
+	const want = `package a
+
+func Sign(x int) int {
+	if x >= 0 || x == -1 {
+		return 1
+	} else {
+		// negative
+		return -1
+	}
+}
+
+func Name(n int) string {
+	switch n {
+	case 1:
+		return "one"
+	case 2, 3:
+		// a few
+		return "few"
+	default:
+		return "many"
+	}
+}
+
+func Nested(a, b bool, s []string) {
+	if a && (b || len(s) > 0) {
+		s = append(s, ` + "`x\ny`" + `)
+	}
+}
+
+func Check(err error) error {
+	if err != nil {
+		return err
+	}
+	x := 1
+	_ = x
+	return nil
+}
+
+func Loop(xs []int) {
+	for _, x := range xs {
+		if x <= 0 {
+			continue
+		}
+		println(x)
+	}
+}
+
+func Either(x, y, z bool) int {
+	if (x || y) && !z {
+		return 2
+	} else {
+		return 1
+	}
+}
+
+func Neither(x, y, z bool) int {
+	if !z && (x || y) {
+		return 2
+	} else {
+		return 1
+	}
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		for _, test := range []struct {
+			re, title string
+		}{
+			{"x < 0", "Invert if condition"},
+			{"n == 2", "Convert if/else chain to switch"},
+			{"if (a)", "Join nested ifs into && condition"},
+			{"err != nil", "Replace else with early return"},
+			{"x > 0", "Replace else with early return"},
+			{"if (!)", "Invert if condition"},
+			{"if (z)", "Invert if condition"},
+		} {
+			applyRewrite(env, "a/a.go", test.re, test.title)
+		}
+		if got := env.Editor.BufferText("a/a.go"); got != want {
+			t.Errorf("unexpected content:\n%s", tests.Diff(t, want, got))
+		}
+	})
+}
+
+func TestSplitIf(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+func F(a, b, c bool) {
+	if a && b && c {
+		println(` + "`x\ny`" + `)
+
+		println()
+	}
+}
+`
+	const want = `package a
+
+func F(a, b, c bool) {
+	if a {
+		if b && c {
+			println(` + "`x\ny`" + `)
+
+			println()
+		}
+	}
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		applyRewrite(env, "a/a.go", "a &&", "Split && condition into nested ifs")
+		if got := env.Editor.BufferText("a/a.go"); got != want {
+			t.Errorf("unexpected content:\n%s", tests.Diff(t, want, got))
+		}
+	})
+}
+
+// applyRewrite applies the refactor.rewrite code action with the given
+// title at the first match of re in path.
+func applyRewrite(env *Env, path, re, title string) {
+	env.T.Helper()
+	pos := env.RegexpSearch(path, re).ToProtocolPosition()
+	actions, err := env.Editor.CodeAction(env.Ctx, path, &protocol.Range{Start: pos, End: pos}, nil)
+	if err != nil {
+		env.T.Fatal(err)
+	}
+	for _, a := range actions {
+		if a.Kind == protocol.RefactorRewrite && a.Title == title {
+			env.ApplyCodeAction(a)
+			return
+		}
+	}
+	env.T.Fatalf("no %q code action at %q in %v", title, re, actions)
+}
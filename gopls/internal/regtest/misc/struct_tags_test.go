@@ -0,0 +1,103 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+	"github.com/kent0106/gotools/internal/lsp/tests"
+)
+
+func TestStructTags(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+type User struct {
+	ID        int
+	FirstName string ` + "`db:\"first_name\"  json:\"FirstName\"`" + `
+	HTTPAddr  string ` + "`json:\"-\"`" + `
+	secret    string
+	Embedded
+}
+
+type Embedded struct{}
+`
+	const wantAdd = `package a
+
+type User struct {
+	ID        int    ` + "`json:\"id\"`" + `
+	FirstName string ` + "`db:\"first_name\"  json:\"FirstName\"`" + `
+	HTTPAddr  string ` + "`json:\"-\"`" + `
+	secret    string
+	Embedded
+}
+
+type Embedded struct{}
+`
+	const wantNormalize = `package a
+
+type User struct {
+	ID        int    ` + "`json:\"id\"`" + `
+	FirstName string ` + "`db:\"first_name\" json:\"firstName\"`" + `
+	HTTPAddr  string ` + "`json:\"-\"`" + `
+	secret    string
+	Embedded
+}
+
+type Embedded struct{}
+`
+	const wantRemove = `package a
+
+type User struct {
+	ID        int
+	FirstName string ` + "`db:\"first_name\"`" + `
+	HTTPAddr  string
+	secret    string
+	Embedded
+}
+
+type Embedded struct{}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		apply := func(title string) {
+			t.Helper()
+			pos := env.RegexpSearch("a/a.go", "User").ToProtocolPosition()
+			actions, err := env.Editor.CodeAction(env.Ctx, "a/a.go", &protocol.Range{Start: pos, End: pos}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, a := range actions {
+				if a.Title == title {
+					if a.Kind != protocol.RefactorRewrite {
+						t.Errorf("%q: got kind %q, want %q", title, a.Kind, protocol.RefactorRewrite)
+					}
+					env.ApplyCodeAction(a)
+					return
+				}
+			}
+			t.Fatalf("no %q code action in %v", title, actions)
+		}
+		for _, test := range []struct {
+			title, want string
+		}{
+			{"Add json tags", wantAdd},
+			{"Normalize json tags", wantNormalize},
+			{"Remove json tags", wantRemove},
+		} {
+			apply(test.title)
+			if got := env.Editor.BufferText("a/a.go"); got != test.want {
+				t.Errorf("%s: unexpected content:\n%s", test.title, tests.Diff(t, test.want, got))
+			}
+		}
+	})
+}
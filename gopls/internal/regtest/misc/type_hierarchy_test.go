@@ -0,0 +1,104 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	. "github.com/kent0106/gotools/internal/lsp/regtest"
+)
+
+func TestTypeHierarchy(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.12
+-- a/a.go --
+package a
+
+type Shape interface {
+	Area() float64
+}
+
+type Polygon interface {
+	Shape
+	Sides() int
+}
+
+type Square struct{ S float64 }
+
+func (s Square) Area() float64 { return s.S * s.S }
+func (Square) Sides() int      { return 4 }
+
+type Circle struct{ R float64 }
+
+func (c *Circle) Area() float64 { return 3 * c.R * c.R }
+
+type Empty interface{}
+-- b/b.go --
+package b
+
+import "mod.com/a"
+
+type Triangle struct{ a.Shape }
+
+func (Triangle) Sides() int { return 3 }
+
+type Namer interface {
+	Name() string
+}
+
+var _ a.Polygon = Triangle{}
+`
+	names := func(items []protocol.TypeHierarchyItem) string {
+		var names []string
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		return strings.Join(names, ", ")
+	}
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		prepare := func(re string) protocol.TypeHierarchyItem {
+			t.Helper()
+			items := env.PrepareTypeHierarchy("a/a.go", env.RegexpSearch("a/a.go", re))
+			if len(items) != 1 {
+				t.Fatalf("PrepareTypeHierarchy(%q) returned %d items, want 1", re, len(items))
+			}
+			return items[0]
+		}
+		shape := prepare("type (Shape)")
+		if shape.Name != "Shape" || shape.Kind != protocol.Interface {
+			t.Errorf("PrepareTypeHierarchy: got %s of kind %v, want Shape of kind %v", shape.Name, shape.Kind, protocol.Interface)
+		}
+		for _, test := range []struct {
+			re                   string
+			supertypes, subtypes string
+		}{
+			{"type (Shape)", "", "Polygon, Square, Circle, Triangle"},
+			{"type (Polygon)", "Shape", "Square, Triangle"},
+			{"type (Square)", "Shape, Polygon", ""},
+			{"c (\\*Circle)", "Shape", ""},
+			{"type (Empty)", "", ""},
+		} {
+			item := prepare(test.re)
+			if got := names(env.Supertypes(item)); got != test.supertypes {
+				t.Errorf("Supertypes(%s) = %q, want %q", item.Name, got, test.supertypes)
+			}
+			if got := names(env.Subtypes(item)); got != test.subtypes {
+				t.Errorf("Subtypes(%s) = %q, want %q", item.Name, got, test.subtypes)
+			}
+		}
+		// A reference to a type in another package resolves to its declaration.
+		env.OpenFile("b/b.go")
+		items := env.PrepareTypeHierarchy("b/b.go", env.RegexpSearch("b/b.go", `a\.(Polygon)`))
+		if want := prepare("type (Polygon)"); len(items) != 1 || items[0].URI != want.URI || items[0].SelectionRange != want.SelectionRange {
+			t.Errorf("PrepareTypeHierarchy(a.Polygon) = %v, want %v", items, want)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisinternal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// IgnoreAnalyzerName is the name under which the problems of the
+// //lint:ignore directives are reported.
+const IgnoreAnalyzerName = "lintignore"
+
+const ignorePrefix = "//lint:ignore"
+
+// Ignores holds the //lint:ignore directives of the files of a package.
+//
+// A directive has the form
+//
+//	//lint:ignore analyzer[,analyzer...] reason
+//
+// and suppresses the diagnostics of the named analyzers on the line of
+// the directive, or on the next line if the directive is on a line of
+// its own. A directive in the doc comment of a declaration suppresses
+// them in the whole declaration. The reason is required.
+type Ignores struct {
+	directives []*ignoreDirective
+	problems   []IgnoreProblem
+}
+
+type ignoreDirective struct {
+	comment            *ast.Comment
+	analyzers          []string
+	filename           string
+	startLine, endLine int // lines of the code to which the directive applies
+	used               map[string]bool
+}
+
+// An IgnoreProblem is an unused or malformed //lint:ignore directive.
+type IgnoreProblem struct {
+	Pos, End token.Pos
+	Message  string
+}
+
+// ParseIgnores returns the //lint:ignore directives of the files.
+func ParseIgnores(fset *token.FileSet, files []*ast.File) *Ignores {
+	ig := new(Ignores)
+	for _, f := range files {
+		tok := fset.File(f.Pos())
+		if tok == nil {
+			continue
+		}
+		docs := declDocs(f)
+		var firsts map[int]token.Pos // first position of code on each line
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if c.Text != ignorePrefix && !strings.HasPrefix(c.Text, ignorePrefix+" ") {
+					continue
+				}
+				fields := strings.Fields(strings.TrimPrefix(c.Text, ignorePrefix))
+				switch len(fields) {
+				case 0:
+					ig.problem(c, "malformed //lint:ignore directive: want //lint:ignore analyzer reason")
+					continue
+				case 1:
+					ig.problem(c, fmt.Sprintf("//lint:ignore directive for %s lacks a reason", fields[0]))
+					continue
+				}
+				d := &ignoreDirective{
+					comment:   c,
+					analyzers: strings.Split(fields[0], ","),
+					filename:  tok.Name(),
+					used:      make(map[string]bool),
+				}
+				if decl, ok := docs[group]; ok {
+					d.startLine, d.endLine = tok.Line(decl.Pos()), tok.Line(decl.End())
+				} else {
+					if firsts == nil {
+						firsts = firstPositions(tok, f)
+					}
+					d.startLine = tok.Line(c.Pos())
+					d.endLine = d.startLine
+					if first, ok := firsts[d.startLine]; !ok || first > c.Pos() {
+						d.endLine++ // the directive is on a line of its own
+					}
+				}
+				ig.directives = append(ig.directives, d)
+			}
+		}
+	}
+	return ig
+}
+
+// Ignored reports whether the directives suppress a diagnostic of the
+// named analyzer at posn, a position unadjusted by //line directives,
+// and records the use of the suppressing directives.
+func (ig *Ignores) Ignored(analyzer string, posn token.Position) bool {
+	ignored := false
+	for _, d := range ig.directives {
+		if d.filename != posn.Filename || posn.Line < d.startLine || posn.Line > d.endLine {
+			continue
+		}
+		for _, name := range d.analyzers {
+			if name == analyzer {
+				d.used[name] = true
+				ignored = true
+			}
+		}
+	}
+	return ignored
+}
+
+// Problems returns the malformed directives and the unused ones, in
+// order of position. A directive is unused for an analyzer if no
+// diagnostic it suppresses was passed to Ignored; only the analyzers for
+// which ran returns true are considered, as the others may be run by
+// other tools.
+func (ig *Ignores) Problems(ran func(analyzer string) bool) []IgnoreProblem {
+	problems := append([]IgnoreProblem(nil), ig.problems...)
+	for _, d := range ig.directives {
+		for _, name := range d.analyzers {
+			if ran(name) && !d.used[name] {
+				problems = append(problems, IgnoreProblem{
+					Pos:     d.comment.Pos(),
+					End:     d.comment.End(),
+					Message: fmt.Sprintf("this //lint:ignore directive never matches a diagnostic of %s", name),
+				})
+			}
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Pos < problems[j].Pos
+	})
+	return problems
+}
+
+func (ig *Ignores) problem(c *ast.Comment, msg string) {
+	ig.problems = append(ig.problems, IgnoreProblem{Pos: c.Pos(), End: c.End(), Message: msg})
+}
+
+// declDocs returns the declarations, including the specs of grouped
+// declarations, of the doc comments of a file.
+func declDocs(f *ast.File) map[*ast.CommentGroup]ast.Node {
+	docs := make(map[*ast.CommentGroup]ast.Node)
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				docs[decl.Doc] = decl
+			}
+		case *ast.GenDecl:
+			if decl.Doc != nil {
+				docs[decl.Doc] = decl
+			}
+			for _, spec := range decl.Specs {
+				var doc *ast.CommentGroup
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					doc = spec.Doc
+				case *ast.ValueSpec:
+					doc = spec.Doc
+				}
+				if doc != nil {
+					docs[doc] = spec
+				}
+			}
+		}
+	}
+	return docs
+}
+
+// firstPositions returns the first position of the nodes, other than
+// comments, on each line of a file.
+func firstPositions(tok *token.File, f *ast.File) map[int]token.Pos {
+	firsts := make(map[int]token.Pos)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.File:
+			return true
+		case *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		// The last position of a node accounts for the closing
+		// brackets, which are not nodes.
+		for _, pos := range []token.Pos{n.Pos(), n.End() - 1} {
+			line := tok.Line(pos)
+			if first, ok := firsts[line]; !ok || pos < first {
+				firsts[line] = pos
+			}
+		}
+		return true
+	})
+	return firsts
+}
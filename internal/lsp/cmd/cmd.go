@@ -188,6 +188,7 @@ func (app *Application) featureCommands() []tool.Application {
 		newRemote(app, ""),
 		newRemote(app, "inspect"),
 		&links{app: app},
+		&lsif{app: app},
 		&prepareRename{app: app},
 		&references{app: app},
 		&rename{app: app},
@@ -195,6 +196,7 @@ func (app *Application) featureCommands() []tool.Application {
 		&signature{app: app},
 		&suggestedFix{app: app},
 		&symbols{app: app},
+		&tags{app: app},
 		newWorkspace(app),
 		&workspaceSymbol{app: app},
 	}
@@ -316,6 +318,13 @@ type cmdClient struct {
 
 	filesMu sync.Mutex
 	files   map[span.URI]*cmdFile
+
+	// edits holds the workspace edits that the server asked to apply while
+	// recording, for the commands that rewrite files. Edits are refused
+	// when no command is recording them.
+	editsMu   sync.Mutex
+	recording bool
+	edits     []protocol.WorkspaceEdit
 }
 
 type cmdFile struct {
@@ -373,6 +382,13 @@ func (c *cmdClient) LogMessage(ctx context.Context, p *protocol.LogMessageParams
 	return nil
 }
 
+func (c *cmdClient) LogTrace(ctx context.Context, p *protocol.LogTraceParams) error {
+	if c.app.verbose() {
+		log.Print("Trace:", p.Message)
+	}
+	return nil
+}
+
 func (c *cmdClient) Event(ctx context.Context, t *interface{}) error { return nil }
 
 func (c *cmdClient) RegisterCapability(ctx context.Context, p *protocol.RegistrationParams) error {
@@ -419,7 +435,33 @@ func (c *cmdClient) Configuration(ctx context.Context, p *protocol.ParamConfigur
 }
 
 func (c *cmdClient) ApplyEdit(ctx context.Context, p *protocol.ApplyWorkspaceEditParams) (*protocol.ApplyWorkspaceEditResponse, error) {
-	return &protocol.ApplyWorkspaceEditResponse{Applied: false, FailureReason: "not implemented"}, nil
+	c.editsMu.Lock()
+	defer c.editsMu.Unlock()
+	if !c.recording {
+		return &protocol.ApplyWorkspaceEditResponse{Applied: false, FailureReason: "not implemented"}, nil
+	}
+	c.edits = append(c.edits, p.Edit)
+	return &protocol.ApplyWorkspaceEditResponse{Applied: true}, nil
+}
+
+// recordEdits starts accepting the workspace edits that the server asks to
+// apply, until the next call to takeEdits.
+func (c *cmdClient) recordEdits() {
+	c.editsMu.Lock()
+	defer c.editsMu.Unlock()
+	c.recording = true
+	c.edits = nil
+}
+
+// takeEdits stops accepting workspace edits and returns those recorded since
+// the last call to recordEdits.
+func (c *cmdClient) takeEdits() []protocol.WorkspaceEdit {
+	c.editsMu.Lock()
+	defer c.editsMu.Unlock()
+	edits := c.edits
+	c.recording = false
+	c.edits = nil
+	return edits
 }
 
 func (c *cmdClient) PublishDiagnostics(ctx context.Context, p *protocol.PublishDiagnosticsParams) error {
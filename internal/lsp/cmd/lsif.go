@@ -0,0 +1,332 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kent0106/gotools/internal/lsp/cache"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/lsp/source"
+	"github.com/kent0106/gotools/internal/span"
+	"github.com/kent0106/gotools/internal/tool"
+)
+
+// lsif implements the lsif verb for gopls.
+type lsif struct {
+	Output string `flag:"o" help:"write the dump to the named file instead of stdout"`
+
+	app *Application
+}
+
+func (l *lsif) Name() string      { return "lsif" }
+func (l *lsif) Usage() string     { return "[<directory>]" }
+func (l *lsif) ShortHelp() string { return "dump cross-reference data in LSIF format" }
+func (l *lsif) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+Dump the definitions, references, hovers and monikers of the identifiers in
+the workspace packages of the given directory (by default, the working
+directory) as Language Server Index Format (LSIF) JSON lines. Only the files
+that are part of a package for the current build configuration are dumped.
+
+The monikers use the "go" scheme, and identify an object by the import path
+of its package and its path within the package as computed by
+go/types/objectpath, separated by a colon.
+
+Example:
+
+  $ gopls lsif -o dump.lsif
+
+gopls lsif flags are:
+`)
+	f.PrintDefaults()
+}
+
+func (l *lsif) Run(ctx context.Context, args ...string) error {
+	dir := l.app.wd
+	switch len(args) {
+	case 0:
+	case 1:
+		dir = args[0]
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(l.app.wd, dir)
+		}
+	default:
+		return tool.CommandLineErrorf("lsif expects at most one directory")
+	}
+
+	options := source.DefaultOptions().Clone()
+	if l.app.options != nil {
+		l.app.options(options)
+	}
+	session := cache.New(l.app.options).NewSession(ctx)
+	defer session.Shutdown(ctx)
+	folder := span.URIFromPath(dir)
+	_, snapshot, release, err := session.NewView(ctx, "lsif", folder, "", options)
+	if err != nil {
+		return err
+	}
+	defer release()
+	pkgs, err := snapshot.ActivePackages(ctx)
+	if err != nil {
+		return err
+	}
+	// Dump each file with the first package containing it, preferring a
+	// package over its test variants.
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].ID() < pkgs[j].ID()
+	})
+
+	if l.Output == "" {
+		return writeLSIF(ctx, os.Stdout, snapshot, folder, pkgs)
+	}
+	f, err := os.Create(l.Output)
+	if err != nil {
+		return err
+	}
+	if err := writeLSIF(ctx, f, snapshot, folder, pkgs); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeLSIF writes the LSIF dump of the packages of the folder to w.
+func writeLSIF(ctx context.Context, w io.Writer, snapshot source.Snapshot, folder span.URI, pkgs []source.Package) error {
+	bw := bufio.NewWriter(w)
+	d := &lsifDumper{
+		snapshot: snapshot,
+		enc:      json.NewEncoder(bw),
+		results:  make(map[token.Position]*lsifResult),
+	}
+	if err := d.dump(ctx, folder, pkgs); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// lsifDumper writes the vertices and edges of an LSIF dump.
+type lsifDumper struct {
+	snapshot source.Snapshot
+	enc      *json.Encoder
+	id       int
+
+	// results holds the result set of each object, keyed by the position of
+	// its declaration, so that the variants of a package share result sets.
+	results map[token.Position]*lsifResult
+	order   []*lsifResult
+}
+
+// lsifResult collects the ranges that refer to a single object.
+type lsifResult struct {
+	resultSet int
+	obj       types.Object
+
+	// defDoc and defRange are the document and range vertices of the
+	// declaration of obj, or 0 if the declaration is not part of the dump.
+	defDoc, defRange int
+
+	// refs maps document vertices to the range vertices referring to obj.
+	refs map[int][]int
+
+	// uri and pos locate the identifier used to compute the hover of obj:
+	// its declaration if it is part of the dump, or else its first
+	// reference.
+	uri span.URI
+	pos protocol.Position
+}
+
+func (d *lsifDumper) dump(ctx context.Context, folder span.URI, pkgs []source.Package) error {
+	d.emitVertex("metaData", map[string]interface{}{
+		"version":          "0.4.3",
+		"projectRoot":      protocol.URIFromSpanURI(folder),
+		"positionEncoding": "utf-16",
+		"toolInfo":         map[string]interface{}{"name": "gopls"},
+	})
+	project := d.emitVertex("project", map[string]interface{}{"kind": "go"})
+	var docs []int
+	seen := make(map[span.URI]bool)
+	for _, pkg := range pkgs {
+		for _, pgf := range pkg.CompiledGoFiles() {
+			// Skip files generated by cgo and files of other modules in
+			// the workspace.
+			if seen[pgf.URI] || !source.InDir(folder.Filename(), pgf.URI.Filename()) {
+				continue
+			}
+			seen[pgf.URI] = true
+			doc, err := d.dumpDocument(pkg, pgf)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+		}
+	}
+	for _, res := range d.order {
+		if err := d.dumpResult(ctx, res); err != nil {
+			return err
+		}
+	}
+	if len(docs) > 0 {
+		d.emitEdge("contains", project, docs, nil)
+	}
+	return nil
+}
+
+// dumpDocument emits the document vertex of pgf, along with a range vertex
+// for each of its identifiers that refers to an object.
+func (d *lsifDumper) dumpDocument(pkg source.Package, pgf *source.ParsedGoFile) (int, error) {
+	doc := d.emitVertex("document", map[string]interface{}{
+		"uri":        protocol.URIFromSpanURI(pgf.URI),
+		"languageId": "go",
+	})
+	fset := d.snapshot.FileSet()
+	info := pkg.GetTypesInfo()
+	var ranges []int
+	var err error
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || err != nil {
+			return err == nil
+		}
+		if id.Name == "_" {
+			return false
+		}
+		obj, isDef := info.Defs[id], true
+		if obj == nil {
+			obj, isDef = info.Uses[id], false
+		}
+		if obj == nil || obj.Pkg() == nil || !obj.Pos().IsValid() {
+			return false // e.g. a package clause, a builtin, or an unresolved identifier
+		}
+		var rng protocol.Range
+		rng, err = source.NewMappedRange(fset, pgf.Mapper, id.Pos(), id.End()).Range()
+		if err != nil {
+			return false
+		}
+		key := fset.Position(obj.Pos())
+		res, ok := d.results[key]
+		if !ok {
+			res = &lsifResult{
+				resultSet: d.emitVertex("resultSet", nil),
+				obj:       obj,
+				refs:      make(map[int][]int),
+				uri:       pgf.URI,
+				pos:       rng.Start,
+			}
+			d.results[key] = res
+			d.order = append(d.order, res)
+		}
+		r := d.emitVertex("range", map[string]interface{}{"start": rng.Start, "end": rng.End})
+		d.emitEdge("next", r, nil, map[string]interface{}{"inV": res.resultSet})
+		ranges = append(ranges, r)
+		if isDef && id.Pos() == obj.Pos() {
+			res.defDoc, res.defRange = doc, r
+			res.uri, res.pos = pgf.URI, rng.Start
+			return false
+		}
+		res.refs[doc] = append(res.refs[doc], r)
+		return false
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(ranges) > 0 {
+		d.emitEdge("contains", doc, ranges, nil)
+	}
+	return doc, nil
+}
+
+// dumpResult emits the definition, reference, hover, and moniker results of
+// res.
+func (d *lsifDumper) dumpResult(ctx context.Context, res *lsifResult) error {
+	defResult := d.emitVertex("definitionResult", nil)
+	d.emitEdge("textDocument/definition", res.resultSet, nil, map[string]interface{}{"inV": defResult})
+	refResult := d.emitVertex("referenceResult", nil)
+	d.emitEdge("textDocument/references", res.resultSet, nil, map[string]interface{}{"inV": refResult})
+	if res.defRange != 0 {
+		d.emitEdge("item", defResult, []int{res.defRange}, map[string]interface{}{"document": res.defDoc})
+		d.emitEdge("item", refResult, []int{res.defRange}, map[string]interface{}{"document": res.defDoc, "property": "definitions"})
+	}
+	var docs []int
+	for doc := range res.refs {
+		docs = append(docs, doc)
+	}
+	sort.Ints(docs)
+	for _, doc := range docs {
+		d.emitEdge("item", refResult, res.refs[doc], map[string]interface{}{"document": doc, "property": "references"})
+	}
+
+	fh, err := d.snapshot.GetFile(ctx, res.uri)
+	if err != nil {
+		return err
+	}
+	// An object whose hover cannot be computed is dumped without one,
+	// rather than failing the whole dump.
+	hover, err := source.Hover(ctx, d.snapshot, fh, res.pos)
+	if err != nil {
+		log.Printf("hover of %s at %v:%v: %v", res.obj.Name(), res.uri.Filename(), res.pos.Line+1, err)
+	} else if hover != nil {
+		hoverResult := d.emitVertex("hoverResult", map[string]interface{}{
+			"result": map[string]interface{}{"contents": hover.Contents},
+		})
+		d.emitEdge("textDocument/hover", res.resultSet, nil, map[string]interface{}{"inV": hoverResult})
+	}
+	if m, ok := source.ObjectMoniker(res.obj, res.defRange != 0); ok {
+		moniker := d.emitVertex("moniker", map[string]interface{}{
+			"scheme":     m.Scheme,
+			"identifier": m.Identifier,
+			"unique":     m.Unique,
+			"kind":       m.Kind,
+		})
+		d.emitEdge("moniker", res.resultSet, nil, map[string]interface{}{"inV": moniker})
+	}
+	return nil
+}
+
+// emitVertex writes a vertex with the given label and fields, returning its
+// ID.
+func (d *lsifDumper) emitVertex(label string, fields map[string]interface{}) int {
+	return d.emit("vertex", label, fields)
+}
+
+// emitEdge writes an edge with the given label from outV to inVs. Edges with
+// a single incoming vertex set the "inV" field instead.
+func (d *lsifDumper) emitEdge(label string, outV int, inVs []int, fields map[string]interface{}) int {
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	fields["outV"] = outV
+	if inVs != nil {
+		fields["inVs"] = inVs
+	}
+	return d.emit("edge", label, fields)
+}
+
+func (d *lsifDumper) emit(typ, label string, fields map[string]interface{}) int {
+	d.id++
+	elem := map[string]interface{}{
+		"id":    d.id,
+		"type":  typ,
+		"label": label,
+	}
+	for k, v := range fields {
+		elem[k] = v
+	}
+	// Errors are reported when the underlying writer is flushed.
+	_ = d.enc.Encode(elem)
+	return d.id
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kent0106/gotools/internal/testenv"
+)
+
+func TestLSIF(t *testing.T) {
+	testenv.NeedsGo1Point(t, 13)
+
+	tmpDir, err := ioutil.TempDir("", "lsif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	files := map[string]string{
+		"go.mod": "module mod.com\n\ngo 1.12\n",
+		"a/a.go": `package a
+
+// Hello says hello.
+func Hello() string { return greeter{}.greet() }
+
+type greeter struct{}
+
+func (greeter) greet() string { return "hello" }
+`,
+		"b/b.go": `package b
+
+import "mod.com/a"
+
+var S = a.Hello()
+`,
+		// Files excluded by build constraints or outside any package are
+		// not dumped.
+		"a/ignored.go": `// +build ignore
+
+package a
+`,
+		"testdata/x.go": "package x\n",
+	}
+	for name, content := range files {
+		fname := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fname, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(tmpDir, "dump.lsif")
+	app := New("gopls-test", tmpDir, os.Environ(), nil)
+	if err := app.Run(context.Background(), "lsif", "-o", out); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	elems := make(map[int]map[string]interface{})
+	var monikers []map[string]interface{}
+	nexts := make(map[int]int) // range -> result set
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var elem map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &elem); err != nil {
+			t.Fatal(err)
+		}
+		elems[int(elem["id"].(float64))] = elem
+		switch elem["label"] {
+		case "document":
+			uri := elem["uri"].(string)
+			if strings.HasSuffix(uri, "ignored.go") || strings.Contains(uri, "testdata") {
+				t.Errorf("unexpected document %s", uri)
+			}
+		case "moniker":
+			if elem["type"] == "vertex" {
+				monikers = append(monikers, elem)
+			}
+		case "next":
+			nexts[int(elem["outV"].(float64))] = int(elem["inV"].(float64))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var found, foundLocal bool
+	for _, m := range monikers {
+		if m["identifier"] == "mod.com/a:Hello" && m["kind"] == "export" && m["scheme"] == "go" {
+			found = true
+		}
+		if m["identifier"] == "mod.com/a:greeter" && m["kind"] == "local" && m["unique"] == "project" {
+			foundLocal = true
+		}
+	}
+	if !found {
+		t.Errorf("no export moniker for mod.com/a:Hello in %v", monikers)
+	}
+	if !foundLocal {
+		t.Errorf("no local moniker for mod.com/a:greeter in %v", monikers)
+	}
+
+	// The declaration of Hello and its use in b.go share a result set.
+	helloSets := make(map[int]int)
+	for id, elem := range elems {
+		if elem["label"] != "range" {
+			continue
+		}
+		start := elem["start"].(map[string]interface{})
+		if start["line"] == 3.0 && start["character"] == 5.0 || start["line"] == 4.0 && start["character"] == 10.0 {
+			helloSets[nexts[id]]++
+		}
+	}
+	if len(helloSets) != 1 {
+		t.Errorf("got %d result sets for Hello, want 1", len(helloSets))
+	}
+	for set, n := range helloSets {
+		if n != 2 {
+			t.Errorf("got %d ranges in the result set of Hello, want 2", n)
+		}
+		var hasHover bool
+		for _, elem := range elems {
+			if elem["label"] == "textDocument/hover" && int(elem["outV"].(float64)) == set {
+				hover := elems[int(elem["inV"].(float64))]
+				hasHover = strings.Contains(hover["result"].(map[string]interface{})["contents"].(map[string]interface{})["value"].(string), "Hello says hello")
+			}
+		}
+		if !hasHover {
+			t.Error("missing hover for Hello")
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kent0106/gotools/internal/lsp/command"
+	"github.com/kent0106/gotools/internal/lsp/diff"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/lsp/source"
+	"github.com/kent0106/gotools/internal/span"
+	"github.com/kent0106/gotools/internal/tool"
+	errors "golang.org/x/xerrors"
+)
+
+// tags implements the tags verb for gopls.
+type tags struct {
+	Add       string `flag:"add" help:"comma-separated keys of the tags to add"`
+	Remove    string `flag:"remove" help:"comma-separated keys of the tags to remove"`
+	Normalize string `flag:"normalize" help:"comma-separated keys of the tags to normalize"`
+	Case      string `flag:"case" help:"how names are derived from field names: camel, snake or kebab"`
+	OmitEmpty bool   `flag:"omitempty" help:"add the omitempty option to the added and normalized tags"`
+	Diff      bool   `flag:"d" help:"display diffs instead of rewriting files"`
+	Write     bool   `flag:"w" help:"write result to (source) file instead of stdout"`
+
+	app *Application
+}
+
+func (t *tags) Name() string      { return "tags" }
+func (t *tags) Usage() string     { return "<position>" }
+func (t *tags) ShortHelp() string { return "edit the tags of the fields of a struct type" }
+func (t *tags) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+Example: add json and yaml tags in snake case to the fields of the struct
+type at the given position, and remove their db tags:
+
+  $ # 1-based location (:line:column or :#offset) within the struct type
+  $ gopls tags -add json,yaml -remove db -case snake helper/helper.go:8:6
+
+gopls tags flags are:
+`)
+	f.PrintDefaults()
+}
+
+// Run edits the tags of the struct type at the specified position and
+// either;
+// - if -w is specified, updates the file in place;
+// - if -d is specified, prints out unified diffs of the changes; or
+// - otherwise, prints the new version to stdout.
+func (t *tags) Run(ctx context.Context, args ...string) error {
+	if len(args) != 1 {
+		return tool.CommandLineErrorf("tags expects 1 argument (position)")
+	}
+	if t.Add == "" && t.Remove == "" && t.Normalize == "" {
+		return tool.CommandLineErrorf("tags expects at least one of -add, -remove or -normalize")
+	}
+	conn, err := t.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	from := span.Parse(args[0])
+	file := conn.AddFile(ctx, from.URI())
+	if file.err != nil {
+		return file.err
+	}
+	loc, err := file.mapper.Location(from)
+	if err != nil {
+		return err
+	}
+	cmd, err := command.NewModifyTagsCommand("", command.ModifyTagsArgs{
+		URI:       loc.URI,
+		Range:     loc.Range,
+		Add:       splitKeys(t.Add),
+		Remove:    splitKeys(t.Remove),
+		Normalize: splitKeys(t.Normalize),
+		Case:      t.Case,
+		OmitEmpty: t.OmitEmpty,
+	})
+	if err != nil {
+		return err
+	}
+	conn.Client.recordEdits()
+	params := &protocol.ExecuteCommandParams{Command: cmd.Command, Arguments: cmd.Arguments}
+	_, err = conn.ExecuteCommand(ctx, params)
+	changes := conn.Client.takeEdits()
+	if err != nil {
+		return errors.Errorf("%v: %v", from, err)
+	}
+	var edits []protocol.TextEdit
+	for _, e := range changes {
+		for _, c := range e.DocumentChanges {
+			if c.TextDocumentEdit != nil && fileURI(c.TextDocumentEdit.TextDocument.URI) == file.uri {
+				edits = append(edits, c.TextDocumentEdit.Edits...)
+			}
+		}
+	}
+
+	sedits, err := source.FromProtocolEdits(file.mapper, edits)
+	if err != nil {
+		return errors.Errorf("%v: %v", edits, err)
+	}
+	newContent := diff.ApplyEdits(string(file.mapper.Content), sedits)
+
+	filename := file.uri.Filename()
+	switch {
+	case t.Write:
+		if len(edits) > 0 {
+			return ioutil.WriteFile(filename, []byte(newContent), 0644)
+		}
+	case t.Diff:
+		diffs := diff.ToUnified(filename+".orig", filename, string(file.mapper.Content), sedits)
+		fmt.Print(diffs)
+	default:
+		fmt.Print(newContent)
+	}
+	return nil
+}
+
+// splitKeys splits a comma-separated list of tag keys.
+func splitKeys(s string) []string {
+	var keys []string
+	for _, key := range strings.Split(s, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
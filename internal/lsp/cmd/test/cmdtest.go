@@ -104,6 +104,22 @@ func (r *runner) MethodExtraction(t *testing.T, start span.Span, end span.Span)
 	//TODO: function extraction not supported on command line
 }
 
+func (r *runner) RangeFormat(t *testing.T, start span.Span, end span.Span) {
+	//TODO: range formatting not supported on command line
+}
+
+func (r *runner) OnTypeFormat(t *testing.T, spn span.Span, ch string) {
+	//TODO: on-type formatting not supported on command line
+}
+
+func (r *runner) SelectionRanges(t *testing.T, spn span.Span) {
+	//TODO: selection ranges not supported on command line
+}
+
+func (r *runner) LinkedEditingRange(t *testing.T, src span.Span, want []span.Span) {
+	//TODO: linked editing ranges not supported on command line
+}
+
 func (r *runner) AddImport(t *testing.T, uri span.URI, expectedImport string) {
 	//TODO: import addition not supported on command line
 }
@@ -0,0 +1,24 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmdtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kent0106/gotools/internal/lsp/tests"
+	"github.com/kent0106/gotools/internal/span"
+)
+
+func (r *runner) StructTags(t *testing.T, spn span.Span, tag tests.StructTag) {
+	filename := spn.URI().Filename()
+	got, _ := r.NormalizeGoplsCmd(t, "tags", "-add", tag.Add, "-case", tag.Case, fmt.Sprint(spn))
+	want := string(r.data.Golden("tags_"+tests.SpanName(spn), filename, func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+	if want != got {
+		t.Errorf("tags failed for %s:\n%s", filename, tests.Diff(t, want, got))
+	}
+}
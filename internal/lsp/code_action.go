@@ -6,6 +6,7 @@ package lsp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -85,11 +86,26 @@ func (s *Server) codeAction(ctx context.Context, params *protocol.CodeActionPara
 			return nil, nil
 		}
 		diagnostics := params.Context.Diagnostics
+		resolve := snapshot.View().Options().CodeActionResolveSupported
 
 		// First, process any missing imports and pair them with the
 		// diagnostics they fix.
 		if wantQuickFixes := wanted[protocol.QuickFix] && len(diagnostics) > 0; wantQuickFixes || wanted[protocol.SourceOrganizeImports] {
-			importEdits, importEditsPerFix, err := source.AllImportsFixes(ctx, snapshot, fh)
+			var (
+				importEdits       []protocol.TextEdit
+				importEditsPerFix []*source.ImportFix
+				err               error
+			)
+			if resolve {
+				// The edits are computed when the actions are resolved.
+				var fixes []*imports.ImportFix
+				fixes, err = source.AllImportFixes(ctx, snapshot, fh)
+				for _, fix := range fixes {
+					importEditsPerFix = append(importEditsPerFix, &source.ImportFix{Fix: fix})
+				}
+			} else {
+				importEdits, importEditsPerFix, err = source.AllImportsFixes(ctx, snapshot, fh)
+			}
 			if err != nil {
 				event.Error(ctx, "imports fixes", err, tag.File.Of(fh.URI().Filename()))
 			}
@@ -101,46 +117,61 @@ func (s *Server) codeAction(ctx context.Context, params *protocol.CodeActionPara
 					if len(fixes) == 0 {
 						continue
 					}
-					codeActions = append(codeActions, protocol.CodeAction{
-						Title: importFixTitle(importFix.Fix),
-						Kind:  protocol.QuickFix,
-						Edit: protocol.WorkspaceEdit{
-							DocumentChanges: documentChanges(fh, importFix.Edits),
-						},
+					action := protocol.CodeAction{
+						Title:       importFixTitle(importFix.Fix),
+						Kind:        protocol.QuickFix,
 						Diagnostics: fixes,
-					})
+					}
+					if resolve {
+						action.Data = codeActionData{
+							URI:     params.TextDocument.URI,
+							Imports: []*imports.ImportFix{importFix.Fix},
+						}
+					} else {
+						action.Edit = protocol.WorkspaceEdit{
+							DocumentChanges: documentChanges(fh, importFix.Edits),
+						}
+					}
+					codeActions = append(codeActions, action)
 				}
 			}
 
 			// Send all of the import edits as one code action if the file is
-			// being organized.
-			if wanted[protocol.SourceOrganizeImports] && len(importEdits) > 0 {
-				codeActions = append(codeActions, protocol.CodeAction{
-					Title: "Organize Imports",
-					Kind:  protocol.SourceOrganizeImports,
-					Edit: protocol.WorkspaceEdit{
-						DocumentChanges: documentChanges(fh, importEdits),
-					},
-				})
+			// being organized. When the edits are resolved later, whether
+			// the imports only need sorting is unknown, so the action is
+			// also offered when it is explicitly requested.
+			if wanted[protocol.SourceOrganizeImports] {
+				if resolve && (len(importEditsPerFix) > 0 || len(params.Context.Only) > 0) {
+					var fixes []*imports.ImportFix
+					for _, importFix := range importEditsPerFix {
+						fixes = append(fixes, importFix.Fix)
+					}
+					codeActions = append(codeActions, protocol.CodeAction{
+						Title: "Organize Imports",
+						Kind:  protocol.SourceOrganizeImports,
+						Data: codeActionData{
+							URI:     params.TextDocument.URI,
+							Imports: fixes,
+						},
+					})
+				} else if len(importEdits) > 0 {
+					codeActions = append(codeActions, protocol.CodeAction{
+						Title: "Organize Imports",
+						Kind:  protocol.SourceOrganizeImports,
+						Edit: protocol.WorkspaceEdit{
+							DocumentChanges: documentChanges(fh, importEdits),
+						},
+					})
+				}
 			}
 		}
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
 		}
-		pkg, err := snapshot.PackageForFile(ctx, fh.URI(), source.TypecheckFull, source.WidestPackage)
-		if err != nil {
-			return nil, err
-		}
-
-		pkgDiagnostics, err := snapshot.DiagnosePackage(ctx, pkg)
-		if err != nil {
-			return nil, err
-		}
-		analysisDiags, err := source.Analyze(ctx, snapshot, pkg, true)
+		pkg, fileDiags, err := fileDiagnostics(ctx, snapshot, fh)
 		if err != nil {
 			return nil, err
 		}
-		fileDiags := append(pkgDiagnostics[uri], analysisDiags[uri]...)
 
 		// Split diagnostics into fixes, which must match incoming diagnostics,
 		// and non-fixes, which must match the requested range. Build actions
@@ -191,6 +222,30 @@ func (s *Server) codeAction(ctx context.Context, params *protocol.CodeActionPara
 			codeActions = append(codeActions, fixes...)
 		}
 
+		if wanted[protocol.RefactorRewrite] {
+			fixes, err := rewriteFixes(ctx, snapshot, uri, params.Range)
+			if err != nil {
+				return nil, err
+			}
+			codeActions = append(codeActions, fixes...)
+		}
+
+		if wanted[protocol.RefactorInline] {
+			fixes, err := inlineFixes(ctx, snapshot, uri, params.Range)
+			if err != nil {
+				return nil, err
+			}
+			codeActions = append(codeActions, fixes...)
+		}
+
+		if wanted[protocol.GoAddTest] {
+			fixes, err := addTest(ctx, snapshot, uri, params.Range)
+			if err != nil {
+				return nil, err
+			}
+			codeActions = append(codeActions, fixes...)
+		}
+
 		if wanted[protocol.GoTest] {
 			fixes, err := goTest(ctx, snapshot, uri, params.Range)
 			if err != nil {
@@ -210,9 +265,147 @@ func (s *Server) codeAction(ctx context.Context, params *protocol.CodeActionPara
 			filtered = append(filtered, action)
 		}
 	}
+	if snapshot.View().Options().CodeActionResolveSupported {
+		for i := range filtered {
+			if err := deferEdits(&filtered[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return filtered, nil
 }
 
+// codeActionData is the data of a code action returned without its edits,
+// from which resolveCodeAction computes them.
+type codeActionData struct {
+	// URI is the file for which the action was returned.
+	URI protocol.DocumentURI
+
+	// Fix, if set, is the fix applied by source.ApplyFix to Range.
+	Fix   string `json:",omitempty"`
+	Range protocol.Range
+
+	// Diagnostic, if set, identifies the diagnostic whose suggested fix
+	// with the title of the action is applied.
+	Diagnostic *protocol.Diagnostic `json:",omitempty"`
+
+	// Otherwise, the action applies the fixes to the imports of the file,
+	// which are all of them when it organizes the imports.
+	Imports []*imports.ImportFix `json:",omitempty"`
+}
+
+// deferEdits replaces the apply_fix command of action, if any, with data
+// that allows the client to resolve the edits of the fix once the action
+// is chosen. The edits are then computed by resolveCodeAction.
+func deferEdits(action *protocol.CodeAction) error {
+	if action.Command == nil || action.Command.Command != command.ApplyFix.ID() || len(action.Edit.DocumentChanges) > 0 {
+		return nil
+	}
+	var args command.ApplyFixArgs
+	if err := command.UnmarshalArgs(action.Command.Arguments, &args); err != nil {
+		return err
+	}
+	action.Command = nil
+	action.Data = codeActionData{
+		URI:   args.URI,
+		Fix:   args.Fix,
+		Range: args.Range,
+	}
+	return nil
+}
+
+// resolveCodeAction computes the edits of a code action returned by
+// codeAction without them.
+func (s *Server) resolveCodeAction(ctx context.Context, action *protocol.CodeAction) (*protocol.CodeAction, error) {
+	if action.Data == nil {
+		return action, nil
+	}
+	raw, err := json.Marshal(action.Data)
+	if err != nil {
+		return nil, err
+	}
+	var data codeActionData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, errors.Errorf("unmarshaling code action data: %w", err)
+	}
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, data.URI, source.UnknownKind)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+	var changes []protocol.DocumentChanges
+	switch {
+	case data.Fix != "":
+		edits, err := source.ApplyFix(ctx, data.Fix, snapshot, fh, data.Range)
+		if err != nil {
+			return nil, err
+		}
+		changes = protocol.TextDocumentChanges(edits)
+	case data.Diagnostic != nil:
+		changes, err = diagnosticFixChanges(ctx, snapshot, fh, *data.Diagnostic, action.Title)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		edits, err := source.ImportFixesEdits(ctx, snapshot, fh, data.Imports)
+		if err != nil {
+			return nil, err
+		}
+		changes = documentChanges(fh, edits)
+	}
+	action.Edit = protocol.WorkspaceEdit{
+		DocumentChanges: changes,
+	}
+	return action, nil
+}
+
+// fileDiagnostics returns the diagnostics of the Go file, including those of
+// the analyzers, and the package in which it was diagnosed.
+func fileDiagnostics(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle) (source.Package, []*source.Diagnostic, error) {
+	pkg, err := snapshot.PackageForFile(ctx, fh.URI(), source.TypecheckFull, source.WidestPackage)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkgDiagnostics, err := snapshot.DiagnosePackage(ctx, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	analysisDiags, err := source.Analyze(ctx, snapshot, pkg, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkg, append(pkgDiagnostics[fh.URI()], analysisDiags[fh.URI()]...), nil
+}
+
+// diagnosticFixChanges returns the document changes of the suggested fix
+// with the given title of the diagnostic pd of the file.
+func diagnosticFixChanges(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, pd protocol.Diagnostic, title string) ([]protocol.DocumentChanges, error) {
+	var (
+		diags []*source.Diagnostic
+		err   error
+	)
+	switch fh.Kind() {
+	case source.Mod:
+		diags, err = mod.DiagnosticsForMod(ctx, snapshot, fh)
+	case source.Go:
+		_, diags, err = fileDiagnostics(ctx, snapshot, fh)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, sd := range diags {
+		if !sameDiagnostic(pd, sd) {
+			continue
+		}
+		for _, fix := range sd.SuggestedFixes {
+			if fix.Title == title {
+				return suggestedFixChanges(ctx, snapshot, fix)
+			}
+		}
+	}
+	return nil, fmt.Errorf("no fix %q of the diagnostic %q at %v", title, pd.Message, pd.Range)
+}
+
 func (s *Server) getSupportedCodeActions() []protocol.CodeActionKind {
 	allCodeActionKinds := make(map[protocol.CodeActionKind]struct{})
 	for _, kinds := range s.session.Options().SupportedCodeActions {
@@ -333,16 +526,161 @@ func extractionFixes(ctx context.Context, snapshot source.Snapshot, pkg source.P
 	return actions, nil
 }
 
-func documentChanges(fh source.VersionedFileHandle, edits []protocol.TextEdit) []protocol.TextDocumentEdit {
-	return []protocol.TextDocumentEdit{
+func inlineFixes(ctx context.Context, snapshot source.Snapshot, uri span.URI, rng protocol.Range) ([]protocol.CodeAction, error) {
+	if rng.Start == rng.End {
+		return nil, nil
+	}
+	fh, err := snapshot.GetFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	pkg, pgf, err := source.GetParsedFile(ctx, snapshot, fh, source.NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for inlining: %w", err)
+	}
+	srng, err := pgf.Mapper.RangeToSpanRange(rng)
+	if err != nil {
+		return nil, err
+	}
+	puri := protocol.URIFromSpanURI(uri)
+	var commands []protocol.Command
+	if source.CanInlineCall(snapshot.FileSet(), pkg, pgf, srng) {
+		cmd, err := command.NewApplyFixCommand("Inline call", command.ApplyFixArgs{
+			URI:   puri,
+			Fix:   source.InlineCall,
+			Range: rng,
+		})
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	if source.CanInlineVariable(srng, pgf.File, pkg.GetTypes(), pkg.GetTypesInfo()) {
+		cmd, err := command.NewApplyFixCommand("Inline variable", command.ApplyFixArgs{
+			URI:   puri,
+			Fix:   source.InlineVariable,
+			Range: rng,
+		})
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	var actions []protocol.CodeAction
+	for i := range commands {
+		actions = append(actions, protocol.CodeAction{
+			Title:   commands[i].Title,
+			Kind:    protocol.RefactorInline,
+			Command: &commands[i],
+		})
+	}
+	return actions, nil
+}
+
+func rewriteFixes(ctx context.Context, snapshot source.Snapshot, uri span.URI, rng protocol.Range) ([]protocol.CodeAction, error) {
+	fh, err := snapshot.GetFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	pkg, pgf, err := source.GetParsedFile(ctx, snapshot, fh, source.NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for rewriting: %w", err)
+	}
+	srng, err := pgf.Mapper.RangeToSpanRange(rng)
+	if err != nil {
+		return nil, err
+	}
+	puri := protocol.URIFromSpanURI(uri)
+	var commands []protocol.Command
+	if title, ok := source.CanFillSwitch(ctx, snapshot, pkg, pgf, srng); ok {
+		cmd, err := command.NewApplyFixCommand(title, command.ApplyFixArgs{
+			URI:   puri,
+			Fix:   source.FillSwitch,
+			Range: rng,
+		})
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	for _, r := range source.IfRewrites(snapshot.FileSet(), pkg, pgf, srng) {
+		cmd, err := command.NewApplyFixCommand(r.Title, command.ApplyFixArgs{
+			URI:   puri,
+			Fix:   r.Fix,
+			Range: rng,
+		})
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	tagCommands, err := structTagCommands(snapshot, pgf, puri, rng, srng)
+	if err != nil {
+		return nil, err
+	}
+	commands = append(commands, tagCommands...)
+	var actions []protocol.CodeAction
+	for i := range commands {
+		actions = append(actions, protocol.CodeAction{
+			Title:   commands[i].Title,
+			Kind:    protocol.RefactorRewrite,
+			Command: &commands[i],
+		})
+	}
+	return actions, nil
+}
+
+// structTagCommands returns the commands adding, removing and normalizing
+// the struct tags configured by the user in the struct type at rng.
+func structTagCommands(snapshot source.Snapshot, pgf *source.ParsedGoFile, uri protocol.DocumentURI, rng protocol.Range, srng span.Range) ([]protocol.Command, error) {
+	opts := snapshot.View().Options()
+	if len(opts.StructTags) == 0 {
+		return nil, nil
+	}
+	keys := strings.Join(opts.StructTags, ", ")
+	var commands []protocol.Command
+	for _, t := range []struct {
+		title string
+		edit  source.TagEdit
+	}{
+		{"Add " + keys + " tags", source.TagEdit{Add: opts.StructTags}},
+		{"Remove " + keys + " tags", source.TagEdit{Remove: opts.StructTags}},
+		{"Normalize " + keys + " tags", source.TagEdit{Normalize: opts.StructTags}},
+	} {
+		t.edit.Case = opts.StructTagCase
+		t.edit.OmitEmpty = opts.StructTagOmitEmpty
+		if !source.CanModifyTags(pgf, srng, t.edit) {
+			continue
+		}
+		cmd, err := command.NewModifyTagsCommand(t.title, command.ModifyTagsArgs{
+			URI:       uri,
+			Range:     rng,
+			Add:       t.edit.Add,
+			Remove:    t.edit.Remove,
+			Normalize: t.edit.Normalize,
+			Case:      string(t.edit.Case),
+			OmitEmpty: t.edit.OmitEmpty,
+		})
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+func documentChanges(fh source.VersionedFileHandle, edits []protocol.TextEdit) []protocol.DocumentChanges {
+	return []protocol.DocumentChanges{
 		{
-			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
-				Version: fh.Version(),
-				TextDocumentIdentifier: protocol.TextDocumentIdentifier{
-					URI: protocol.URIFromSpanURI(fh.URI()),
+			TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					Version: fh.Version(),
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+						URI: protocol.URIFromSpanURI(fh.URI()),
+					},
 				},
+				Edits: edits,
 			},
-			Edits: edits,
 		},
 	}
 }
@@ -373,29 +711,29 @@ func codeActionsMatchingDiagnostics(ctx context.Context, snapshot source.Snapsho
 func codeActionsForDiagnostic(ctx context.Context, snapshot source.Snapshot, sd *source.Diagnostic, pd *protocol.Diagnostic) ([]protocol.CodeAction, error) {
 	var actions []protocol.CodeAction
 	for _, fix := range sd.SuggestedFixes {
-		var changes []protocol.TextDocumentEdit
-		for uri, edits := range fix.Edits {
-			fh, err := snapshot.GetVersionedFile(ctx, uri)
+		action := protocol.CodeAction{
+			Title:   fix.Title,
+			Kind:    fix.ActionKind,
+			Command: fix.Command,
+		}
+		if len(fix.Edits) > 0 && snapshot.View().Options().CodeActionResolveSupported {
+			// The edits are computed anew when the action is resolved.
+			action.Data = codeActionData{
+				URI: protocol.URIFromSpanURI(sd.URI),
+				Diagnostic: &protocol.Diagnostic{
+					Range:   sd.Range,
+					Message: sd.Message,
+					Source:  string(sd.Source),
+				},
+			}
+		} else {
+			changes, err := suggestedFixChanges(ctx, snapshot, fix)
 			if err != nil {
 				return nil, err
 			}
-			changes = append(changes, protocol.TextDocumentEdit{
-				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
-					Version: fh.Version(),
-					TextDocumentIdentifier: protocol.TextDocumentIdentifier{
-						URI: protocol.URIFromSpanURI(uri),
-					},
-				},
-				Edits: edits,
-			})
-		}
-		action := protocol.CodeAction{
-			Title: fix.Title,
-			Kind:  fix.ActionKind,
-			Edit: protocol.WorkspaceEdit{
+			action.Edit = protocol.WorkspaceEdit{
 				DocumentChanges: changes,
-			},
-			Command: fix.Command,
+			}
 		}
 		if pd != nil {
 			action.Diagnostics = []protocol.Diagnostic{*pd}
@@ -405,6 +743,28 @@ func codeActionsForDiagnostic(ctx context.Context, snapshot source.Snapshot, sd
 	return actions, nil
 }
 
+// suggestedFixChanges returns the document changes applying the edits of a
+// suggested fix.
+func suggestedFixChanges(ctx context.Context, snapshot source.Snapshot, fix source.SuggestedFix) ([]protocol.DocumentChanges, error) {
+	var changes []protocol.TextDocumentEdit
+	for uri, edits := range fix.Edits {
+		fh, err := snapshot.GetVersionedFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				Version: fh.Version(),
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+					URI: protocol.URIFromSpanURI(uri),
+				},
+			},
+			Edits: edits,
+		})
+	}
+	return protocol.TextDocumentChanges(changes), nil
+}
+
 func sameDiagnostic(pd protocol.Diagnostic, sd *source.Diagnostic) bool {
 	return pd.Message == sd.Message && protocol.CompareRange(pd.Range, sd.Range) == 0 && pd.Source == string(sd.Source)
 }
@@ -447,3 +807,34 @@ func goTest(ctx context.Context, snapshot source.Snapshot, uri span.URI, rng pro
 		Command: &cmd,
 	}}, nil
 }
+
+func addTest(ctx context.Context, snapshot source.Snapshot, uri span.URI, rng protocol.Range) ([]protocol.CodeAction, error) {
+	fh, err := snapshot.GetFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	pkg, pgf, err := source.GetParsedFile(ctx, snapshot, fh, source.NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for adding a test: %w", err)
+	}
+	srng, err := pgf.Mapper.RangeToSpanRange(rng)
+	if err != nil {
+		return nil, err
+	}
+	title, ok := source.CanAddTest(pkg, pgf, srng)
+	if !ok {
+		return nil, nil
+	}
+	cmd, err := command.NewAddTestCommand(title, command.AddTestArgs{
+		URI:   protocol.URIFromSpanURI(uri),
+		Range: rng,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.CodeAction{{
+		Title:   cmd.Title,
+		Kind:    protocol.GoAddTest,
+		Command: &cmd,
+	}}, nil
+}
@@ -6,6 +6,7 @@ package lsp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/kent0106/gotools/internal/lsp/mod"
 	"github.com/kent0106/gotools/internal/lsp/protocol"
 	"github.com/kent0106/gotools/internal/lsp/source"
+	errors "golang.org/x/xerrors"
 )
 
 func (s *Server) codeLens(ctx context.Context, params *protocol.CodeLensParams) ([]protocol.CodeLens, error) {
@@ -44,14 +46,96 @@ func (s *Server) codeLens(ctx context.Context, params *protocol.CodeLensParams)
 			event.Error(ctx, fmt.Sprintf("code lens %s failed", cmd), err)
 			continue
 		}
-		result = append(result, added...)
+		// The commands of the code lenses are computed when they are
+		// resolved.
+		for i, lens := range added {
+			result = append(result, protocol.CodeLens{
+				Range: lens.Range,
+				Data: codeLensData{
+					URI:   params.TextDocument.URI,
+					Lens:  cmd,
+					Index: sameRangeIndex(added, i),
+				},
+			})
+		}
 	}
 	sort.Slice(result, func(i, j int) bool {
 		a, b := result[i], result[j]
 		if protocol.CompareRange(a.Range, b.Range) == 0 {
-			return a.Command.Command < b.Command.Command
+			da, db := a.Data.(codeLensData), b.Data.(codeLensData)
+			if da.Lens == db.Lens {
+				return da.Index < db.Index
+			}
+			return da.Lens < db.Lens
 		}
 		return protocol.CompareRange(a.Range, b.Range) < 0
 	})
 	return result, nil
 }
+
+// codeLensData is the data of a code lens returned without its command, from
+// which resolveCodeLens computes it.
+type codeLensData struct {
+	// URI is the file for which the code lens was returned.
+	URI protocol.DocumentURI
+
+	// Lens is the command of the lens function that returned the code
+	// lens, and Index its index among the code lenses of the function at
+	// the same range.
+	Lens  command.Command
+	Index int
+}
+
+// sameRangeIndex returns the index of the i'th code lens among the code
+// lenses at the same range.
+func sameRangeIndex(lenses []protocol.CodeLens, i int) int {
+	index := 0
+	for _, lens := range lenses[:i] {
+		if protocol.CompareRange(lens.Range, lenses[i].Range) == 0 {
+			index++
+		}
+	}
+	return index
+}
+
+// resolveCodeLens computes the command of a code lens returned by codeLens
+// without it.
+func (s *Server) resolveCodeLens(ctx context.Context, lens *protocol.CodeLens) (*protocol.CodeLens, error) {
+	if lens.Data == nil {
+		return lens, nil
+	}
+	raw, err := json.Marshal(lens.Data)
+	if err != nil {
+		return nil, err
+	}
+	var data codeLensData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, errors.Errorf("unmarshaling code lens data: %w", err)
+	}
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, data.URI, source.UnknownKind)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+	var lf source.LensFunc
+	switch fh.Kind() {
+	case source.Mod:
+		lf = mod.LensFuncs()[data.Lens]
+	case source.Go:
+		lf = source.LensFuncs()[data.Lens]
+	}
+	if lf == nil {
+		return nil, fmt.Errorf("no %s code lens for %s", data.Lens, fh.URI())
+	}
+	lenses, err := lf(ctx, snapshot, fh)
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range lenses {
+		if protocol.CompareRange(l.Range, lens.Range) == 0 && sameRangeIndex(lenses, i) == data.Index {
+			lens.Command = l.Command
+			return lens, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot resolve %s code lens at %v: the file has changed", data.Lens, lens.Range)
+}
@@ -140,7 +140,7 @@ func (c *commandHandler) ApplyFix(ctx context.Context, args command.ApplyFixArgs
 		}
 		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
 			Edit: protocol.WorkspaceEdit{
-				DocumentChanges: edits,
+				DocumentChanges: protocol.TextDocumentChanges(edits),
 			},
 		})
 		if err != nil {
@@ -288,15 +288,7 @@ func (c *commandHandler) RemoveDependency(ctx context.Context, args command.Remo
 		}
 		response, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
 			Edit: protocol.WorkspaceEdit{
-				DocumentChanges: []protocol.TextDocumentEdit{{
-					TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
-						Version: deps.fh.Version(),
-						TextDocumentIdentifier: protocol.TextDocumentIdentifier{
-							URI: protocol.URIFromSpanURI(deps.fh.URI()),
-						},
-					},
-					Edits: edits,
-				}},
+				DocumentChanges: documentChanges(deps.fh, edits),
 			},
 		})
 		if err != nil {
@@ -512,7 +504,7 @@ func (s *Server) runGoModUpdateCommands(ctx context.Context, snapshot source.Sna
 	}
 	response, err := s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
 		Edit: protocol.WorkspaceEdit{
-			DocumentChanges: changes,
+			DocumentChanges: protocol.TextDocumentChanges(changes),
 		},
 	})
 	if err != nil {
@@ -701,6 +693,119 @@ func (c *commandHandler) AddImport(ctx context.Context, args command.AddImportAr
 	})
 }
 
+func (c *commandHandler) ChangeSignature(ctx context.Context, args command.ChangeSignatureArgs) error {
+	return c.run(ctx, commandConfig{
+		progress: "Changing signature",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		edits, err := source.ChangeSignature(ctx, deps.snapshot, deps.fh, args.Position, args.Params)
+		if err != nil {
+			return fmt.Errorf("could not change signature: %v", err)
+		}
+		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+			Edit: protocol.WorkspaceEdit{
+				DocumentChanges: protocol.TextDocumentChanges(edits),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if !r.Applied {
+			return errors.New(r.FailureReason)
+		}
+		return nil
+	})
+}
+
+func (c *commandHandler) AddTest(ctx context.Context, args command.AddTestArgs) error {
+	return c.run(ctx, commandConfig{
+		progress: "Adding test",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := source.AddTest(ctx, deps.snapshot, deps.fh, args.Range)
+		if err != nil {
+			return fmt.Errorf("could not add test: %v", err)
+		}
+		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+			Edit: protocol.WorkspaceEdit{
+				DocumentChanges: changes,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if !r.Applied {
+			return errors.New(r.FailureReason)
+		}
+		return nil
+	})
+}
+
+func (c *commandHandler) MoveDeclaration(ctx context.Context, args command.MoveDeclarationArgs) error {
+	return c.run(ctx, commandConfig{
+		progress: "Moving declaration",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := source.MoveDeclaration(ctx, deps.snapshot, deps.fh, args.Position, args.Dest.SpanURI())
+		if err != nil {
+			return fmt.Errorf("could not move declaration: %v", err)
+		}
+		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+			Edit: protocol.WorkspaceEdit{
+				DocumentChanges: changes,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if !r.Applied {
+			return errors.New(r.FailureReason)
+		}
+		return nil
+	})
+}
+
+func (c *commandHandler) ModifyTags(ctx context.Context, args command.ModifyTagsArgs) error {
+	return c.run(ctx, commandConfig{
+		progress: "Modifying struct tags",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		edit := source.TagEdit{
+			Add:       args.Add,
+			Remove:    args.Remove,
+			Normalize: args.Normalize,
+			Case:      source.StructTagCase(args.Case),
+			OmitEmpty: args.OmitEmpty,
+		}
+		switch edit.Case {
+		case "":
+			edit.Case = deps.snapshot.View().Options().StructTagCase
+		case source.StructTagCamel, source.StructTagSnake, source.StructTagKebab:
+		default:
+			return fmt.Errorf("unknown case %q", args.Case)
+		}
+		edits, err := source.ModifyTags(ctx, deps.snapshot, deps.fh, args.Range, edit)
+		if err != nil {
+			return fmt.Errorf("could not modify struct tags: %v", err)
+		}
+		if len(edits) == 0 {
+			return nil
+		}
+		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+			Edit: protocol.WorkspaceEdit{
+				DocumentChanges: protocol.TextDocumentChanges(edits),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if !r.Applied {
+			return errors.New(r.FailureReason)
+		}
+		return nil
+	})
+}
+
 func (c *commandHandler) WorkspaceMetadata(ctx context.Context) (command.WorkspaceMetadataResult, error) {
 	var result command.WorkspaceMetadataResult
 	for _, view := range c.s.session.Views() {
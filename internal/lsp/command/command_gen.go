@@ -21,13 +21,17 @@ import (
 const (
 	AddDependency     Command = "add_dependency"
 	AddImport         Command = "add_import"
+	AddTest           Command = "add_test"
 	ApplyFix          Command = "apply_fix"
+	ChangeSignature   Command = "change_signature"
 	CheckUpgrades     Command = "check_upgrades"
 	GCDetails         Command = "gc_details"
 	Generate          Command = "generate"
 	GenerateGoplsMod  Command = "generate_gopls_mod"
 	GoGetPackage      Command = "go_get_package"
 	ListKnownPackages Command = "list_known_packages"
+	ModifyTags        Command = "modify_tags"
+	MoveDeclaration   Command = "move_declaration"
 	RegenerateCgo     Command = "regenerate_cgo"
 	RemoveDependency  Command = "remove_dependency"
 	RunTests          Command = "run_tests"
@@ -44,13 +48,17 @@ const (
 var Commands = []Command{
 	AddDependency,
 	AddImport,
+	AddTest,
 	ApplyFix,
+	ChangeSignature,
 	CheckUpgrades,
 	GCDetails,
 	Generate,
 	GenerateGoplsMod,
 	GoGetPackage,
 	ListKnownPackages,
+	ModifyTags,
+	MoveDeclaration,
 	RegenerateCgo,
 	RemoveDependency,
 	RunTests,
@@ -78,12 +86,24 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.AddImport(ctx, a0)
+	case "gopls.add_test":
+		var a0 AddTestArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.AddTest(ctx, a0)
 	case "gopls.apply_fix":
 		var a0 ApplyFixArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
 			return nil, err
 		}
 		return nil, s.ApplyFix(ctx, a0)
+	case "gopls.change_signature":
+		var a0 ChangeSignatureArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ChangeSignature(ctx, a0)
 	case "gopls.check_upgrades":
 		var a0 CheckUpgradesArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -120,6 +140,18 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.ListKnownPackages(ctx, a0)
+	case "gopls.modify_tags":
+		var a0 ModifyTagsArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ModifyTags(ctx, a0)
+	case "gopls.move_declaration":
+		var a0 MoveDeclarationArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.MoveDeclaration(ctx, a0)
 	case "gopls.regenerate_cgo":
 		var a0 URIArg
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -212,6 +244,18 @@ func NewAddImportCommand(title string, a0 AddImportArgs) (protocol.Command, erro
 	}, nil
 }
 
+func NewAddTestCommand(title string, a0 AddTestArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.add_test",
+		Arguments: args,
+	}, nil
+}
+
 func NewApplyFixCommand(title string, a0 ApplyFixArgs) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
@@ -224,6 +268,18 @@ func NewApplyFixCommand(title string, a0 ApplyFixArgs) (protocol.Command, error)
 	}, nil
 }
 
+func NewChangeSignatureCommand(title string, a0 ChangeSignatureArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.change_signature",
+		Arguments: args,
+	}, nil
+}
+
 func NewCheckUpgradesCommand(title string, a0 CheckUpgradesArgs) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
@@ -296,6 +352,30 @@ func NewListKnownPackagesCommand(title string, a0 URIArg) (protocol.Command, err
 	}, nil
 }
 
+func NewModifyTagsCommand(title string, a0 ModifyTagsArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.modify_tags",
+		Arguments: args,
+	}, nil
+}
+
+func NewMoveDeclarationCommand(title string, a0 MoveDeclarationArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.move_declaration",
+		Arguments: args,
+	}, nil
+}
+
 func NewRegenerateCgoCommand(title string, a0 URIArg) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
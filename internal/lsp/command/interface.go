@@ -120,6 +120,30 @@ type Interface interface {
 	// Retrieve a list of packages that are importable from the given URI.
 	ListKnownPackages(context.Context, URIArg) (ListKnownPackagesResult, error)
 
+	// ChangeSignature: Change the signature of a function
+	//
+	// Removes, reorders or adds parameters of a function or method, and
+	// updates its calls and the methods related to it through interfaces.
+	ChangeSignature(context.Context, ChangeSignatureArgs) error
+
+	// AddTest: Add a test for a function
+	//
+	// Adds a table-driven test of a function or method to the test file
+	// next to the file declaring it, creating the test file if needed.
+	AddTest(context.Context, AddTestArgs) error
+
+	// MoveDeclaration: Move a declaration
+	//
+	// Moves a package-level declaration, with the methods of a type, to
+	// another file of its package or of another package, and updates the
+	// references to it.
+	MoveDeclaration(context.Context, MoveDeclarationArgs) error
+
+	// ModifyTags: Modify struct tags
+	//
+	// Adds, removes or normalizes the tags of the fields of a struct type.
+	ModifyTags(context.Context, ModifyTagsArgs) error
+
 	// AddImport: Add an import
 	//
 	// Ask the server to add an import path to a given Go file.  The method will
@@ -220,6 +244,65 @@ type AddImportArgs struct {
 	URI protocol.DocumentURI
 }
 
+type ChangeSignatureArgs struct {
+	// The file containing the name of the function or method.
+	URI protocol.DocumentURI
+	// The position of the name of the function or method.
+	Position protocol.Position
+	// The parameters of the new signature, in order.
+	Params []ChangeSignatureParam
+}
+
+type ChangeSignatureParam struct {
+	// The index of the parameter in the old signature, or -1 for a new
+	// parameter.
+	OldIndex int
+	// The name of a new parameter.
+	Name string
+	// The type of a new parameter.
+	Type string
+	// The argument passed for a new parameter at the existing calls,
+	// inserted verbatim.
+	Value string
+}
+
+type AddTestArgs struct {
+	// The file declaring the function or method.
+	URI protocol.DocumentURI
+	// The range of the declaration header of the function or method.
+	Range protocol.Range
+}
+
+type MoveDeclarationArgs struct {
+	// The file containing the name of the declaration.
+	URI protocol.DocumentURI
+	// The position of the name of the declaration.
+	Position protocol.Position
+	// The file to move the declaration to, which is created if it does not
+	// exist. Its directory must contain a package of the workspace.
+	Dest protocol.DocumentURI
+}
+
+type ModifyTagsArgs struct {
+	// The file containing the struct type.
+	URI protocol.DocumentURI
+	// A range within the struct type.
+	Range protocol.Range
+	// The keys of the tags to add to the fields lacking them.
+	Add []string
+	// The keys of the tags to remove from the fields.
+	Remove []string
+	// The keys of the tags whose names to derive anew from the names of the
+	// fields. The tags of all the fields are also rewritten in the canonical
+	// form.
+	Normalize []string
+	// How the names are derived from the names of the fields: "camel",
+	// "snake" or "kebab".
+	Case string
+	// Whether to add the omitempty option to the added and normalized tags.
+	OmitEmpty bool
+}
+
 type ListKnownPackagesResult struct {
 	// Packages is a list of packages relative
 	// to the URIArg passed by the command request.
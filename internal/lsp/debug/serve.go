@@ -614,6 +614,9 @@ func makeGlobalExporter(stderr io.Writer) event.Exporter {
 			if level < log.Trace {
 				ctx = protocol.LogEvent(ctx, ev, lm, messageType(level))
 			}
+			// All log events, including trace logs, are sent to clients that
+			// enabled tracing.
+			ctx = protocol.LogTraceEvent(ctx, ev, lm)
 		}
 		if i == nil {
 			return ctx
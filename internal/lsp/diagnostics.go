@@ -192,6 +192,11 @@ func (s *Server) diagnose(ctx context.Context, snapshot source.Snapshot, forceAn
 	defer func() {
 		<-s.diagnosticsSema
 	}()
+	defer func() {
+		if ctx.Err() == nil {
+			s.markDiagnosed(snapshot)
+		}
+	}()
 
 	// First, diagnose the go.mod file.
 	modReports, modErr := mod.Diagnostics(ctx, snapshot)
@@ -365,6 +370,26 @@ func (s *Server) storeDiagnostics(snapshot source.Snapshot, uri span.URI, dsourc
 	s.diagnostics[uri].reports[dsource] = report
 }
 
+// markDiagnosed records that all of the diagnostics of snapshot have been
+// stored.
+func (s *Server) markDiagnosed(snapshot source.Snapshot) {
+	s.diagnosticsMu.Lock()
+	defer s.diagnosticsMu.Unlock()
+	folder := snapshot.View().Folder()
+	if id, ok := s.diagnosedSnapshots[folder]; !ok || id < snapshot.ID() {
+		s.diagnosedSnapshots[folder] = snapshot.ID()
+	}
+}
+
+// diagnosed reports whether all of the diagnostics of snapshot, or of a later
+// snapshot of its view, have been stored.
+func (s *Server) diagnosed(snapshot source.Snapshot) bool {
+	s.diagnosticsMu.Lock()
+	defer s.diagnosticsMu.Unlock()
+	id, ok := s.diagnosedSnapshots[snapshot.View().Folder()]
+	return ok && id >= snapshot.ID()
+}
+
 // clearDiagnosticSource clears all diagnostics for a given source type. It is
 // necessary for cases where diagnostics have been invalidated by something
 // other than a snapshot change, for example when gc_details is toggled.
@@ -539,6 +564,167 @@ func (s *Server) publishDiagnostics(ctx context.Context, final bool, snapshot so
 	}
 }
 
+// diagnostic implements the textDocument/diagnostic request, which lets
+// clients pull the diagnostics for a single document instead of waiting for
+// them to be published. The result ID of a report is the hash of its
+// diagnostics, so an unchanged report is returned if the client already has
+// the current diagnostics.
+func (s *Server) diagnostic(ctx context.Context, params *protocol.DocumentDiagnosticParams) (protocol.DocumentDiagnosticReport, error) {
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.UnknownKind)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+	s.diagnoseFile(ctx, snapshot, fh)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	diags := s.snapshotDiagnostics(snapshot, fh.URI())
+	hash := hashDiagnostics(diags...)
+	if hash == params.PreviousResultID {
+		return &protocol.RelatedUnchangedDocumentDiagnosticReport{
+			UnchangedDocumentDiagnosticReport: protocol.UnchangedDocumentDiagnosticReport{
+				Kind:     "unchanged",
+				ResultID: hash,
+			},
+		}, nil
+	}
+	return &protocol.RelatedFullDocumentDiagnosticReport{
+		FullDocumentDiagnosticReport: protocol.FullDocumentDiagnosticReport{
+			Kind:     "full",
+			ResultID: hash,
+			Items:    toProtocolDiagnostics(diags),
+		},
+	}, nil
+}
+
+// diagnosticWorkspace implements the workspace/diagnostic request. It
+// reports the diagnostics of every file in the workspace that has
+// diagnostics, or for which the client holds a previous result. Files whose
+// diagnostics match the client's previous result ID get an unchanged report.
+func (s *Server) diagnosticWorkspace(ctx context.Context, params *protocol.WorkspaceDiagnosticParams) (*protocol.WorkspaceDiagnosticReport, error) {
+	previous := make(map[span.URI]string)
+	for _, prev := range params.PreviousResultIds {
+		previous[prev.URI.SpanURI()] = prev.Value
+	}
+	report := &protocol.WorkspaceDiagnosticReport{
+		Items: []protocol.WorkspaceDocumentDiagnosticReport{},
+	}
+	seen := make(map[span.URI]bool)
+	for _, view := range s.session.Views() {
+		snapshot, release := view.Snapshot(ctx)
+		// The snapshot's diagnostics are usually stored already, unless they
+		// are still being debounced.
+		if !s.diagnosed(snapshot) {
+			s.diagnose(ctx, snapshot, false)
+		}
+		if ctx.Err() != nil {
+			release()
+			return nil, ctx.Err()
+		}
+		s.diagnosticsMu.Lock()
+		var uris []span.URI
+		for uri := range s.diagnostics {
+			uris = append(uris, uri)
+		}
+		s.diagnosticsMu.Unlock()
+		for uri := range previous {
+			uris = append(uris, uri)
+		}
+		for _, uri := range uris {
+			if seen[uri] {
+				continue
+			}
+			fh := snapshot.FindFile(uri)
+			if fh == nil {
+				continue
+			}
+			seen[uri] = true
+			diags := s.snapshotDiagnostics(snapshot, uri)
+			prev, ok := previous[uri]
+			if len(diags) == 0 && !ok {
+				continue
+			}
+			hash := hashDiagnostics(diags...)
+			if hash == prev {
+				report.Items = append(report.Items, &protocol.WorkspaceUnchangedDocumentDiagnosticReport{
+					URI:     protocol.URIFromSpanURI(uri),
+					Version: fh.Version(),
+					UnchangedDocumentDiagnosticReport: protocol.UnchangedDocumentDiagnosticReport{
+						Kind:     "unchanged",
+						ResultID: hash,
+					},
+				})
+				continue
+			}
+			report.Items = append(report.Items, &protocol.WorkspaceFullDocumentDiagnosticReport{
+				URI:     protocol.URIFromSpanURI(uri),
+				Version: fh.Version(),
+				FullDocumentDiagnosticReport: protocol.FullDocumentDiagnosticReport{
+					Kind:     "full",
+					ResultID: hash,
+					Items:    toProtocolDiagnostics(diags),
+				},
+			})
+		}
+		release()
+	}
+	return report, nil
+}
+
+// diagnoseFile stores the diagnostics for fh in the given snapshot. Unlike
+// the diagnostics computed when the workspace changes, analyzers are always
+// run, as the client has explicitly asked for the file's diagnostics.
+func (s *Server) diagnoseFile(ctx context.Context, snapshot source.Snapshot, fh source.VersionedFileHandle) {
+	switch fh.Kind() {
+	case source.Mod:
+		diags, err := mod.DiagnosticsForMod(ctx, snapshot, fh)
+		if err != nil {
+			event.Error(ctx, "warning: diagnose go.mod", err, tag.URI.Of(fh.URI()), tag.Snapshot.Of(snapshot.ID()))
+			return
+		}
+		s.storeDiagnostics(snapshot, fh.URI(), modSource, diags)
+	case source.Tmpl:
+		s.storeDiagnostics(snapshot, fh.URI(), typeCheckSource, template.Diagnose(fh))
+	case source.Go:
+		if snapshot.IsBuiltin(ctx, fh.URI()) {
+			return
+		}
+		pkgs, err := snapshot.PackagesForFile(ctx, fh.URI(), source.TypecheckFull, false)
+		if err != nil {
+			if diagnostic := s.checkForOrphanedFile(ctx, snapshot, fh); diagnostic != nil {
+				s.storeDiagnostics(snapshot, fh.URI(), orphanedSource, []*source.Diagnostic{diagnostic})
+			}
+			return
+		}
+		for _, pkg := range pkgs {
+			s.diagnosePkg(ctx, snapshot, pkg, true)
+		}
+	}
+}
+
+// snapshotDiagnostics returns the stored diagnostics for uri that were
+// computed for snapshot.
+func (s *Server) snapshotDiagnostics(snapshot source.Snapshot, uri span.URI) []*source.Diagnostic {
+	s.diagnosticsMu.Lock()
+	defer s.diagnosticsMu.Unlock()
+	r, ok := s.diagnostics[uri]
+	if !ok {
+		return nil
+	}
+	var diags []*source.Diagnostic
+	for _, report := range r.reports {
+		if report.snapshotID != snapshot.ID() {
+			continue
+		}
+		for _, d := range report.diags {
+			diags = append(diags, d)
+		}
+	}
+	source.SortDiagnostics(diags)
+	return diags
+}
+
 func toProtocolDiagnostics(diagnostics []*source.Diagnostic) []protocol.Diagnostic {
 	reports := []protocol.Diagnostic{}
 	for _, diag := range diagnostics {
@@ -14,6 +14,7 @@ import (
 // ClientHooks are called to handle the corresponding client LSP method.
 type ClientHooks struct {
 	OnLogMessage             func(context.Context, *protocol.LogMessageParams) error
+	OnLogTrace               func(context.Context, *protocol.LogTraceParams) error
 	OnDiagnostics            func(context.Context, *protocol.PublishDiagnosticsParams) error
 	OnWorkDoneProgressCreate func(context.Context, *protocol.WorkDoneProgressCreateParams) error
 	OnProgress               func(context.Context, *protocol.ProgressParams) error
@@ -56,6 +57,13 @@ func (c *Client) LogMessage(ctx context.Context, params *protocol.LogMessagePara
 	return nil
 }
 
+func (c *Client) LogTrace(ctx context.Context, params *protocol.LogTraceParams) error {
+	if c.hooks.OnLogTrace != nil {
+		return c.hooks.OnLogTrace(ctx, params)
+	}
+	return nil
+}
+
 func (c *Client) Event(ctx context.Context, event *interface{}) error {
 	return nil
 }
@@ -120,7 +128,7 @@ func (c *Client) ApplyEdit(ctx context.Context, params *protocol.ApplyWorkspaceE
 		return &protocol.ApplyWorkspaceEditResponse{FailureReason: "Edit.Changes is unsupported"}, nil
 	}
 	for _, change := range params.Edit.DocumentChanges {
-		if err := c.editor.applyProtocolEdit(ctx, change); err != nil {
+		if err := c.editor.applyDocumentChange(ctx, change); err != nil {
 			return nil, err
 		}
 	}
@@ -7,6 +7,7 @@ package fake
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -84,6 +85,11 @@ type EditorConfig struct {
 	// their default state.
 	CodeLenses map[string]bool
 
+	// InlayHints is a map defining whether inlay hints are enabled, keyed by
+	// the name of the hint. Hints which are not present in this map are left in
+	// their default state.
+	InlayHints map[string]bool
+
 	// SymbolMatcher is the config associated with the "symbolMatcher" gopls
 	// config option.
 	SymbolMatcher, SymbolStyle *string
@@ -114,6 +120,10 @@ type EditorConfig struct {
 	// Whether to edit files with windows line endings.
 	WindowsLineEndings bool
 
+	// Whether to advertise support for resolving the edits of code actions
+	// lazily.
+	CodeActionResolveSupport bool
+
 	ImportShortcut                 string
 	DirectoryFilters               []string
 	VerboseOutput                  bool
@@ -235,6 +245,9 @@ func (e *Editor) configuration() map[string]interface{} {
 	if e.Config.CodeLenses != nil {
 		config["codelenses"] = e.Config.CodeLenses
 	}
+	if e.Config.InlayHints != nil {
+		config["hints"] = e.Config.InlayHints
+	}
 	if e.Config.SymbolMatcher != nil {
 		config["symbolMatcher"] = *e.Config.SymbolMatcher
 	}
@@ -278,6 +291,10 @@ func (e *Editor) initialize(ctx context.Context, workspaceFolders []string) erro
 	}
 
 	params.Capabilities.Workspace.Configuration = true
+	params.Capabilities.Workspace.WorkspaceEdit = &protocol.WorkspaceEditClientCapabilities{
+		DocumentChanges:    true,
+		ResourceOperations: []protocol.ResourceOperationKind{protocol.Create},
+	}
 	params.Capabilities.Window.WorkDoneProgress = true
 	// TODO: set client capabilities
 	params.Capabilities.TextDocument.Completion.CompletionItem.TagSupport.ValueSet = []protocol.CompletionItemTag{protocol.ComplDeprecated}
@@ -288,6 +305,12 @@ func (e *Editor) initialize(ctx context.Context, workspaceFolders []string) erro
 
 	params.Capabilities.TextDocument.Completion.CompletionItem.SnippetSupport = true
 
+	if e.Config.CodeActionResolveSupport {
+		// Edits of code actions are resolved when the actions are applied.
+		params.Capabilities.TextDocument.CodeAction.DataSupport = true
+		params.Capabilities.TextDocument.CodeAction.ResolveSupport.Properties = []string{"edit"}
+	}
+
 	// This is a bit of a hack, since the fake editor doesn't actually support
 	// watching changed files that match a specific glob pattern. However, the
 	// editor does send didChangeWatchedFiles notifications, so set this to
@@ -827,13 +850,26 @@ func (e *Editor) ApplyQuickFixes(ctx context.Context, path string, rng *protocol
 
 // ApplyCodeAction applies the given code action.
 func (e *Editor) ApplyCodeAction(ctx context.Context, action protocol.CodeAction) error {
+	if action.Data != nil && len(action.Edit.DocumentChanges) == 0 {
+		resolved, err := e.Server.ResolveCodeAction(ctx, &action)
+		if err != nil {
+			return errors.Errorf("resolving code action %q: %w", action.Title, err)
+		}
+		action = *resolved
+	}
 	for _, change := range action.Edit.DocumentChanges {
-		path := e.sandbox.Workdir.URIToPath(change.TextDocument.URI)
-		if int32(e.buffers[path].version) != change.TextDocument.Version {
+		if change.TextDocumentEdit == nil {
+			if err := e.applyDocumentChange(ctx, change); err != nil {
+				return err
+			}
+			continue
+		}
+		path := e.sandbox.Workdir.URIToPath(change.TextDocumentEdit.TextDocument.URI)
+		if int32(e.buffers[path].version) != change.TextDocumentEdit.TextDocument.Version {
 			// Skip edits for old versions.
 			continue
 		}
-		edits := convertEdits(change.Edits)
+		edits := convertEdits(change.TextDocumentEdit.Edits)
 		if err := e.EditBuffer(ctx, path, edits); err != nil {
 			return errors.Errorf("editing buffer %q: %w", path, err)
 		}
@@ -1024,9 +1060,143 @@ func (e *Editor) CodeLens(ctx context.Context, path string) ([]protocol.CodeLens
 	if err != nil {
 		return nil, err
 	}
+	// Resolve the commands of the code lenses, as they would be displayed.
+	for i := range lens {
+		if lens[i].Command.Command != "" {
+			continue
+		}
+		resolved, err := e.Server.ResolveCodeLens(ctx, &lens[i])
+		if err != nil {
+			return nil, errors.Errorf("resolving code lens at %v: %w", lens[i].Range, err)
+		}
+		lens[i] = *resolved
+	}
 	return lens, nil
 }
 
+// Diagnostic pulls the diagnostics for the buffer at path. Unchanged
+// reports are returned with their Kind set to "unchanged" and no items.
+func (e *Editor) Diagnostic(ctx context.Context, path, previousResultID string) (*protocol.FullDocumentDiagnosticReport, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	if e.serverCapabilities.DiagnosticProvider == nil {
+		return nil, errors.New("server does not support pull diagnostics")
+	}
+	params := &protocol.DocumentDiagnosticParams{
+		TextDocument:     e.textDocumentIdentifier(path),
+		PreviousResultID: previousResultID,
+	}
+	resp, err := e.Server.Diagnostic(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	report := &protocol.FullDocumentDiagnosticReport{}
+	if err := convertReport(resp, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// WorkspaceDiagnostic pulls the diagnostics for the entire workspace, given
+// the previous result IDs of the client, keyed by path.
+func (e *Editor) WorkspaceDiagnostic(ctx context.Context, previousResultIDs map[string]string) ([]protocol.WorkspaceFullDocumentDiagnosticReport, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	if e.serverCapabilities.DiagnosticProvider == nil {
+		return nil, errors.New("server does not support pull diagnostics")
+	}
+	params := &protocol.WorkspaceDiagnosticParams{
+		PreviousResultIds: []protocol.PreviousResultID{},
+	}
+	for path, id := range previousResultIDs {
+		params.PreviousResultIds = append(params.PreviousResultIds, protocol.PreviousResultID{
+			URI:   e.sandbox.Workdir.URI(path),
+			Value: id,
+		})
+	}
+	resp, err := e.Server.DiagnosticWorkspace(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	var reports []protocol.WorkspaceFullDocumentDiagnosticReport
+	if err := convertReport(resp.Items, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// convertReport converts a diagnostic report, which is received as an
+// untyped value, into the given report type.
+func convertReport(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// DocumentColor returns the colors of the buffer at path.
+func (e *Editor) DocumentColor(ctx context.Context, path string) ([]protocol.ColorInformation, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	params := &protocol.DocumentColorParams{TextDocument: e.textDocumentIdentifier(path)}
+	return e.Server.DocumentColor(ctx, params)
+}
+
+// ColorPresentation returns the presentations of color for the color at rng
+// in the buffer at path.
+func (e *Editor) ColorPresentation(ctx context.Context, path string, rng protocol.Range, color protocol.Color) ([]protocol.ColorPresentation, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	params := &protocol.ColorPresentationParams{
+		TextDocument: e.textDocumentIdentifier(path),
+		Color:        color,
+		Range:        rng,
+	}
+	return e.Server.ColorPresentation(ctx, params)
+}
+
+// PrepareTypeHierarchy returns the type hierarchy items for the type at pos
+// in the buffer at path.
+func (e *Editor) PrepareTypeHierarchy(ctx context.Context, path string, pos Pos) ([]protocol.TypeHierarchyItem, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	params := &protocol.TypeHierarchyPrepareParams{}
+	params.TextDocument = e.textDocumentIdentifier(path)
+	params.Position = pos.ToProtocolPosition()
+	return e.Server.PrepareTypeHierarchy(ctx, params)
+}
+
+// Supertypes returns the supertypes of the type hierarchy item.
+func (e *Editor) Supertypes(ctx context.Context, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	return e.Server.Supertypes(ctx, &protocol.TypeHierarchySupertypesParams{Item: item})
+}
+
+// Subtypes returns the subtypes of the type hierarchy item.
+func (e *Editor) Subtypes(ctx context.Context, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	return e.Server.Subtypes(ctx, &protocol.TypeHierarchySubtypesParams{Item: item})
+}
+
+// InlayHint returns the inlay hints of the buffer at path.
+func (e *Editor) InlayHint(ctx context.Context, path string) ([]protocol.InlayHint, error) {
+	if e.Server == nil {
+		return nil, nil
+	}
+	params := &protocol.InlayHintParams{TextDocument: e.textDocumentIdentifier(path)}
+	return e.Server.InlayHint(ctx, params)
+}
+
 // Completion executes a completion request on the server.
 func (e *Editor) Completion(ctx context.Context, path string, pos Pos) (*protocol.CompletionList, error) {
 	if e.Server == nil {
@@ -1109,28 +1279,98 @@ func (e *Editor) Rename(ctx context.Context, path string, pos Pos, newName strin
 		return err
 	}
 	for _, change := range wsEdits.DocumentChanges {
-		if err := e.applyProtocolEdit(ctx, change); err != nil {
+		if err := e.applyDocumentChange(ctx, change); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// WillRenameFiles notifies the server that the file or directory at oldPath
+// is about to be renamed to newPath, and applies the resulting edits. It
+// does not rename anything in the workdir.
+func (e *Editor) WillRenameFiles(ctx context.Context, oldPath, newPath string) error {
+	if e.Server == nil {
+		return nil
+	}
+	params := &protocol.RenameFilesParams{
+		Files: []protocol.FileRename{{
+			OldURI: string(e.sandbox.Workdir.URI(oldPath)),
+			NewURI: string(e.sandbox.Workdir.URI(newPath)),
+		}},
+	}
+	wsEdits, err := e.Server.WillRenameFiles(ctx, params)
+	if err != nil {
+		return err
+	}
+	if wsEdits == nil {
+		return nil
+	}
+	for _, change := range wsEdits.DocumentChanges {
+		if err := e.applyDocumentChange(ctx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameFiles renames the file or directory at oldPath to newPath in the
+// workdir, and notifies the server with didRenameFiles. Open buffers are not
+// renamed.
+func (e *Editor) RenameFiles(ctx context.Context, oldPath, newPath string) error {
+	if err := e.sandbox.Workdir.RenameFile(ctx, oldPath, newPath); err != nil {
+		return err
+	}
+	if e.Server == nil {
+		return nil
+	}
+	params := &protocol.RenameFilesParams{
+		Files: []protocol.FileRename{{
+			OldURI: string(e.sandbox.Workdir.URI(oldPath)),
+			NewURI: string(e.sandbox.Workdir.URI(newPath)),
+		}},
+	}
+	if err := e.Server.DidRenameFiles(ctx, params); err != nil {
+		return errors.Errorf("DidRenameFiles: %w", err)
+	}
+	return nil
+}
+
+// applyDocumentChange applies a document change of a workspace edit: it
+// either creates, renames or deletes a file in the workdir, or edits a
+// buffer, opening it if needed.
+func (e *Editor) applyDocumentChange(ctx context.Context, change protocol.DocumentChanges) error {
+	switch {
+	case change.CreateFile != nil:
+		return e.createFile(ctx, *change.CreateFile)
+	case change.RenameFile != nil:
+		w := e.sandbox.Workdir
+		return e.RenameFiles(ctx, w.URIToPath(change.RenameFile.OldURI), w.URIToPath(change.RenameFile.NewURI))
+	case change.DeleteFile != nil:
+		return e.sandbox.Workdir.RemoveFile(ctx, e.sandbox.Workdir.URIToPath(change.DeleteFile.URI))
+	}
+	return e.applyProtocolEdit(ctx, *change.TextDocumentEdit)
+}
+
+// createFile creates the empty file of a create operation in the workdir.
+func (e *Editor) createFile(ctx context.Context, op protocol.CreateFile) error {
+	path := e.sandbox.Workdir.URIToPath(op.URI)
+	if _, err := e.sandbox.Workdir.ReadFile(path); err == nil && !op.Options.Overwrite {
+		if op.Options.IgnoreIfExists {
+			return nil
+		}
+		return fmt.Errorf("creating %q: file already exists", path)
+	}
+	return e.sandbox.Workdir.WriteFile(ctx, path, "")
+}
+
 func (e *Editor) applyProtocolEdit(ctx context.Context, change protocol.TextDocumentEdit) error {
 	path := e.sandbox.Workdir.URIToPath(change.TextDocument.URI)
 	if ver := int32(e.BufferVersion(path)); ver != change.TextDocument.Version {
 		return fmt.Errorf("buffer versions for %q do not match: have %d, editing %d", path, ver, change.TextDocument.Version)
 	}
 	if !e.HasBuffer(path) {
-		err := e.OpenFile(ctx, path)
-		if os.IsNotExist(err) {
-			// TODO: it's unclear if this is correct. Here we create the buffer (with
-			// version 1), then apply edits. Perhaps we should apply the edits before
-			// sending the didOpen notification.
-			e.CreateBuffer(ctx, path, "")
-			err = nil
-		}
-		if err != nil {
+		if err := e.OpenFile(ctx, path); err != nil {
 			return err
 		}
 	}
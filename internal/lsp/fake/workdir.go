@@ -205,6 +205,27 @@ func (w *Workdir) RemoveFile(ctx context.Context, path string) error {
 	return nil
 }
 
+// RenameFile renames the workdir-relative file or directory oldPath to
+// newPath. It does not send file events, as clients notify the server of
+// renames with didRenameFiles rather than through file watching.
+func (w *Workdir) RenameFile(ctx context.Context, oldPath, newPath string) error {
+	newAbs := w.AbsPath(newPath)
+	if err := os.MkdirAll(filepath.Dir(newAbs), 0755); err != nil {
+		return errors.Errorf("creating nested directory: %w", err)
+	}
+	if err := os.Rename(w.AbsPath(oldPath), newAbs); err != nil {
+		return errors.Errorf("renaming %q to %q: %w", oldPath, newPath, err)
+	}
+	w.fileMu.Lock()
+	defer w.fileMu.Unlock()
+	files, err := w.listFiles(".")
+	if err != nil {
+		return err
+	}
+	w.files = files
+	return nil
+}
+
 func (w *Workdir) sendEvents(ctx context.Context, evts []FileEvent) {
 	if len(evts) == 0 {
 		return
@@ -47,6 +47,11 @@ func (s *Server) initialize(ctx context.Context, params *protocol.ParamInitializ
 		s.tempDir = ""
 	}
 	s.progress.SetSupportsWorkDoneProgress(params.Capabilities.Window.WorkDoneProgress)
+	if params.Trace != "" {
+		if err := updateTrace(ctx, params.Trace); err != nil {
+			event.Error(ctx, "setting initial trace", err)
+		}
+	}
 
 	options := s.session.Options()
 	defer func() { s.session.SetOptions(options) }()
@@ -86,6 +91,7 @@ func (s *Server) initialize(ctx context.Context, params *protocol.ParamInitializ
 		// Using CodeActionOptions is only valid if codeActionLiteralSupport is set.
 		codeActionProvider = &protocol.CodeActionOptions{
 			CodeActionKinds: s.getSupportedCodeActions(),
+			ResolveProvider: options.CodeActionResolveSupported,
 		}
 	}
 	var renameOpts interface{} = true
@@ -120,25 +126,43 @@ See https://github.com/golang/go/issues/45732 for more information.`,
 	return &protocol.InitializeResult{
 		Capabilities: protocol.ServerCapabilities{
 			CallHierarchyProvider: true,
+			TypeHierarchyProvider: true,
+			InlayHintProvider:     true,
 			CodeActionProvider:    codeActionProvider,
+			CodeLensProvider: protocol.CodeLensOptions{
+				ResolveProvider: true,
+			},
 			CompletionProvider: protocol.CompletionOptions{
 				TriggerCharacters: []string{"."},
 			},
-			DefinitionProvider:         true,
-			TypeDefinitionProvider:     true,
-			ImplementationProvider:     true,
-			DocumentFormattingProvider: true,
-			DocumentSymbolProvider:     true,
-			WorkspaceSymbolProvider:    true,
+			DiagnosticProvider: protocol.DiagnosticOptions{
+				InterFileDependencies: true,
+				WorkspaceDiagnostics:  true,
+			},
+			DefinitionProvider:              true,
+			TypeDefinitionProvider:          true,
+			ImplementationProvider:          true,
+			DocumentFormattingProvider:      true,
+			DocumentRangeFormattingProvider: true,
+			DocumentOnTypeFormattingProvider: protocol.DocumentOnTypeFormattingOptions{
+				FirstTriggerCharacter: "}",
+				MoreTriggerCharacter:  []string{"\n"},
+			},
+			DocumentSymbolProvider:  true,
+			WorkspaceSymbolProvider: true,
 			ExecuteCommandProvider: protocol.ExecuteCommandOptions{
 				Commands: options.SupportedCommands,
 			},
-			FoldingRangeProvider:      true,
-			HoverProvider:             true,
-			DocumentHighlightProvider: true,
-			DocumentLinkProvider:      protocol.DocumentLinkOptions{},
-			ReferencesProvider:        true,
-			RenameProvider:            renameOpts,
+			ColorProvider:              true,
+			FoldingRangeProvider:       true,
+			HoverProvider:              true,
+			DocumentHighlightProvider:  true,
+			DocumentLinkProvider:       protocol.DocumentLinkOptions{},
+			LinkedEditingRangeProvider: true,
+			MonikerProvider:            true,
+			ReferencesProvider:         true,
+			RenameProvider:             renameOpts,
+			SelectionRangeProvider:     true,
 			SignatureHelpProvider: protocol.SignatureHelpOptions{
 				TriggerCharacters: []string{"(", ","},
 			},
@@ -188,20 +212,26 @@ func (s *Server) initialized(ctx context.Context, params *protocol.InitializedPa
 	}
 	s.pendingFolders = nil
 
+	var registrations []protocol.Registration
 	if options.ConfigurationSupported && options.DynamicConfigurationSupported {
-		registrations := []protocol.Registration{
-			{
+		registrations = append(registrations,
+			protocol.Registration{
 				ID:     "workspace/didChangeConfiguration",
 				Method: "workspace/didChangeConfiguration",
 			},
-			{
+			protocol.Registration{
 				ID:     "workspace/didChangeWorkspaceFolders",
 				Method: "workspace/didChangeWorkspaceFolders",
 			},
-		}
+		)
 		if options.SemanticTokens {
 			registrations = append(registrations, semanticTokenRegistration(options.SemanticTypes, options.SemanticMods))
 		}
+	}
+	if options.DynamicFileOperationsSupported {
+		registrations = append(registrations, fileOperationRegistrations()...)
+	}
+	if len(registrations) > 0 {
 		if err := s.client.RegisterCapability(ctx, &protocol.RegistrationParams{
 			Registrations: registrations,
 		}); err != nil {
@@ -211,6 +241,33 @@ func (s *Server) initialized(ctx context.Context, params *protocol.InitializedPa
 	return nil
 }
 
+// fileOperationRegistrations returns the registrations for the file
+// operations that gopls is interested in: renames of directories, so that
+// import paths can be kept up to date.
+func fileOperationRegistrations() []protocol.Registration {
+	opts := protocol.FileOperationRegistrationOptions{
+		Filters: []protocol.FileOperationFilter{{
+			Scheme: "file",
+			Pattern: protocol.FileOperationPattern{
+				Glob:    "**",
+				Matches: protocol.FolderOp,
+			},
+		}},
+	}
+	return []protocol.Registration{
+		{
+			ID:              "workspace/willRenameFiles",
+			Method:          "workspace/willRenameFiles",
+			RegisterOptions: opts,
+		},
+		{
+			ID:              "workspace/didRenameFiles",
+			Method:          "workspace/didRenameFiles",
+			RegisterOptions: opts,
+		},
+	}
+}
+
 func (s *Server) addFolders(ctx context.Context, folders []protocol.WorkspaceFolder) error {
 	originalViews := len(s.session.Views())
 	viewErrors := make(map[span.URI]error)
@@ -0,0 +1,32 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/lsp/source"
+	"github.com/kent0106/gotools/internal/lsp/template"
+)
+
+func (s *Server) linkedEditingRange(ctx context.Context, params *protocol.LinkedEditingRangeParams) (*protocol.LinkedEditingRanges, error) {
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.UnknownKind)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+	var ranges []protocol.Range
+	switch fh.Kind() {
+	case source.Go:
+		ranges, err = source.LinkedEditingRange(ctx, snapshot, fh, params.Position)
+	case source.Tmpl:
+		ranges, err = template.LinkedEditingRange(ctx, snapshot, fh, params.Position)
+	}
+	if err != nil || len(ranges) == 0 {
+		return nil, err
+	}
+	return &protocol.LinkedEditingRanges{Ranges: ranges}, nil
+}
@@ -198,6 +198,16 @@ func (r *runner) CodeLens(t *testing.T, uri span.URI, want []protocol.CodeLens)
 	if err != nil {
 		t.Fatal(err)
 	}
+	for i := range got {
+		if got[i].Command.Command != "" || got[i].Data == nil {
+			t.Errorf("got code lens %+v, want a code lens to resolve", got[i])
+		}
+		resolved, err := r.server.resolveCodeLens(r.ctx, &got[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[i] = *resolved
+	}
 	if diff := tests.DiffCodeLens(uri, want, got); diff != "" {
 		t.Errorf("%s: %s", uri, diff)
 	}
@@ -269,6 +279,44 @@ func (r *runner) FoldingRanges(t *testing.T, spn span.Span) {
 	view.SetOptions(r.ctx, original)
 }
 
+func (r *runner) SelectionRanges(t *testing.T, spn span.Span) {
+	uri := spn.URI()
+	m, err := r.data.Mapper(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := m.Location(spn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := r.server.SelectionRange(r.ctx, &protocol.SelectionRangeParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.URIFromSpanURI(uri),
+		},
+		Positions: []protocol.Position{loc.Range.Start},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 selection range, got %d", len(result))
+	}
+	var rngs []protocol.Range
+	for sel := &result[0]; sel != nil; sel = sel.Parent {
+		rngs = append(rngs, sel.Range)
+	}
+	got, err := tests.SelectionRangesString(m, rngs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := string(r.data.Golden("selectionrange_"+tests.SpanName(spn), uri.Filename(), func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+	if want != got {
+		t.Errorf("selectionRange failed for %s:\n%s", spn, tests.Diff(t, want, got))
+	}
+}
+
 func (r *runner) foldingRanges(t *testing.T, prefix string, uri span.URI, ranges []protocol.FoldingRange) {
 	m, err := r.data.Mapper(uri)
 	if err != nil {
@@ -415,6 +463,75 @@ func (r *runner) Format(t *testing.T, spn span.Span) {
 	}
 }
 
+func (r *runner) RangeFormat(t *testing.T, start span.Span, end span.Span) {
+	uri := start.URI()
+	m, err := r.data.Mapper(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spn := span.New(uri, start.Start(), end.End())
+	rng, err := m.Range(spn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	edits, err := r.server.RangeFormatting(r.ctx, &protocol.DocumentRangeFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.URIFromSpanURI(uri),
+		},
+		Range: rng,
+	})
+	if err != nil {
+		got = err.Error()
+	} else {
+		sedits, err := source.FromProtocolEdits(m, edits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = diff.ApplyEdits(string(m.Content), sedits)
+	}
+	want := string(r.data.Golden("rangeformat_"+tests.SpanName(start), uri.Filename(), func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+	if want != got {
+		t.Errorf("range format failed for %s:\n%s", spn, tests.Diff(t, want, got))
+	}
+}
+
+func (r *runner) OnTypeFormat(t *testing.T, spn span.Span, ch string) {
+	uri := spn.URI()
+	m, err := r.data.Mapper(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The request is sent with the cursor just after the typed character.
+	pos, err := m.Position(spn.End())
+	if err != nil {
+		t.Fatal(err)
+	}
+	edits, err := r.server.OnTypeFormatting(r.ctx, &protocol.DocumentOnTypeFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.URIFromSpanURI(uri),
+		},
+		Position: pos,
+		Ch:       ch,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sedits, err := source.FromProtocolEdits(m, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := diff.ApplyEdits(string(m.Content), sedits)
+	want := string(r.data.Golden("ontypeformat_"+tests.SpanName(spn), uri.Filename(), func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+	if want != got {
+		t.Errorf("on type format failed for %s:\n%s", spn, tests.Diff(t, want, got))
+	}
+}
+
 func (r *runner) SemanticTokens(t *testing.T, spn span.Span) {
 	uri := spn.URI()
 	filename := uri.Filename()
@@ -676,6 +793,43 @@ func (r *runner) MethodExtraction(t *testing.T, start span.Span, end span.Span)
 	}
 }
 
+func (r *runner) StructTags(t *testing.T, spn span.Span, tag tests.StructTag) {
+	uri := spn.URI()
+	m, err := r.data.Mapper(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng, err := m.Range(spn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := command.NewModifyTagsCommand("", command.ModifyTagsArgs{
+		URI:   protocol.URIFromSpanURI(uri),
+		Range: rng,
+		Add:   strings.Split(tag.Add, ","),
+		Case:  tag.Case,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.server.ExecuteCommand(r.ctx, &protocol.ExecuteCommandParams{
+		Command:   cmd.Command,
+		Arguments: cmd.Arguments,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := <-r.editRecv
+	for u, got := range res {
+		want := string(r.data.Golden("tags_"+tests.SpanName(spn), u.Filename(), func() ([]byte, error) {
+			return []byte(got), nil
+		}))
+		if want != got {
+			t.Errorf("tags failed for %s:\n%s", u.Filename(), tests.Diff(t, want, got))
+		}
+	}
+}
+
 func (r *runner) Definition(t *testing.T, spn span.Span, d tests.Definition) {
 	sm, err := r.data.Mapper(d.Src.URI())
 	if err != nil {
@@ -840,6 +994,39 @@ func (r *runner) Highlight(t *testing.T, src span.Span, locations []span.Span) {
 	}
 }
 
+func (r *runner) LinkedEditingRange(t *testing.T, src span.Span, want []span.Span) {
+	m, err := r.data.Mapper(src.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := m.Location(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := r.server.LinkedEditingRange(r.ctx, &protocol.LinkedEditingRangeParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: loc.URI},
+			Position:     loc.Range.Start,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []span.Span
+	if result != nil {
+		for _, rng := range result.Ranges {
+			spn, err := m.RangeSpan(rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, spn)
+		}
+	}
+	if diff := tests.DiffSpans(want, got); diff != "" {
+		t.Errorf("linked editing ranges at %v failed:\n%s", src, diff)
+	}
+}
+
 func (r *runner) Hover(t *testing.T, src span.Span, text string) {
 	m, err := r.data.Mapper(src.URI())
 	if err != nil {
@@ -1030,9 +1217,17 @@ func (r *runner) PrepareRename(t *testing.T, src span.Span, want *source.Prepare
 	}
 }
 
-func applyTextDocumentEdits(r *runner, edits []protocol.TextDocumentEdit) (map[span.URI]string, error) {
+func applyTextDocumentEdits(r *runner, changes []protocol.DocumentChanges) (map[span.URI]string, error) {
 	res := map[span.URI]string{}
-	for _, docEdits := range edits {
+	for _, change := range changes {
+		if change.CreateFile != nil {
+			res[change.CreateFile.URI.SpanURI()] = ""
+			continue
+		}
+		if change.TextDocumentEdit == nil {
+			return nil, fmt.Errorf("unexpected renaming or deletion of a file")
+		}
+		docEdits := change.TextDocumentEdit
 		uri := docEdits.TextDocument.URI.SpanURI()
 		var m *protocol.ColumnMapper
 		// If we have already edited this file, we use the edited version (rather than the
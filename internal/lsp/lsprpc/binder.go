@@ -44,10 +44,12 @@ func (b *ServerBinder) Bind(ctx context.Context, conn *jsonrpc2_v2.Connection) (
 	client := protocol.ClientDispatcherV2(conn)
 	server := b.newServer(ctx, client)
 	serverHandler := protocol.ServerHandlerV2(server)
-	// Wrap the server handler to inject the client into each request context, so
-	// that log events are reflected back to the client.
+	tracer := &protocol.Tracer{}
+	// Wrap the server handler to inject the client and its trace setting into
+	// each request context, so that log events are reflected back to the client.
 	wrapped := jsonrpc2_v2.HandlerFunc(func(ctx context.Context, req *jsonrpc2_v2.Request) (interface{}, error) {
 		ctx = protocol.WithClient(ctx, client)
+		ctx = protocol.WithTracer(ctx, tracer)
 		return serverHandler.Handle(ctx, req)
 	})
 	preempter := &canceler{
@@ -88,6 +88,7 @@ func (s *StreamServer) ServeStream(ctx context.Context, conn jsonrpc2.Conn) erro
 		executable = ""
 	}
 	ctx = protocol.WithClient(ctx, client)
+	ctx = protocol.WithTracer(ctx, &protocol.Tracer{})
 	conn.Go(ctx,
 		protocol.Handlers(
 			handshaker(session, executable, s.daemon,
@@ -17,6 +17,7 @@ import (
 	"github.com/kent0106/gotools/internal/jsonrpc2/servertest"
 	"github.com/kent0106/gotools/internal/lsp/cache"
 	"github.com/kent0106/gotools/internal/lsp/debug"
+	"github.com/kent0106/gotools/internal/lsp/debug/log"
 	"github.com/kent0106/gotools/internal/lsp/fake"
 	"github.com/kent0106/gotools/internal/lsp/protocol"
 	"github.com/kent0106/gotools/internal/testenv"
@@ -33,6 +34,11 @@ func (c FakeClient) LogMessage(ctx context.Context, params *protocol.LogMessageP
 	return nil
 }
 
+func (c FakeClient) LogTrace(ctx context.Context, params *protocol.LogTraceParams) error {
+	c.Logs <- "trace: " + params.Message
+	return nil
+}
+
 // fakeServer is intended to be embedded in the test fakes below, to trivially
 // implement Shutdown.
 type fakeServer struct {
@@ -84,6 +90,54 @@ func TestClientLogging(t *testing.T) {
 	}
 }
 
+type TracingServer struct{ fakeServer }
+
+func (s TracingServer) SetTrace(ctx context.Context, params *protocol.SetTraceParams) error {
+	protocol.GetTracer(ctx).Set(params.Value)
+	return nil
+}
+
+func (s TracingServer) DidOpen(ctx context.Context, params *protocol.DidOpenTextDocumentParams) error {
+	log.Trace.Log(ctx, "ping")
+	return nil
+}
+
+func TestClientTracing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := FakeClient{Logs: make(chan string, 10)}
+
+	ctx = debug.WithInstance(ctx, "", "")
+	ss := NewStreamServer(cache.New(nil), false)
+	ss.serverForTest = TracingServer{}
+	ts := servertest.NewPipeServer(ctx, ss, nil)
+	defer checkClose(t, ts.Close)
+	cc := ts.Connect(ctx)
+	cc.Go(ctx, protocol.ClientHandler(client, jsonrpc2.MethodNotFound))
+	server := protocol.ServerDispatcher(cc)
+
+	// Trace logs are not sent to the client until it enables tracing.
+	if err := server.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{}); err != nil {
+		t.Errorf("DidOpen: %v", err)
+	}
+	if err := server.SetTrace(ctx, &protocol.SetTraceParams{Value: "messages"}); err != nil {
+		t.Errorf("SetTrace: %v", err)
+	}
+	if err := server.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{}); err != nil {
+		t.Errorf("DidOpen: %v", err)
+	}
+
+	select {
+	case got := <-client.Logs:
+		if want := "trace: ping"; got != want {
+			t.Errorf("got log %q, want %q", got, want)
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("timeout waiting for client trace")
+	}
+}
+
 // WaitableServer instruments LSP request so that we can control their timing.
 // The requests chosen are arbitrary: we simply needed one that blocks, and
 // another that doesn't.
@@ -6,6 +6,7 @@ package protocol
 
 // Custom code actions that aren't explicitly stated in LSP
 const (
-	GoTest CodeActionKind = "goTest"
+	GoTest    CodeActionKind = "goTest"
+	GoAddTest CodeActionKind = "source.addTest"
 	// TODO: Add GoGenerate, RegenerateCgo etc.
 )
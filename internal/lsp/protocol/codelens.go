@@ -0,0 +1,20 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import "encoding/json"
+
+// MarshalJSON omits the command of a code lens that has none, so that the
+// client resolves it.
+func (l CodeLens) MarshalJSON() ([]byte, error) {
+	type codeLens CodeLens // without the MarshalJSON method
+	if l.Command.Title != "" || l.Command.Command != "" {
+		return json.Marshal(codeLens(l))
+	}
+	return json.Marshal(struct {
+		Range Range       `json:"range"`
+		Data  interface{} `json:"data,omitempty"`
+	}{l.Range, l.Data})
+}
@@ -7,10 +7,12 @@ package protocol
 import (
 	"bytes"
 	"context"
+	"sync"
 
 	"github.com/kent0106/gotools/internal/event"
 	"github.com/kent0106/gotools/internal/event/core"
 	"github.com/kent0106/gotools/internal/event/export"
+	"github.com/kent0106/gotools/internal/event/keys"
 	"github.com/kent0106/gotools/internal/event/label"
 	"github.com/kent0106/gotools/internal/xcontext"
 )
@@ -19,6 +21,7 @@ type contextKey int
 
 const (
 	clientKey = contextKey(iota)
+	tracerKey
 )
 
 func WithClient(ctx context.Context, client Client) context.Context {
@@ -41,3 +44,70 @@ func LogEvent(ctx context.Context, ev core.Event, lm label.Map, mt MessageType)
 	go client.LogMessage(xcontext.Detach(ctx), msg)
 	return ctx
 }
+
+// A Tracer holds the trace setting of a client, which determines the log
+// events sent to the client with $/logTrace notifications.
+type Tracer struct {
+	mu    sync.Mutex
+	value TraceValues
+}
+
+// Value returns the current trace setting, "off" by default.
+func (t *Tracer) Value() TraceValues {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.value == "" {
+		return "off"
+	}
+	return t.value
+}
+
+// Set updates the trace setting.
+func (t *Tracer) Set(value TraceValues) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.value = value
+}
+
+// WithTracer returns a context that carries the trace setting of the client
+// carried by ctx.
+func WithTracer(ctx context.Context, tracer *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey, tracer)
+}
+
+// GetTracer returns the tracer carried by ctx, or nil if there is none.
+func GetTracer(ctx context.Context) *Tracer {
+	tracer, _ := ctx.Value(tracerKey).(*Tracer)
+	return tracer
+}
+
+// LogTraceEvent sends a log event to the client carried by ctx as a
+// $/logTrace notification, according to the trace setting carried by ctx.
+// With the "messages" setting, only the message of the event is sent. With
+// the "verbose" setting, the event is also sent along with all its labels.
+func LogTraceEvent(ctx context.Context, ev core.Event, lm label.Map) context.Context {
+	client, ok := ctx.Value(clientKey).(Client)
+	if !ok {
+		return ctx
+	}
+	tracer := GetTracer(ctx)
+	if tracer == nil {
+		return ctx
+	}
+	value := tracer.Value()
+	if value != "messages" && value != "verbose" {
+		return ctx
+	}
+	msg := &LogTraceParams{Message: keys.Msg.Get(ev)}
+	if err := keys.Err.Get(ev); err != nil && msg.Message == "" {
+		msg.Message = err.Error()
+	}
+	if value == "verbose" {
+		buf := &bytes.Buffer{}
+		p := export.Printer{}
+		p.WriteEvent(buf, ev, lm)
+		msg.Verbose = buf.String()
+	}
+	go client.LogTrace(xcontext.Detach(ctx), msg)
+	return ctx
+}
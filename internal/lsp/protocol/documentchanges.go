@@ -0,0 +1,66 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DocumentChanges is an element of the documentChanges of a WorkspaceEdit:
+// either an edit of a text document or the creation, renaming or deletion of
+// a file. Exactly one of its fields is set.
+type DocumentChanges struct {
+	TextDocumentEdit *TextDocumentEdit
+	CreateFile       *CreateFile
+	RenameFile       *RenameFile
+	DeleteFile       *DeleteFile
+}
+
+// TextDocumentChanges returns the document changes applying the given edits
+// of text documents.
+func TextDocumentChanges(edits []TextDocumentEdit) []DocumentChanges {
+	var changes []DocumentChanges
+	for i := range edits {
+		changes = append(changes, DocumentChanges{TextDocumentEdit: &edits[i]})
+	}
+	return changes
+}
+
+func (d DocumentChanges) MarshalJSON() ([]byte, error) {
+	switch {
+	case d.CreateFile != nil:
+		return json.Marshal(d.CreateFile)
+	case d.RenameFile != nil:
+		return json.Marshal(d.RenameFile)
+	case d.DeleteFile != nil:
+		return json.Marshal(d.DeleteFile)
+	}
+	return json.Marshal(d.TextDocumentEdit)
+}
+
+func (d *DocumentChanges) UnmarshalJSON(data []byte) error {
+	var op struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &op); err != nil {
+		return err
+	}
+	switch op.Kind {
+	case "":
+		d.TextDocumentEdit = new(TextDocumentEdit)
+		return json.Unmarshal(data, d.TextDocumentEdit)
+	case string(Create):
+		d.CreateFile = new(CreateFile)
+		return json.Unmarshal(data, d.CreateFile)
+	case string(Rename):
+		d.RenameFile = new(RenameFile)
+		return json.Unmarshal(data, d.RenameFile)
+	case string(Delete):
+		d.DeleteFile = new(DeleteFile)
+		return json.Unmarshal(data, d.DeleteFile)
+	}
+	return fmt.Errorf("unsupported document change of kind %q", op.Kind)
+}
@@ -25,6 +25,7 @@ type Client interface {
 	Event(context.Context, *interface{}) error
 	PublishDiagnostics(context.Context, *PublishDiagnosticsParams) error
 	Progress(context.Context, *ProgressParams) error
+	LogTrace(context.Context, *LogTraceParams) error
 	WorkspaceFolders(context.Context) ([]WorkspaceFolder /*WorkspaceFolder[] | null*/, error)
 	Configuration(context.Context, *ParamConfiguration) ([]interface{}, error)
 	WorkDoneProgressCreate(context.Context, *WorkDoneProgressCreateParams) error
@@ -72,6 +73,13 @@ func clientDispatch(ctx context.Context, client Client, reply jsonrpc2.Replier,
 		}
 		err := client.Progress(ctx, &params)
 		return true, reply(ctx, nil, err)
+	case "$/logTrace": // notif
+		var params LogTraceParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		err := client.LogTrace(ctx, &params)
+		return true, reply(ctx, nil, err)
 	case "workspace/workspaceFolders": // req
 		if len(r.Params()) > 0 {
 			return true, reply(ctx, nil, errors.Errorf("%w: expected no params", jsonrpc2.ErrInvalidParams))
@@ -152,6 +160,10 @@ func (s *clientDispatcher) PublishDiagnostics(ctx context.Context, params *Publi
 func (s *clientDispatcher) Progress(ctx context.Context, params *ProgressParams) error {
 	return s.sender.Notify(ctx, "$/progress", params)
 }
+
+func (s *clientDispatcher) LogTrace(ctx context.Context, params *LogTraceParams) error {
+	return s.sender.Notify(ctx, "$/logTrace", params)
+}
 func (s *clientDispatcher) WorkspaceFolders(ctx context.Context) ([]WorkspaceFolder /*WorkspaceFolder[] | null*/, error) {
 	var result []WorkspaceFolder /*WorkspaceFolder[] | null*/
 	if err := s.sender.Call(ctx, "workspace/workspaceFolders", nil, &result); err != nil {
@@ -1355,6 +1355,42 @@ type Diagnostic struct {
 	Data interface{} `json:"data,omitempty"`
 }
 
+/**
+ * Diagnostic options.
+ *
+ * @since 3.17.0 - proposed state
+ */
+type DiagnosticOptions struct {
+	/**
+	 * An optional identifier under which the diagnostics are
+	 * managed by the client.
+	 */
+	Identifier string `json:"identifier,omitempty"`
+	/**
+	 * Whether the language has inter file dependencies meaning that
+	 * editing code in one file can result in a different diagnostic
+	 * set in another file. Inter file dependencies are common for
+	 * most programming languages and typically uncommon for linters.
+	 */
+	InterFileDependencies bool `json:"interFileDependencies"`
+	/**
+	 * The server provides support for workspace diagnostics as well.
+	 */
+	WorkspaceDiagnostics bool `json:"workspaceDiagnostics"`
+	WorkDoneProgressOptions
+}
+
+/**
+ * Diagnostic registration options.
+ *
+ * @since 3.17.0 - proposed state
+ */
+type DiagnosticRegistrationOptions struct {
+	TextDocumentRegistrationOptions
+	DiagnosticOptions
+	StaticRegistrationOptions
+}
+
 /**
  * Represents a related message and source code location for a diagnostic. This should be
  * used to point to code locations that cause or related to a diagnostics, e.g when duplicating
@@ -2567,6 +2603,148 @@ type InitializeResult struct {
 type InitializedParams struct {
 }
 
+/**
+ * Inlay hint information.
+ *
+ * @since 3.17.0
+ */
+type InlayHint struct {
+	/**
+	 * The position of this hint.
+	 */
+	Position Position `json:"position"`
+	/**
+	 * The label of this hint. A human readable string or an array of
+	 * InlayHintLabelPart label parts.
+	 *
+	 * *Note* that neither the string nor the label part can be empty.
+	 */
+	Label []InlayHintLabelPart/*string | InlayHintLabelPart[]*/ `json:"label"`
+	/**
+	 * The kind of this hint. Can be omitted in which case the client
+	 * should fall back to a reasonable default.
+	 */
+	Kind InlayHintKind `json:"kind,omitempty"`
+	/**
+	 * Optional text edits that are performed when accepting this inlay hint.
+	 */
+	TextEdits []TextEdit `json:"textEdits,omitempty"`
+	/**
+	 * The tooltip text when you hover over this item.
+	 */
+	Tooltip string/*string | MarkupContent*/ `json:"tooltip,omitempty"`
+	/**
+	 * Render padding before the hint.
+	 */
+	PaddingLeft bool `json:"paddingLeft,omitempty"`
+	/**
+	 * Render padding after the hint.
+	 */
+	PaddingRight bool `json:"paddingRight,omitempty"`
+	/**
+	 * A data entry field that is preserved on an inlay hint between
+	 * a `textDocument/inlayHint` and a `inlayHint/resolve` request.
+	 */
+	Data interface{} `json:"data,omitempty"`
+}
+
+/**
+ * Inlay hint client capabilities.
+ *
+ * @since 3.17.0
+ */
+type InlayHintClientCapabilities struct {
+	/**
+	 * Whether inlay hints support dynamic registration.
+	 */
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	/**
+	 * Indicates which properties a client can resolve lazily on a inlay
+	 * hint.
+	 */
+	ResolveSupport struct {
+		/**
+		 * The properties that a client can resolve lazily.
+		 */
+		Properties []string `json:"properties"`
+	} `json:"resolveSupport,omitempty"`
+}
+
+/**
+ * Inlay hint kinds.
+ *
+ * @since 3.17.0
+ */
+type InlayHintKind float64
+
+/**
+ * An inlay hint label part allows for interactive and composite labels
+ * of inlay hints.
+ *
+ * @since 3.17.0
+ */
+type InlayHintLabelPart struct {
+	/**
+	 * The value of this label part.
+	 */
+	Value string `json:"value"`
+	/**
+	 * The tooltip text when you hover over this label part.
+	 */
+	Tooltip string/*string | MarkupContent*/ `json:"tooltip,omitempty"`
+	/**
+	 * An optional source code location that represents this
+	 * label part.
+	 */
+	Location *Location `json:"location,omitempty"`
+	/**
+	 * An optional command for this label part.
+	 */
+	Command *Command `json:"command,omitempty"`
+}
+
+/**
+ * Inlay hint options used during static registration.
+ *
+ * @since 3.17.0
+ */
+type InlayHintOptions struct {
+	/**
+	 * The server provides support to resolve additional
+	 * information for an inlay hint item.
+	 */
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+	WorkDoneProgressOptions
+}
+
+/**
+ * A parameter literal used in inlay hint requests.
+ *
+ * @since 3.17.0
+ */
+type InlayHintParams struct {
+	/**
+	 * The text document.
+	 */
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	/**
+	 * The visible document range for which inlay hints should be computed.
+	 */
+	Range Range `json:"range"`
+	WorkDoneProgressParams
+}
+
+/**
+ * Inlay hint options used during static or dynamic registration.
+ *
+ * @since 3.17.0
+ */
+type InlayHintRegistrationOptions struct {
+	InlayHintOptions
+	TextDocumentRegistrationOptions
+	StaticRegistrationOptions
+}
+
 /**
  * A special text edit to provide an insert and a replace operation.
  *
@@ -3691,6 +3869,24 @@ type ServerCapabilities struct {
 	 * @since 3.16.0
 	 */
 	MonikerProvider interface{}/* bool | MonikerOptions | MonikerRegistrationOptions*/ `json:"monikerProvider,omitempty"`
+	/**
+	 * The server has support for pull model diagnostics.
+	 *
+	 * @since 3.17.0 - proposed state
+	 */
+	DiagnosticProvider interface{}/*DiagnosticOptions | DiagnosticRegistrationOptions*/ `json:"diagnosticProvider,omitempty"`
+	/**
+	 * The server provides type hierarchy support.
+	 *
+	 * @since 3.17.0
+	 */
+	TypeHierarchyProvider interface{}/* bool | TypeHierarchyOptions | TypeHierarchyRegistrationOptions*/ `json:"typeHierarchyProvider,omitempty"`
+	/**
+	 * The server provides inlay hints.
+	 *
+	 * @since 3.17.0
+	 */
+	InlayHintProvider interface{}/* bool | InlayHintOptions | InlayHintRegistrationOptions*/ `json:"inlayHintProvider,omitempty"`
 	/**
 	 * Experimental server capabilities.
 	 */
@@ -4174,6 +4370,18 @@ type TextDocumentClientCapabilities struct {
 	 * @since 3.16.0
 	 */
 	Moniker MonikerClientCapabilities `json:"moniker,omitempty"`
+	/**
+	 * Capabilities specific to the various type hierarchy requests.
+	 *
+	 * @since 3.17.0
+	 */
+	TypeHierarchy TypeHierarchyClientCapabilities `json:"typeHierarchy,omitempty"`
+	/**
+	 * Capabilities specific to the `textDocument/inlayHint` request.
+	 *
+	 * @since 3.17.0
+	 */
+	InlayHint InlayHintClientCapabilities `json:"inlayHint,omitempty"`
 }
 
 /**
@@ -4391,6 +4599,114 @@ type TypeDefinitionRegistrationOptions struct {
 	StaticRegistrationOptions
 }
 
+/**
+ * @since 3.17.0
+ */
+type TypeHierarchyClientCapabilities struct {
+	/**
+	 * Whether implementation supports dynamic registration. If this is set to `true`
+	 * the client supports the new `(TextDocumentRegistrationOptions & StaticRegistrationOptions)`
+	 * return value for the corresponding server capability as well.
+	 */
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
+
+/**
+ * @since 3.17.0
+ */
+type TypeHierarchyItem struct {
+	/**
+	 * The name of this item.
+	 */
+	Name string `json:"name"`
+	/**
+	 * The kind of this item.
+	 */
+	Kind SymbolKind `json:"kind"`
+	/**
+	 * Tags for this item.
+	 */
+	Tags []SymbolTag `json:"tags,omitempty"`
+	/**
+	 * More detail for this item, e.g. the signature of a function.
+	 */
+	Detail string `json:"detail,omitempty"`
+	/**
+	 * The resource identifier of this item.
+	 */
+	URI DocumentURI `json:"uri"`
+	/**
+	 * The range enclosing this symbol not including leading/trailing whitespace
+	 * but everything else, e.g. comments and code.
+	 */
+	Range Range `json:"range"`
+	/**
+	 * The range that should be selected and revealed when this symbol is being
+	 * picked, e.g. the name of a function. Must be contained by the
+	 * [`range`](#TypeHierarchyItem.range).
+	 */
+	SelectionRange Range `json:"selectionRange"`
+	/**
+	 * A data entry field that is preserved between a type hierarchy prepare and
+	 * supertypes or subtypes requests. It could also be used to identify the
+	 * type hierarchy in the server, helping improve the performance on
+	 * resolving supertypes and subtypes.
+	 */
+	Data interface{} `json:"data,omitempty"`
+}
+
+/**
+ * Type hierarchy options used during static registration.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchyOptions struct {
+	WorkDoneProgressOptions
+}
+
+/**
+ * The parameter of a `textDocument/prepareTypeHierarchy` request.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchyPrepareParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+}
+
+/**
+ * Type hierarchy options used during static or dynamic registration.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchyRegistrationOptions struct {
+	TextDocumentRegistrationOptions
+	TypeHierarchyOptions
+	StaticRegistrationOptions
+}
+
+/**
+ * The parameter of a `typeHierarchy/subtypes` request.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchySubtypesParams struct {
+	Item TypeHierarchyItem `json:"item"`
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+/**
+ * The parameter of a `typeHierarchy/supertypes` request.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchySupertypesParams struct {
+	Item TypeHierarchyItem `json:"item"`
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
 /**
  * A tagging type for string properties that are actually URIs
  *
@@ -4713,7 +5029,7 @@ type WorkspaceEdit struct {
 	 * If a client neither supports `documentChanges` nor `workspace.workspaceEdit.resourceOperations` then
 	 * only plain `TextEdit`s using the `changes` property are supported.
 	 */
-	DocumentChanges []TextDocumentEdit/*TextDocumentEdit | CreateFile | RenameFile | DeleteFile*/ `json:"documentChanges,omitempty"`
+	DocumentChanges []DocumentChanges/*TextDocumentEdit | CreateFile | RenameFile | DeleteFile*/ `json:"documentChanges,omitempty"`
 	/**
 	 * A map of change annotations that can be referenced in `AnnotatedTextEdit`s or create, rename and
 	 * delete file / folder operations.
@@ -5154,6 +5470,16 @@ const (
 	 */
 
 	UnknownProtocolVersion InitializeError = 1
+	/**
+	 * An inlay hint that is for a type annotation.
+	 */
+
+	Type InlayHintKind = 1
+	/**
+	 * An inlay hint that is for a parameter.
+	 */
+
+	Parameter InlayHintKind = 2
 	/**
 	 * The primary text to be inserted is treated as a plain string.
 	 */
@@ -35,7 +35,6 @@ type Server interface {
 	WillSave(context.Context, *WillSaveTextDocumentParams) error
 	DidChangeWatchedFiles(context.Context, *DidChangeWatchedFilesParams) error
 	SetTrace(context.Context, *SetTraceParams) error
-	LogTrace(context.Context, *LogTraceParams) error
 	Implementation(context.Context, *ImplementationParams) (Definition /*Definition | DefinitionLink[] | null*/, error)
 	TypeDefinition(context.Context, *TypeDefinitionParams) (Definition /*Definition | DefinitionLink[] | null*/, error)
 	DocumentColor(context.Context, *DocumentColorParams) ([]ColorInformation, error)
@@ -80,9 +79,13 @@ type Server interface {
 	Rename(context.Context, *RenameParams) (*WorkspaceEdit /*WorkspaceEdit | null*/, error)
 	PrepareRename(context.Context, *PrepareRenameParams) (*Range /*Range | { range: Range, placeholder: string } | { defaultBehavior: boolean } | null*/, error)
 	ExecuteCommand(context.Context, *ExecuteCommandParams) (interface{} /*any | null*/, error)
-	Diagnostic(context.Context, *string) (*string, error)
+	Diagnostic(context.Context, *DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
 	DiagnosticWorkspace(context.Context, *WorkspaceDiagnosticParams) (*WorkspaceDiagnosticReport, error)
 	DiagnosticRefresh(context.Context) error
+	PrepareTypeHierarchy(context.Context, *TypeHierarchyPrepareParams) ([]TypeHierarchyItem /*TypeHierarchyItem[] | null*/, error)
+	Supertypes(context.Context, *TypeHierarchySupertypesParams) ([]TypeHierarchyItem /*TypeHierarchyItem[] | null*/, error)
+	Subtypes(context.Context, *TypeHierarchySubtypesParams) ([]TypeHierarchyItem /*TypeHierarchyItem[] | null*/, error)
+	InlayHint(context.Context, *InlayHintParams) ([]InlayHint /*InlayHint[] | null*/, error)
 	NonstandardRequest(ctx context.Context, method string, params interface{}) (interface{}, error)
 }
 
@@ -189,13 +192,6 @@ func serverDispatch(ctx context.Context, server Server, reply jsonrpc2.Replier,
 		}
 		err := server.SetTrace(ctx, &params)
 		return true, reply(ctx, nil, err)
-	case "$/logTrace": // notif
-		var params LogTraceParams
-		if err := json.Unmarshal(r.Params(), &params); err != nil {
-			return true, sendParseError(ctx, reply, err)
-		}
-		err := server.LogTrace(ctx, &params)
-		return true, reply(ctx, nil, err)
 	case "textDocument/implementation": // req
 		var params ImplementationParams
 		if err := json.Unmarshal(r.Params(), &params); err != nil {
@@ -504,7 +500,7 @@ func serverDispatch(ctx context.Context, server Server, reply jsonrpc2.Replier,
 		resp, err := server.ExecuteCommand(ctx, &params)
 		return true, reply(ctx, resp, err)
 	case "textDocument/diagnostic": // req
-		var params string
+		var params DocumentDiagnosticParams
 		if err := json.Unmarshal(r.Params(), &params); err != nil {
 			return true, sendParseError(ctx, reply, err)
 		}
@@ -523,6 +519,34 @@ func serverDispatch(ctx context.Context, server Server, reply jsonrpc2.Replier,
 		}
 		err := server.DiagnosticRefresh(ctx)
 		return true, reply(ctx, nil, err)
+	case "textDocument/prepareTypeHierarchy": // req
+		var params TypeHierarchyPrepareParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.PrepareTypeHierarchy(ctx, &params)
+		return true, reply(ctx, resp, err)
+	case "typeHierarchy/supertypes": // req
+		var params TypeHierarchySupertypesParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.Supertypes(ctx, &params)
+		return true, reply(ctx, resp, err)
+	case "typeHierarchy/subtypes": // req
+		var params TypeHierarchySubtypesParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.Subtypes(ctx, &params)
+		return true, reply(ctx, resp, err)
+	case "textDocument/inlayHint": // req
+		var params InlayHintParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.InlayHint(ctx, &params)
+		return true, reply(ctx, resp, err)
 
 	default:
 		return false, nil
@@ -588,10 +612,6 @@ func (s *serverDispatcher) DidChangeWatchedFiles(ctx context.Context, params *Di
 func (s *serverDispatcher) SetTrace(ctx context.Context, params *SetTraceParams) error {
 	return s.sender.Notify(ctx, "$/setTrace", params)
 }
-
-func (s *serverDispatcher) LogTrace(ctx context.Context, params *LogTraceParams) error {
-	return s.sender.Notify(ctx, "$/logTrace", params)
-}
 func (s *serverDispatcher) Implementation(ctx context.Context, params *ImplementationParams) (Definition /*Definition | DefinitionLink[] | null*/, error) {
 	var result Definition /*Definition | DefinitionLink[] | null*/
 	if err := s.sender.Call(ctx, "textDocument/implementation", params, &result); err != nil {
@@ -932,8 +952,8 @@ func (s *serverDispatcher) ExecuteCommand(ctx context.Context, params *ExecuteCo
 	return result, nil
 }
 
-func (s *serverDispatcher) Diagnostic(ctx context.Context, params *string) (*string, error) {
-	var result *string
+func (s *serverDispatcher) Diagnostic(ctx context.Context, params *DocumentDiagnosticParams) (DocumentDiagnosticReport, error) {
+	var result DocumentDiagnosticReport
 	if err := s.sender.Call(ctx, "textDocument/diagnostic", params, &result); err != nil {
 		return nil, err
 	}
@@ -952,6 +972,38 @@ func (s *serverDispatcher) DiagnosticRefresh(ctx context.Context) error {
 	return s.sender.Call(ctx, "workspace/diagnostic/refresh", nil, nil)
 }
 
+func (s *serverDispatcher) PrepareTypeHierarchy(ctx context.Context, params *TypeHierarchyPrepareParams) ([]TypeHierarchyItem /*TypeHierarchyItem[] | null*/, error) {
+	var result []TypeHierarchyItem /*TypeHierarchyItem[] | null*/
+	if err := s.sender.Call(ctx, "textDocument/prepareTypeHierarchy", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *serverDispatcher) Supertypes(ctx context.Context, params *TypeHierarchySupertypesParams) ([]TypeHierarchyItem /*TypeHierarchyItem[] | null*/, error) {
+	var result []TypeHierarchyItem /*TypeHierarchyItem[] | null*/
+	if err := s.sender.Call(ctx, "typeHierarchy/supertypes", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *serverDispatcher) Subtypes(ctx context.Context, params *TypeHierarchySubtypesParams) ([]TypeHierarchyItem /*TypeHierarchyItem[] | null*/, error) {
+	var result []TypeHierarchyItem /*TypeHierarchyItem[] | null*/
+	if err := s.sender.Call(ctx, "typeHierarchy/subtypes", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *serverDispatcher) InlayHint(ctx context.Context, params *InlayHintParams) ([]InlayHint /*InlayHint[] | null*/, error) {
+	var result []InlayHint /*InlayHint[] | null*/
+	if err := s.sender.Call(ctx, "textDocument/inlayHint", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (s *serverDispatcher) NonstandardRequest(ctx context.Context, method string, params interface{}) (interface{}, error) {
 	var result interface{}
 	if err := s.sender.Call(ctx, method, params, &result); err != nil {
@@ -27,7 +27,7 @@ var program: ts.Program;
 function parse() {
   // this won't complain if some fnames don't exist
   program = ts.createProgram(
-    u.fnames,
+    u.fnames.concat(u.proposedFname),
     { target: ts.ScriptTarget.ES2018, module: ts.ModuleKind.CommonJS });
   program.getTypeChecker();  // finish type checking and assignment
 }
@@ -137,6 +137,7 @@ function setReceives() {
   receives.set('window/workDoneProgress/create', 'client');
   receives.set('window/showDocument', 'client');
   receives.set('$/progress', 'client');
+  receives.set('$/logTrace', 'client');
   // a small check
   receives.forEach((_, k) => {
     if (!req.get(k) && !not.get(k)) throw new Error(`145 missing ${k}}`);
@@ -490,6 +491,9 @@ function moreTypes() {
   extra('WorkDoneProgressBegin');
   extra('WorkDoneProgressReport');
   extra('WorkDoneProgressEnd');
+  // the types of the proposed capabilities
+  proposedCapabilities.forEach((_, nm) => proposedProperties(nm).forEach(
+    (p) => underlying(p.type, (n) => extra(goName(n.getText())))));
   let old = 0;
   do {
     old = seenTypes.size;
@@ -691,9 +695,32 @@ var starred: [string, string][] = [
   ['TextDocumentContentChangeEvent', 'range'], ['CodeAction', 'command'],
   ['CodeAction', 'disabled'],
   ['DidSaveTextDocumentParams', 'text'], ['CompletionItem', 'command'],
-  ['Diagnostic', 'codeDescription']
+  ['Diagnostic', 'codeDescription'], ['InlayHintLabelPart', 'location'],
+  ['InlayHintLabelPart', 'command']
 ];
 
+// The capabilities of the proposed features are declared in interfaces of
+// their own, like DiagnosticServerCapabilities, rather than in
+// ServerCapabilities or TextDocumentClientCapabilities. goInterface adds them
+// to the interface they extend.
+const proposedCapabilities = new Map<string, string[]>([
+  ['ServerCapabilities', [
+    'DiagnosticServerCapabilities', 'TypeHierarchyServerCapabilities',
+    'InlayHintServerCapabilities']],
+  ['TextDocumentClientCapabilities', [
+    'TypeHierarchyTextDocumentClientCapabilities',
+    'InlayHintTextDocumentClientCapabilities']],
+]);
+function proposedProperties(nm: string): ts.PropertySignature[] {
+  const ans: ts.PropertySignature[] = [];
+  (proposedCapabilities.get(nm) || []).forEach((pnm) => {
+    const d = data.get(pnm);
+    if (!d) throw new Error(`missing proposed capabilities ${pnm}`);
+    d.properties.forEach((p) => ans.push(p));
+  });
+  return ans;
+}
+
 // generate Go code for an interface
 function goInterface(d: Data, nm: string) {
   let ans = `type ${goName(nm)} struct {\n`;
@@ -715,7 +742,14 @@ function goInterface(d: Data, nm: string) {
     });
     ans = ans.concat(`${goName(n.name.getText())} ${gt}`, json, '\n');
   };
-  d.properties.forEach(g);
+  d.properties.forEach((n) => {
+    // the proposed server capabilities go just before the experimental ones
+    if (d.name == 'ServerCapabilities' && n.name.getText() == 'experimental') {
+      proposedProperties(d.name).forEach(g);
+    }
+    g(n);
+  });
+  if (d.name != 'ServerCapabilities') proposedProperties(d.name).forEach(g);
   // heritage clauses become embedded types
   // check they are all Identifiers
   const f = function (n: ts.ExpressionWithTypeArguments) {
@@ -808,7 +842,7 @@ function goType(n: ts.TypeNode | undefined, nm: string): string {
   if (n.getText() == 'T') return 'interface{}';  // should check it's generic
   if (ts.isTypeReferenceNode(n)) {
     // DocumentDiagnosticReportKind.unChanged (or .new) value is "new" or "unChanged"
-    if (n.getText().startsWith('DocumentDiagnostic')) return 'string';
+    if (n.getText().startsWith('DocumentDiagnosticReportKind')) return 'string';
     switch (n.getText()) {
       case 'integer': return 'int32';
       case 'uinteger': return 'uint32';
@@ -940,7 +974,7 @@ function goUnionType(n: ts.UnionTypeNode, nm: string): string {
       break;
     }
     case 4:
-      if (nm == 'documentChanges') return `TextDocumentEdit ${help} `;
+      if (nm == 'documentChanges') return `DocumentChanges ${help} `;
       if (nm == 'textDocument/prepareRename') return `Range ${help} `;
     // eslint-disable-next-line no-fallthrough
     default:
@@ -1285,7 +1319,8 @@ function indirect(s: string): boolean {
   if (s == '' || s == 'void') return false;
   const skip = (x: string) => s.startsWith(x);
   if (skip('[]') || skip('interface') || skip('Declaration') ||
-    skip('Definition') || skip('DocumentSelector'))
+    skip('Definition') || skip('DocumentSelector') ||
+    skip('DocumentDiagnosticReport'))
     return false;
   return true;
 }
@@ -0,0 +1,338 @@
+/* --------------------------------------------------------------------------------------------
+ * Copyright (c) Microsoft Corporation. All rights reserved.
+ * Licensed under the MIT License. See License.txt in the project root for license information.
+ * ------------------------------------------------------------------------------------------ */
+
+// The LSP 3.17 definitions of the features that gopls implements but that
+// are missing from the commit of vscode-languageserver-node that code.ts
+// reads, transcribed from the protocol.*.ts files of the 3.17 release.
+// This file is only read by code.ts, and is not compiled by tsc.
+//
+// As for the proposed diagnostics, the capabilities are declared in
+// interfaces of their own, which goInterface adds to ServerCapabilities and
+// TextDocumentClientCapabilities.
+
+/**
+ * @since 3.17.0
+ */
+export interface TypeHierarchyClientCapabilities {
+	/**
+	 * Whether implementation supports dynamic registration. If this is set to `true`
+	 * the client supports the new `(TextDocumentRegistrationOptions & StaticRegistrationOptions)`
+	 * return value for the corresponding server capability as well.
+	 */
+	dynamicRegistration?: boolean;
+}
+
+/**
+ * Type hierarchy options used during static registration.
+ *
+ * @since 3.17.0
+ */
+export interface TypeHierarchyOptions extends WorkDoneProgressOptions {
+}
+
+/**
+ * Type hierarchy options used during static or dynamic registration.
+ *
+ * @since 3.17.0
+ */
+export interface TypeHierarchyRegistrationOptions extends TextDocumentRegistrationOptions, TypeHierarchyOptions, StaticRegistrationOptions {
+}
+
+/**
+ * @since 3.17.0
+ */
+export interface TypeHierarchyItem {
+	/**
+	 * The name of this item.
+	 */
+	name: string;
+	/**
+	 * The kind of this item.
+	 */
+	kind: SymbolKind;
+	/**
+	 * Tags for this item.
+	 */
+	tags?: SymbolTag[];
+	/**
+	 * More detail for this item, e.g. the signature of a function.
+	 */
+	detail?: string;
+	/**
+	 * The resource identifier of this item.
+	 */
+	uri: DocumentUri;
+	/**
+	 * The range enclosing this symbol not including leading/trailing whitespace
+	 * but everything else, e.g. comments and code.
+	 */
+	range: Range;
+	/**
+	 * The range that should be selected and revealed when this symbol is being
+	 * picked, e.g. the name of a function. Must be contained by the
+	 * [`range`](#TypeHierarchyItem.range).
+	 */
+	selectionRange: Range;
+	/**
+	 * A data entry field that is preserved between a type hierarchy prepare and
+	 * supertypes or subtypes requests. It could also be used to identify the
+	 * type hierarchy in the server, helping improve the performance on
+	 * resolving supertypes and subtypes.
+	 */
+	data?: unknown;
+}
+
+/**
+ * The parameter of a `textDocument/prepareTypeHierarchy` request.
+ *
+ * @since 3.17.0
+ */
+export interface TypeHierarchyPrepareParams extends TextDocumentPositionParams, WorkDoneProgressParams {
+}
+
+/**
+ * A request to result a `TypeHierarchyItem` in a document at a given position.
+ * Can be used as an input to a subtypes or supertypes type hierarchy.
+ *
+ * @since 3.17.0
+ */
+export namespace TypeHierarchyPrepareRequest {
+	export const method: 'textDocument/prepareTypeHierarchy' = 'textDocument/prepareTypeHierarchy';
+	export const type = new ProtocolRequestType<TypeHierarchyPrepareParams, TypeHierarchyItem[] | null, never, void, TypeHierarchyRegistrationOptions>(method);
+}
+
+/**
+ * The parameter of a `typeHierarchy/supertypes` request.
+ *
+ * @since 3.17.0
+ */
+export interface TypeHierarchySupertypesParams extends WorkDoneProgressParams, PartialResultParams {
+	item: TypeHierarchyItem;
+}
+
+/**
+ * A request to resolve the supertypes for a given `TypeHierarchyItem`.
+ *
+ * @since 3.17.0
+ */
+export namespace TypeHierarchySupertypesRequest {
+	export const method: 'typeHierarchy/supertypes' = 'typeHierarchy/supertypes';
+	export const type = new ProtocolRequestType<TypeHierarchySupertypesParams, TypeHierarchyItem[] | null, TypeHierarchyItem[], void, void>(method);
+}
+
+/**
+ * The parameter of a `typeHierarchy/subtypes` request.
+ *
+ * @since 3.17.0
+ */
+export interface TypeHierarchySubtypesParams extends WorkDoneProgressParams, PartialResultParams {
+	item: TypeHierarchyItem;
+}
+
+/**
+ * A request to resolve the subtypes for a given `TypeHierarchyItem`.
+ *
+ * @since 3.17.0
+ */
+export namespace TypeHierarchySubtypesRequest {
+	export const method: 'typeHierarchy/subtypes' = 'typeHierarchy/subtypes';
+	export const type = new ProtocolRequestType<TypeHierarchySubtypesParams, TypeHierarchyItem[] | null, TypeHierarchyItem[], void, void>(method);
+}
+
+export interface TypeHierarchyServerCapabilities {
+	/**
+	 * The server provides type hierarchy support.
+	 *
+	 * @since 3.17.0
+	 */
+	typeHierarchyProvider?: boolean | TypeHierarchyOptions | TypeHierarchyRegistrationOptions;
+}
+
+export interface TypeHierarchyTextDocumentClientCapabilities {
+	/**
+	 * Capabilities specific to the various type hierarchy requests.
+	 *
+	 * @since 3.17.0
+	 */
+	typeHierarchy?: TypeHierarchyClientCapabilities;
+}
+
+/**
+ * Inlay hint kinds.
+ *
+ * @since 3.17.0
+ */
+export namespace InlayHintKind {
+	/**
+	 * An inlay hint that is for a type annotation.
+	 */
+	export const Type = 1;
+	/**
+	 * An inlay hint that is for a parameter.
+	 */
+	export const Parameter = 2;
+}
+
+export type InlayHintKind = 1 | 2;
+
+/**
+ * An inlay hint label part allows for interactive and composite labels
+ * of inlay hints.
+ *
+ * @since 3.17.0
+ */
+export interface InlayHintLabelPart {
+	/**
+	 * The value of this label part.
+	 */
+	value: string;
+	/**
+	 * The tooltip text when you hover over this label part.
+	 */
+	tooltip?: string | MarkupContent;
+	/**
+	 * An optional source code location that represents this
+	 * label part.
+	 */
+	location?: Location;
+	/**
+	 * An optional command for this label part.
+	 */
+	command?: Command;
+}
+
+/**
+ * Inlay hint information.
+ *
+ * @since 3.17.0
+ */
+export interface InlayHint {
+	/**
+	 * The position of this hint.
+	 */
+	position: Position;
+	/**
+	 * The label of this hint. A human readable string or an array of
+	 * InlayHintLabelPart label parts.
+	 *
+	 * *Note* that neither the string nor the label part can be empty.
+	 */
+	label: string | InlayHintLabelPart[];
+	/**
+	 * The kind of this hint. Can be omitted in which case the client
+	 * should fall back to a reasonable default.
+	 */
+	kind?: InlayHintKind;
+	/**
+	 * Optional text edits that are performed when accepting this inlay hint.
+	 */
+	textEdits?: TextEdit[];
+	/**
+	 * The tooltip text when you hover over this item.
+	 */
+	tooltip?: string | MarkupContent;
+	/**
+	 * Render padding before the hint.
+	 */
+	paddingLeft?: boolean;
+	/**
+	 * Render padding after the hint.
+	 */
+	paddingRight?: boolean;
+	/**
+	 * A data entry field that is preserved on an inlay hint between
+	 * a `textDocument/inlayHint` and a `inlayHint/resolve` request.
+	 */
+	data?: unknown;
+}
+
+/**
+ * Inlay hint client capabilities.
+ *
+ * @since 3.17.0
+ */
+export interface InlayHintClientCapabilities {
+	/**
+	 * Whether inlay hints support dynamic registration.
+	 */
+	dynamicRegistration?: boolean;
+	/**
+	 * Indicates which properties a client can resolve lazily on a inlay
+	 * hint.
+	 */
+	resolveSupport?: {
+		/**
+		 * The properties that a client can resolve lazily.
+		 */
+		properties: string[];
+	};
+}
+
+/**
+ * Inlay hint options used during static registration.
+ *
+ * @since 3.17.0
+ */
+export interface InlayHintOptions extends WorkDoneProgressOptions {
+	/**
+	 * The server provides support to resolve additional
+	 * information for an inlay hint item.
+	 */
+	resolveProvider?: boolean;
+}
+
+/**
+ * Inlay hint options used during static or dynamic registration.
+ *
+ * @since 3.17.0
+ */
+export interface InlayHintRegistrationOptions extends InlayHintOptions, TextDocumentRegistrationOptions, StaticRegistrationOptions {
+}
+
+/**
+ * A parameter literal used in inlay hint requests.
+ *
+ * @since 3.17.0
+ */
+export interface InlayHintParams extends WorkDoneProgressParams {
+	/**
+	 * The text document.
+	 */
+	textDocument: TextDocumentIdentifier;
+	/**
+	 * The visible document range for which inlay hints should be computed.
+	 */
+	range: Range;
+}
+
+/**
+ * A request to provide inlay hints in a document. The request's parameter is of
+ * type [InlayHintsParams](#InlayHintsParams), the response is of type
+ * [InlayHint[]](#InlayHint[]) or a Thenable that resolves to such.
+ *
+ * @since 3.17.0
+ */
+export namespace InlayHintRequest {
+	export const method: 'textDocument/inlayHint' = 'textDocument/inlayHint';
+	export const type = new ProtocolRequestType<InlayHintParams, InlayHint[] | null, InlayHint[], void, InlayHintRegistrationOptions>(method);
+}
+
+export interface InlayHintServerCapabilities {
+	/**
+	 * The server provides inlay hints.
+	 *
+	 * @since 3.17.0
+	 */
+	inlayHintProvider?: boolean | InlayHintOptions | InlayHintRegistrationOptions;
+}
+
+export interface InlayHintTextDocumentClientCapabilities {
+	/**
+	 * Capabilities specific to the `textDocument/inlayHint` request.
+	 *
+	 * @since 3.17.0
+	 */
+	inlayHint?: InlayHintClientCapabilities;
+}
@@ -15,6 +15,9 @@ export const fnames = [
   `${dir}/${srcDir}/protocol/src/browser/main.ts`, `${dir}${srcDir}/types/src/main.ts`,
   `${dir}${srcDir}/jsonrpc/src/node/main.ts`
 ];
+// proposedFname holds the definitions of the features that gopls implements
+// ahead of the vscode-languageserver-node commit.
+export const proposedFname = `${__dirname}/proposed.ts`;
 export const gitHash = '0cb3812e7d540ef3a904e96df795bc37a21de9b0';
 let outFname = 'tsprotocol.go';
 let fda: number, fdb: number, fde: number;  // file descriptors
@@ -376,6 +376,112 @@ func (e *Env) Rename(path string, pos fake.Pos, newName string) {
 	}
 }
 
+// WillRenameFiles notifies the server that oldPath is about to be renamed to
+// newPath, and applies the resulting edits, calling t.Fatal on any error.
+func (e *Env) WillRenameFiles(oldPath, newPath string) {
+	e.T.Helper()
+	if err := e.Editor.WillRenameFiles(e.Ctx, oldPath, newPath); err != nil {
+		e.T.Fatal(err)
+	}
+}
+
+// Diagnostic pulls the diagnostics for the buffer at path, calling t.Fatal
+// on any error.
+func (e *Env) Diagnostic(path, previousResultID string) *protocol.FullDocumentDiagnosticReport {
+	e.T.Helper()
+	report, err := e.Editor.Diagnostic(e.Ctx, path, previousResultID)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return report
+}
+
+// WorkspaceDiagnostic pulls the diagnostics for the entire workspace,
+// calling t.Fatal on any error.
+func (e *Env) WorkspaceDiagnostic(previousResultIDs map[string]string) []protocol.WorkspaceFullDocumentDiagnosticReport {
+	e.T.Helper()
+	reports, err := e.Editor.WorkspaceDiagnostic(e.Ctx, previousResultIDs)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return reports
+}
+
+// DocumentColor returns the colors of the buffer at path, calling t.Fatal on
+// any error.
+func (e *Env) DocumentColor(path string) []protocol.ColorInformation {
+	e.T.Helper()
+	colors, err := e.Editor.DocumentColor(e.Ctx, path)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return colors
+}
+
+// ColorPresentation returns the presentations of color for the color at rng
+// in the buffer at path, calling t.Fatal on any error.
+func (e *Env) ColorPresentation(path string, rng protocol.Range, color protocol.Color) []protocol.ColorPresentation {
+	e.T.Helper()
+	presentations, err := e.Editor.ColorPresentation(e.Ctx, path, rng, color)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return presentations
+}
+
+// PrepareTypeHierarchy returns the type hierarchy items for the type at pos
+// in the buffer at path, calling t.Fatal on any error.
+func (e *Env) PrepareTypeHierarchy(path string, pos fake.Pos) []protocol.TypeHierarchyItem {
+	e.T.Helper()
+	items, err := e.Editor.PrepareTypeHierarchy(e.Ctx, path, pos)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return items
+}
+
+// Supertypes returns the supertypes of the type hierarchy item, calling
+// t.Fatal on any error.
+func (e *Env) Supertypes(item protocol.TypeHierarchyItem) []protocol.TypeHierarchyItem {
+	e.T.Helper()
+	items, err := e.Editor.Supertypes(e.Ctx, item)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return items
+}
+
+// Subtypes returns the subtypes of the type hierarchy item, calling t.Fatal
+// on any error.
+func (e *Env) Subtypes(item protocol.TypeHierarchyItem) []protocol.TypeHierarchyItem {
+	e.T.Helper()
+	items, err := e.Editor.Subtypes(e.Ctx, item)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return items
+}
+
+// InlayHint returns the inlay hints of the buffer at path, calling t.Fatal on
+// any error.
+func (e *Env) InlayHint(path string) []protocol.InlayHint {
+	e.T.Helper()
+	hints, err := e.Editor.InlayHint(e.Ctx, path)
+	if err != nil {
+		e.T.Fatal(err)
+	}
+	return hints
+}
+
+// RenameFiles renames oldPath to newPath in the workdir and notifies the
+// server, calling t.Fatal on any error.
+func (e *Env) RenameFiles(oldPath, newPath string) {
+	e.T.Helper()
+	if err := e.Editor.RenameFiles(e.Ctx, oldPath, newPath); err != nil {
+		e.T.Fatal(err)
+	}
+}
+
 // Completion executes a completion request on the server.
 func (e *Env) Completion(path string, pos fake.Pos) *protocol.CompletionList {
 	e.T.Helper()
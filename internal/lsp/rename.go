@@ -9,6 +9,7 @@ import (
 
 	"github.com/kent0106/gotools/internal/lsp/protocol"
 	"github.com/kent0106/gotools/internal/lsp/source"
+	"github.com/kent0106/gotools/internal/span"
 )
 
 func (s *Server) rename(ctx context.Context, params *protocol.RenameParams) (*protocol.WorkspaceEdit, error) {
@@ -22,7 +23,7 @@ func (s *Server) rename(ctx context.Context, params *protocol.RenameParams) (*pr
 		return nil, err
 	}
 
-	var docChanges []protocol.TextDocumentEdit
+	var docChanges []protocol.DocumentChanges
 	for uri, e := range edits {
 		fh, err := snapshot.GetVersionedFile(ctx, uri)
 		if err != nil {
@@ -52,3 +53,38 @@ func (s *Server) prepareRename(ctx context.Context, params *protocol.PrepareRena
 	// TODO(suzmue): return ident.Name as the placeholder text.
 	return &item.Range, nil
 }
+
+func (s *Server) willRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) (*protocol.WorkspaceEdit, error) {
+	var docChanges []protocol.DocumentChanges
+	for _, rename := range params.Files {
+		oldURI, newURI := span.URIFromURI(rename.OldURI), span.URIFromURI(rename.NewURI)
+		if !oldURI.IsFile() || !newURI.IsFile() {
+			continue
+		}
+		view, err := s.session.ViewOf(oldURI)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, release := view.Snapshot(ctx)
+		edits, err := source.RenameFile(ctx, snapshot, oldURI, newURI)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		for uri, e := range edits {
+			fh, err := snapshot.GetVersionedFile(ctx, uri)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			docChanges = append(docChanges, documentChanges(fh, e)...)
+		}
+		release()
+	}
+	if len(docChanges) == 0 {
+		return nil, nil
+	}
+	return &protocol.WorkspaceEdit{
+		DocumentChanges: docChanges,
+	}, nil
+}
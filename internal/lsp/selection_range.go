@@ -0,0 +1,46 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/lsp/source"
+)
+
+func (s *Server) selectionRange(ctx context.Context, params *protocol.SelectionRangeParams) ([]protocol.SelectionRange, error) {
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.Go)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+
+	result := make([]protocol.SelectionRange, 0, len(params.Positions))
+	for _, pos := range params.Positions {
+		rngs, err := source.SelectionRange(ctx, snapshot, fh, pos)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, toProtocolSelectionRange(rngs))
+	}
+	return result, nil
+}
+
+// toProtocolSelectionRange converts a list of ranges, ordered from innermost
+// to outermost, into the linked form expected by the protocol.
+func toProtocolSelectionRange(rngs []protocol.Range) protocol.SelectionRange {
+	var sel *protocol.SelectionRange
+	for i := len(rngs) - 1; i >= 0; i-- {
+		sel = &protocol.SelectionRange{
+			Range:  rngs[i],
+			Parent: sel,
+		}
+	}
+	if sel == nil {
+		return protocol.SelectionRange{}
+	}
+	return *sel
+}
@@ -27,6 +27,7 @@ func NewServer(session source.Session, client protocol.ClientCloser) *Server {
 	session.SetProgressTracker(tracker)
 	return &Server{
 		diagnostics:           map[span.URI]*fileReports{},
+		diagnosedSnapshots:    map[span.URI]uint64{},
 		gcOptimizationDetails: make(map[string]struct{}),
 		watchedGlobPatterns:   make(map[string]struct{}),
 		changedFiles:          make(map[span.URI]struct{}),
@@ -92,6 +93,9 @@ type Server struct {
 
 	diagnosticsMu sync.Mutex
 	diagnostics   map[span.URI]*fileReports
+	// diagnosedSnapshots records, for the folder of each view, the ID of the
+	// last snapshot whose diagnostics have all been computed.
+	diagnosedSnapshots map[span.URI]uint64
 
 	// gcOptimizationDetails describes the packages for which we want
 	// optimization details to be included in the diagnostics. The key is the
@@ -128,6 +132,24 @@ func (s *Server) workDoneProgressCancel(ctx context.Context, params *protocol.Wo
 	return s.progress.Cancel(ctx, params.Token)
 }
 
+func (s *Server) setTrace(ctx context.Context, params *protocol.SetTraceParams) error {
+	return updateTrace(ctx, params.Value)
+}
+
+// updateTrace updates the trace setting of the client of the request ctx, which
+// determines the log events sent to the client as $/logTrace notifications.
+func updateTrace(ctx context.Context, value protocol.TraceValues) error {
+	switch value {
+	case "off", "messages", "verbose":
+	default:
+		return errors.Errorf("%w: invalid trace value %q", jsonrpc2.ErrInvalidParams, value)
+	}
+	if tracer := protocol.GetTracer(ctx); tracer != nil {
+		tracer.Set(value)
+	}
+	return nil
+}
+
 func (s *Server) nonstandardRequest(ctx context.Context, method string, params interface{}) (interface{}, error) {
 	switch method {
 	case "gopls/diagnoseFiles":
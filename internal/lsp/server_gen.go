@@ -24,8 +24,8 @@ func (s *Server) CodeLensRefresh(context.Context) error {
 	return notImplemented("CodeLensRefresh")
 }
 
-func (s *Server) ColorPresentation(context.Context, *protocol.ColorPresentationParams) ([]protocol.ColorPresentation, error) {
-	return nil, notImplemented("ColorPresentation")
+func (s *Server) ColorPresentation(ctx context.Context, params *protocol.ColorPresentationParams) ([]protocol.ColorPresentation, error) {
+	return s.colorPresentation(ctx, params)
 }
 
 func (s *Server) Completion(ctx context.Context, params *protocol.CompletionParams) (*protocol.CompletionList, error) {
@@ -40,16 +40,16 @@ func (s *Server) Definition(ctx context.Context, params *protocol.DefinitionPara
 	return s.definition(ctx, params)
 }
 
-func (s *Server) Diagnostic(context.Context, *string) (*string, error) {
-	return nil, notImplemented("Diagnostic")
+func (s *Server) Diagnostic(ctx context.Context, params *protocol.DocumentDiagnosticParams) (protocol.DocumentDiagnosticReport, error) {
+	return s.diagnostic(ctx, params)
 }
 
 func (s *Server) DiagnosticRefresh(context.Context) error {
 	return notImplemented("DiagnosticRefresh")
 }
 
-func (s *Server) DiagnosticWorkspace(context.Context, *protocol.WorkspaceDiagnosticParams) (*protocol.WorkspaceDiagnosticReport, error) {
-	return nil, notImplemented("DiagnosticWorkspace")
+func (s *Server) DiagnosticWorkspace(ctx context.Context, params *protocol.WorkspaceDiagnosticParams) (*protocol.WorkspaceDiagnosticReport, error) {
+	return s.diagnosticWorkspace(ctx, params)
 }
 
 func (s *Server) DidChange(ctx context.Context, params *protocol.DidChangeTextDocumentParams) error {
@@ -84,16 +84,16 @@ func (s *Server) DidOpen(ctx context.Context, params *protocol.DidOpenTextDocume
 	return s.didOpen(ctx, params)
 }
 
-func (s *Server) DidRenameFiles(context.Context, *protocol.RenameFilesParams) error {
-	return notImplemented("DidRenameFiles")
+func (s *Server) DidRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) error {
+	return s.didRenameFiles(ctx, params)
 }
 
 func (s *Server) DidSave(ctx context.Context, params *protocol.DidSaveTextDocumentParams) error {
 	return s.didSave(ctx, params)
 }
 
-func (s *Server) DocumentColor(context.Context, *protocol.DocumentColorParams) ([]protocol.ColorInformation, error) {
-	return nil, notImplemented("DocumentColor")
+func (s *Server) DocumentColor(ctx context.Context, params *protocol.DocumentColorParams) ([]protocol.ColorInformation, error) {
+	return s.documentColor(ctx, params)
 }
 
 func (s *Server) DocumentHighlight(ctx context.Context, params *protocol.DocumentHighlightParams) ([]protocol.DocumentHighlight, error) {
@@ -144,24 +144,24 @@ func (s *Server) Initialized(ctx context.Context, params *protocol.InitializedPa
 	return s.initialized(ctx, params)
 }
 
-func (s *Server) LinkedEditingRange(context.Context, *protocol.LinkedEditingRangeParams) (*protocol.LinkedEditingRanges, error) {
-	return nil, notImplemented("LinkedEditingRange")
+func (s *Server) InlayHint(ctx context.Context, params *protocol.InlayHintParams) ([]protocol.InlayHint, error) {
+	return s.inlayHint(ctx, params)
 }
 
-func (s *Server) LogTrace(context.Context, *protocol.LogTraceParams) error {
-	return notImplemented("LogTrace")
+func (s *Server) LinkedEditingRange(ctx context.Context, params *protocol.LinkedEditingRangeParams) (*protocol.LinkedEditingRanges, error) {
+	return s.linkedEditingRange(ctx, params)
 }
 
-func (s *Server) Moniker(context.Context, *protocol.MonikerParams) ([]protocol.Moniker, error) {
-	return nil, notImplemented("Moniker")
+func (s *Server) Moniker(ctx context.Context, params *protocol.MonikerParams) ([]protocol.Moniker, error) {
+	return s.moniker(ctx, params)
 }
 
 func (s *Server) NonstandardRequest(ctx context.Context, method string, params interface{}) (interface{}, error) {
 	return s.nonstandardRequest(ctx, method, params)
 }
 
-func (s *Server) OnTypeFormatting(context.Context, *protocol.DocumentOnTypeFormattingParams) ([]protocol.TextEdit, error) {
-	return nil, notImplemented("OnTypeFormatting")
+func (s *Server) OnTypeFormatting(ctx context.Context, params *protocol.DocumentOnTypeFormattingParams) ([]protocol.TextEdit, error) {
+	return s.onTypeFormatting(ctx, params)
 }
 
 func (s *Server) OutgoingCalls(ctx context.Context, params *protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error) {
@@ -176,8 +176,12 @@ func (s *Server) PrepareRename(ctx context.Context, params *protocol.PrepareRena
 	return s.prepareRename(ctx, params)
 }
 
-func (s *Server) RangeFormatting(context.Context, *protocol.DocumentRangeFormattingParams) ([]protocol.TextEdit, error) {
-	return nil, notImplemented("RangeFormatting")
+func (s *Server) PrepareTypeHierarchy(ctx context.Context, params *protocol.TypeHierarchyPrepareParams) ([]protocol.TypeHierarchyItem, error) {
+	return s.prepareTypeHierarchy(ctx, params)
+}
+
+func (s *Server) RangeFormatting(ctx context.Context, params *protocol.DocumentRangeFormattingParams) ([]protocol.TextEdit, error) {
+	return s.rangeFormatting(ctx, params)
 }
 
 func (s *Server) References(ctx context.Context, params *protocol.ReferenceParams) ([]protocol.Location, error) {
@@ -192,20 +196,20 @@ func (s *Server) Resolve(context.Context, *protocol.CompletionItem) (*protocol.C
 	return nil, notImplemented("Resolve")
 }
 
-func (s *Server) ResolveCodeAction(context.Context, *protocol.CodeAction) (*protocol.CodeAction, error) {
-	return nil, notImplemented("ResolveCodeAction")
+func (s *Server) ResolveCodeAction(ctx context.Context, action *protocol.CodeAction) (*protocol.CodeAction, error) {
+	return s.resolveCodeAction(ctx, action)
 }
 
-func (s *Server) ResolveCodeLens(context.Context, *protocol.CodeLens) (*protocol.CodeLens, error) {
-	return nil, notImplemented("ResolveCodeLens")
+func (s *Server) ResolveCodeLens(ctx context.Context, lens *protocol.CodeLens) (*protocol.CodeLens, error) {
+	return s.resolveCodeLens(ctx, lens)
 }
 
 func (s *Server) ResolveDocumentLink(context.Context, *protocol.DocumentLink) (*protocol.DocumentLink, error) {
 	return nil, notImplemented("ResolveDocumentLink")
 }
 
-func (s *Server) SelectionRange(context.Context, *protocol.SelectionRangeParams) ([]protocol.SelectionRange, error) {
-	return nil, notImplemented("SelectionRange")
+func (s *Server) SelectionRange(ctx context.Context, params *protocol.SelectionRangeParams) ([]protocol.SelectionRange, error) {
+	return s.selectionRange(ctx, params)
 }
 
 func (s *Server) SemanticTokensFull(ctx context.Context, p *protocol.SemanticTokensParams) (*protocol.SemanticTokens, error) {
@@ -224,8 +228,8 @@ func (s *Server) SemanticTokensRefresh(ctx context.Context) error {
 	return s.semanticTokensRefresh(ctx)
 }
 
-func (s *Server) SetTrace(context.Context, *protocol.SetTraceParams) error {
-	return notImplemented("SetTrace")
+func (s *Server) SetTrace(ctx context.Context, params *protocol.SetTraceParams) error {
+	return s.setTrace(ctx, params)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
@@ -236,6 +240,14 @@ func (s *Server) SignatureHelp(ctx context.Context, params *protocol.SignatureHe
 	return s.signatureHelp(ctx, params)
 }
 
+func (s *Server) Subtypes(ctx context.Context, params *protocol.TypeHierarchySubtypesParams) ([]protocol.TypeHierarchyItem, error) {
+	return s.subtypes(ctx, params)
+}
+
+func (s *Server) Supertypes(ctx context.Context, params *protocol.TypeHierarchySupertypesParams) ([]protocol.TypeHierarchyItem, error) {
+	return s.supertypes(ctx, params)
+}
+
 func (s *Server) Symbol(ctx context.Context, params *protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
 	return s.symbol(ctx, params)
 }
@@ -252,8 +264,8 @@ func (s *Server) WillDeleteFiles(context.Context, *protocol.DeleteFilesParams) (
 	return nil, notImplemented("WillDeleteFiles")
 }
 
-func (s *Server) WillRenameFiles(context.Context, *protocol.RenameFilesParams) (*protocol.WorkspaceEdit, error) {
-	return nil, notImplemented("WillRenameFiles")
+func (s *Server) WillRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) (*protocol.WorkspaceEdit, error) {
+	return s.willRenameFiles(ctx, params)
 }
 
 func (s *Server) WillSave(context.Context, *protocol.WillSaveTextDocumentParams) error {
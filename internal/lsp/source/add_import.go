@@ -24,3 +24,25 @@ func AddImport(ctx context.Context, snapshot Snapshot, fh VersionedFileHandle, i
 		FixType: imports.AddImport,
 	})
 }
+
+// addImportEdits returns the edits adding the imports to the file, if it does
+// not import them yet.
+func addImportEdits(snapshot Snapshot, pgf *ParsedGoFile, imps []imports.ImportInfo) ([]protocol.TextEdit, error) {
+	return computeFixEdits(snapshot, pgf, importFixOptions(snapshot), addImportFixes(imps))
+}
+
+// addImports returns the source of a Go file with the imports added to it.
+func addImports(snapshot Snapshot, src []byte, imps []imports.ImportInfo) ([]byte, error) {
+	return imports.ApplyFixes(addImportFixes(imps), "", src, importFixOptions(snapshot), 0)
+}
+
+func addImportFixes(imps []imports.ImportInfo) []*imports.ImportFix {
+	var fixes []*imports.ImportFix
+	for _, imp := range imps {
+		fixes = append(fixes, &imports.ImportFix{
+			StmtInfo: imp,
+			FixType:  imports.AddImport,
+		})
+	}
+	return fixes
+}
@@ -666,6 +666,45 @@ var GeneratedAPIJSON = &APIJSON{
 				Status:     "experimental",
 				Hierarchy:  "ui.diagnostic",
 			},
+			{
+				Name: "hints",
+				Type: "map[string]bool",
+				Doc:  "hints enables or disables the kinds of inlay hints, keyed by their\nnames. All the hints are disabled by default.\n",
+				EnumKeys: EnumKeys{
+					ValueType: "bool",
+					Keys: []EnumKey{
+						{
+							Name:    "\"assignVariableTypes\"",
+							Doc:     "`\"assignVariableTypes\"`: Enable/disable inlay hints for variable types in assign statements:\n```go\n\ti/* int */, j/* int */ := 0, len(r)-1\n```",
+							Default: "false",
+						},
+						{
+							Name:    "\"compositeLiteralFields\"",
+							Doc:     "`\"compositeLiteralFields\"`: Enable/disable inlay hints for composite literal field names:\n```go\n\t{/* in: */ \"Hello, world\", /* want: */ \"dlrow ,olleH\"}\n```",
+							Default: "false",
+						},
+						{
+							Name:    "\"constantValues\"",
+							Doc:     "`\"constantValues\"`: Enable/disable inlay hints for constant values:\n```go\n\tconst (\n\t\tKindNone   Kind = iota/* = 0 */\n\t\tKindPrint/*  = 1 */\n\t\tKindPrintf/* = 2 */\n\t\tKindErrorf/* = 3 */\n\t)\n```",
+							Default: "false",
+						},
+						{
+							Name:    "\"parameterNames\"",
+							Doc:     "`\"parameterNames\"`: Enable/disable inlay hints for parameter names:\n```go\n\tparseInt(/* str: */ \"123\", /* radix: */ 8)\n```",
+							Default: "false",
+						},
+						{
+							Name:    "\"rangeVariableTypes\"",
+							Doc:     "`\"rangeVariableTypes\"`: Enable/disable inlay hints for variable types in range statements:\n```go\n\tfor k/* int */, v/* string */ := range []string{} {\n\t\tfmt.Println(k, v)\n\t}\n```",
+							Default: "false",
+						},
+					},
+				},
+				EnumValues: nil,
+				Default:    "{}",
+				Status:     "experimental",
+				Hierarchy:  "ui.inlayhint",
+			},
 			{
 				Name: "codelenses",
 				Type: "map[string]bool",
@@ -754,6 +793,58 @@ var GeneratedAPIJSON = &APIJSON{
 				Status:     "",
 				Hierarchy:  "formatting",
 			},
+			{
+				Name: "structTags",
+				Type: "[]string",
+				Doc:  "structTags lists the keys of the tags that the code actions on a\nstruct type add to, remove from or normalize in its fields.\n",
+				EnumKeys: EnumKeys{
+					ValueType: "",
+					Keys:      nil,
+				},
+				EnumValues: nil,
+				Default:    "[\"json\"]",
+				Status:     "experimental",
+				Hierarchy:  "formatting",
+			},
+			{
+				Name: "structTagCase",
+				Type: "enum",
+				Doc:  "structTagCase controls how the names in the tags added or normalized\nby the code actions on a struct type are derived from the names of the\nfields.\n",
+				EnumKeys: EnumKeys{
+					ValueType: "",
+					Keys:      nil,
+				},
+				EnumValues: []EnumValue{
+					{
+						Value: "\"camel\"",
+						Doc:   "`\"camel\"` derives the names from the names of the fields in\nlower camel case, as in \"userId\".\n",
+					},
+					{
+						Value: "\"kebab\"",
+						Doc:   "`\"kebab\"` derives the names from the names of the fields in\nkebab case, as in \"user-id\".\n",
+					},
+					{
+						Value: "\"snake\"",
+						Doc:   "`\"snake\"` derives the names from the names of the fields in\nsnake case, as in \"user_id\".\n",
+					},
+				},
+				Default:   "\"camel\"",
+				Status:    "experimental",
+				Hierarchy: "formatting",
+			},
+			{
+				Name: "structTagOmitEmpty",
+				Type: "bool",
+				Doc:  "structTagOmitEmpty adds the omitempty option to the tags added or\nnormalized by the code actions on a struct type.\n",
+				EnumKeys: EnumKeys{
+					ValueType: "",
+					Keys:      nil,
+				},
+				EnumValues: nil,
+				Default:    "false",
+				Status:     "experimental",
+				Hierarchy:  "formatting",
+			},
 			{
 				Name: "verboseOutput",
 				Type: "bool",
@@ -784,6 +875,13 @@ var GeneratedAPIJSON = &APIJSON{
 			ArgDoc:    "{\n\t// ImportPath is the target import path that should\n\t// be added to the URI file\n\t\"ImportPath\": string,\n\t// URI is the file that the ImportPath should be\n\t// added to\n\t\"URI\": string,\n}",
 			ResultDoc: "",
 		},
+		{
+			Command:   "gopls.add_test",
+			Title:     "Add a test for a function",
+			Doc:       "Adds a table-driven test of a function or method to the test file\nnext to the file declaring it, creating the test file if needed.",
+			ArgDoc:    "{\n\t// The file declaring the function or method.\n\t\"URI\": string,\n\t// The range of the declaration header of the function or method.\n\t\"Range\": {\n\t\t\"start\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t\t\"end\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t},\n}",
+			ResultDoc: "",
+		},
 		{
 			Command:   "gopls.apply_fix",
 			Title:     "Apply a fix",
@@ -791,6 +889,13 @@ var GeneratedAPIJSON = &APIJSON{
 			ArgDoc:    "{\n\t// The fix to apply.\n\t\"Fix\": string,\n\t// The file URI for the document to fix.\n\t\"URI\": string,\n\t// The document range to scan for fixes.\n\t\"Range\": {\n\t\t\"start\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t\t\"end\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t},\n}",
 			ResultDoc: "",
 		},
+		{
+			Command:   "gopls.change_signature",
+			Title:     "Change the signature of a function",
+			Doc:       "Removes, reorders or adds parameters of a function or method, and\nupdates its calls and the methods related to it through interfaces.",
+			ArgDoc:    "{\n\t// The file containing the name of the function or method.\n\t\"URI\": string,\n\t// The position of the name of the function or method.\n\t\"Position\": {\n\t\t\"line\": uint32,\n\t\t\"character\": uint32,\n\t},\n\t// The parameters of the new signature, in order.\n\t\"Params\": []{\n\t\t\"OldIndex\": int,\n\t\t\"Name\": string,\n\t\t\"Type\": string,\n\t\t\"Value\": string,\n\t},\n}",
+			ResultDoc: "",
+		},
 		{
 			Command:   "gopls.check_upgrades",
 			Title:     "Check for upgrades",
@@ -833,6 +938,20 @@ var GeneratedAPIJSON = &APIJSON{
 			ArgDoc:    "{\n\t// The file URI.\n\t\"URI\": string,\n}",
 			ResultDoc: "{\n\t// Packages is a list of packages relative\n\t// to the URIArg passed by the command request.\n\t// In other words, it omits paths that are already\n\t// imported or cannot be imported due to compiler\n\t// restrictions.\n\t\"Packages\": []string,\n}",
 		},
+		{
+			Command:   "gopls.modify_tags",
+			Title:     "Modify struct tags",
+			Doc:       "Adds, removes or normalizes the tags of the fields of a struct type.",
+			ArgDoc:    "{\n\t// The file containing the struct type.\n\t\"URI\": string,\n\t// A range within the struct type.\n\t\"Range\": {\n\t\t\"start\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t\t\"end\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t},\n\t// The keys of the tags to add to the fields lacking them.\n\t\"Add\": []string,\n\t// The keys of the tags to remove from the fields.\n\t\"Remove\": []string,\n\t// The keys of the tags whose names to derive anew from the names of the\n\t// fields. The tags of all the fields are also rewritten in the canonical\n\t// form.\n\t\"Normalize\": []string,\n\t// How the names are derived from the names of the fields: \"camel\",\n\t// \"snake\" or \"kebab\".\n\t\"Case\": string,\n\t// Whether to add the omitempty option to the added and normalized tags.\n\t\"OmitEmpty\": bool,\n}",
+			ResultDoc: "",
+		},
+		{
+			Command:   "gopls.move_declaration",
+			Title:     "Move a declaration",
+			Doc:       "Moves a package-level declaration, with the methods of a type, to\nanother file of its package or of another package, and updates the\nreferences to it.",
+			ArgDoc:    "{\n\t// The file containing the name of the declaration.\n\t\"URI\": string,\n\t// The position of the name of the declaration.\n\t\"Position\": {\n\t\t\"line\": uint32,\n\t\t\"character\": uint32,\n\t},\n\t// The file to move the declaration to, which is created if it does not\n\t// exist. Its directory must contain a package of the workspace.\n\t\"Dest\": string,\n}",
+			ResultDoc: "",
+		},
 		{
 			Command:   "gopls.regenerate_cgo",
 			Title:     "Regenerate cgo",
@@ -0,0 +1,584 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/command"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+	"github.com/kent0106/gotools/internal/typeparams"
+	errors "golang.org/x/xerrors"
+)
+
+// ChangeSignature returns the edits changing the parameters of the function
+// or method named at pp to params. The calls of the function are updated, as
+// well as the methods related to it through interfaces, so that they keep
+// implementing them.
+func ChangeSignature(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position, params []command.ChangeSignatureParam) ([]protocol.TextDocumentEdit, error) {
+	ctx, done := event.Start(ctx, "source.ChangeSignature")
+	defer done()
+
+	fix, err := changeSignature(ctx, snapshot, fh, pp, params)
+	if err != nil {
+		return nil, err
+	}
+	return suggestedFixEdits(ctx, snapshot, fix)
+}
+
+// removeUnusedParameter removes the parameter at the given range, as reported
+// by the unusedparams analyzer, from its function and the calls of the
+// function.
+func removeUnusedParameter(ctx context.Context, snapshot Snapshot, fh VersionedFileHandle, pRng protocol.Range) (*analysis.SuggestedFix, error) {
+	_, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.End)
+	var decl *ast.FuncDecl
+	for _, n := range path {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return nil, errors.Errorf("cannot remove a parameter of a function literal")
+		}
+		if n, ok := n.(*ast.FuncDecl); ok {
+			decl = n
+			break
+		}
+	}
+	if decl == nil {
+		return nil, errors.Errorf("no function declaration at %v", pRng)
+	}
+	index, removed := 0, -1
+	for _, field := range decl.Type.Params.List {
+		if len(field.Names) == 0 {
+			index++
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Pos() <= rng.Start && rng.Start <= name.End() {
+				removed = index
+			}
+			index++
+		}
+	}
+	if removed < 0 {
+		return nil, errors.Errorf("no parameter at %v", pRng)
+	}
+	var params []command.ChangeSignatureParam
+	for i := 0; i < index; i++ {
+		if i != removed {
+			params = append(params, command.ChangeSignatureParam{OldIndex: i})
+		}
+	}
+	nameRng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, decl.Name.Pos(), decl.Name.End()).Range()
+	if err != nil {
+		return nil, err
+	}
+	return changeSignature(ctx, snapshot, fh, nameRng.Start, params)
+}
+
+func changeSignature(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position, params []command.ChangeSignatureParam) (*analysis.SuggestedFix, error) {
+	qos, err := qualifiedObjsAtProtocolPos(ctx, snapshot, fh.URI(), pp)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := qos[0].obj.(*types.Func)
+	if !ok {
+		return nil, errors.Errorf("%s is not a function", qos[0].obj.Name())
+	}
+	sig := fn.Type().(*types.Signature)
+	if typeparams.ForSignature(sig).Len() > 0 || typeparams.RecvTypeParams(sig).Len() > 0 {
+		return nil, errors.Errorf("cannot change the signature of the generic function %s", fn.Name())
+	}
+	if err := checkParamChanges(sig, params); err != nil {
+		return nil, err
+	}
+	related, err := relatedMethods(ctx, snapshot, qos)
+	if err != nil {
+		return nil, err
+	}
+	active, err := snapshot.ActivePackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspace := make(map[string]bool)
+	for _, pkg := range active {
+		workspace[pkg.PkgPath()] = true
+	}
+
+	c := &sigChanger{
+		fset:   snapshot.FileSet(),
+		sig:    sig,
+		params: params,
+		files:  make(map[span.URI]*sigFile),
+	}
+	for _, qo := range append(qos, related...) {
+		if qo.pkg == nil || !workspace[qo.pkg.PkgPath()] {
+			return nil, errors.Errorf("%s must keep its signature to implement %s", fn.Name(), qo.obj)
+		}
+		refs, err := references(ctx, snapshot, []qualifiedObject{qo}, true, false, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			if err := c.update(ref); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c.fix(), nil
+}
+
+// checkParamChanges reports an error if params do not describe a valid
+// signature derived from sig.
+func checkParamChanges(sig *types.Signature, params []command.ChangeSignatureParam) error {
+	n := sig.Params().Len()
+	kept := make(map[int]bool)
+	for i, p := range params {
+		if p.OldIndex >= 0 {
+			if p.OldIndex >= n || kept[p.OldIndex] {
+				return errors.Errorf("invalid parameter index %d", p.OldIndex)
+			}
+			kept[p.OldIndex] = true
+			if sig.Variadic() && p.OldIndex == n-1 && i != len(params)-1 {
+				return errors.Errorf("the variadic parameter %s must remain the last parameter", sig.Params().At(n-1).Name())
+			}
+			continue
+		}
+		if p.OldIndex != -1 {
+			return errors.Errorf("invalid parameter index %d", p.OldIndex)
+		}
+		if p.Name != "_" && !isValidIdentifier(p.Name) {
+			return errors.Errorf("invalid parameter name %q", p.Name)
+		}
+		if _, err := parser.ParseExpr(p.Type); err != nil || strings.HasPrefix(p.Type, "...") {
+			return errors.Errorf("invalid type %q for parameter %s", p.Type, p.Name)
+		}
+		if _, err := parser.ParseExpr(p.Value); err != nil {
+			return errors.Errorf("invalid value %q for parameter %s", p.Value, p.Name)
+		}
+	}
+	if sig.Variadic() && !kept[n-1] {
+		return errors.Errorf("cannot remove the variadic parameter %s", sig.Params().At(n-1).Name())
+	}
+	return nil
+}
+
+// relatedMethods returns the methods related to the method of qos through
+// interfaces: the interface methods it implements, the other implementations
+// of these methods, and so on.
+func relatedMethods(ctx context.Context, snapshot Snapshot, qos []qualifiedObject) ([]qualifiedObject, error) {
+	var related []qualifiedObject
+	fset := snapshot.FileSet()
+	seen := map[token.Position]bool{fset.Position(qos[0].obj.Pos()): true}
+	queue := qos[:1]
+	for len(queue) > 0 {
+		qo := queue[0]
+		queue = queue[1:]
+		if qo.pkg == nil {
+			continue
+		}
+		rng, err := objToMappedRange(snapshot, qo.pkg, qo.obj)
+		if err != nil {
+			return nil, err
+		}
+		pr, err := rng.Range()
+		if err != nil {
+			return nil, err
+		}
+		fh, err := snapshot.GetFile(ctx, rng.URI())
+		if err != nil {
+			return nil, err
+		}
+		impls, err := implementations(ctx, snapshot, fh, pr.Start)
+		if errors.Is(err, ErrNotAType) {
+			continue // a function
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, impl := range impls {
+			pos := fset.Position(impl.obj.Pos())
+			if seen[pos] {
+				continue
+			}
+			seen[pos] = true
+			related = append(related, impl)
+			queue = append(queue, impl)
+		}
+	}
+	return related, nil
+}
+
+// A sigChanger computes the edits of a change of signature.
+type sigChanger struct {
+	fset   *token.FileSet
+	sig    *types.Signature // the old signature
+	params []command.ChangeSignatureParam
+	files  map[span.URI]*sigFile
+}
+
+// A sigFile holds the edits of a change of signature in a file. Since the
+// arguments of a call may contain other calls to update, the replacement of
+// a call refers to the text of its arguments, which is computed when all the
+// edits are known.
+type sigFile struct {
+	tok   *token.File
+	src   []byte
+	seen  map[int]bool // the offsets of the updated references
+	edits []*sigEdit
+}
+
+// A sigEdit replaces the text of a file between the offsets start and end
+// with the concatenation of pieces.
+type sigEdit struct {
+	start, end int
+	pieces     []sigPiece
+}
+
+// A sigPiece is a literal text, or the text of the file between start and
+// end, with its edits applied, if text is empty.
+type sigPiece struct {
+	text       string
+	start, end int
+}
+
+func (c *sigChanger) file(pgf *ParsedGoFile) *sigFile {
+	f, ok := c.files[pgf.URI]
+	if !ok {
+		f = &sigFile{tok: pgf.Tok, src: pgf.Src, seen: make(map[int]bool)}
+		c.files[pgf.URI] = f
+	}
+	return f
+}
+
+// update records the edits of the reference ref to the changed function,
+// which is either a declaration or a call.
+func (c *sigChanger) update(ref *ReferenceInfo) error {
+	pgf, err := ref.pkg.File(ref.URI())
+	if err != nil {
+		return err
+	}
+	f := c.file(pgf)
+	offset := pgf.Tok.Offset(ref.ident.Pos())
+	if f.seen[offset] {
+		return nil
+	}
+	f.seen[offset] = true
+	path, _ := astutil.PathEnclosingInterval(pgf.File, ref.ident.Pos(), ref.ident.End())
+	if len(path) < 2 {
+		return errors.Errorf("no syntax for %s", ref.Name)
+	}
+	info := ref.pkg.GetTypesInfo()
+	switch parent := path[1].(type) {
+	case *ast.FuncDecl:
+		if parent.Name == ref.ident {
+			return c.updateDecl(f, pgf, info, parent.Type, parent.Body, parent.Recv)
+		}
+	case *ast.Field:
+		if ftype, ok := parent.Type.(*ast.FuncType); ok && len(path) > 3 {
+			if _, ok := path[3].(*ast.InterfaceType); ok {
+				return c.updateDecl(f, pgf, info, ftype, nil, nil)
+			}
+		}
+	}
+	return c.updateCall(f, pgf, info, path)
+}
+
+// updateDecl records the edit of the parameters of the declaration of a
+// function, with the given body and receiver, if any.
+func (c *sigChanger) updateDecl(f *sigFile, pgf *ParsedGoFile, info *types.Info, ftype *ast.FuncType, body *ast.BlockStmt, recv *ast.FieldList) error {
+	type param struct {
+		name, typ string
+		obj       types.Object
+	}
+	var old []param
+	named := c.sig.Params().Len() == 0
+	for _, field := range ftype.Params.List {
+		typ := string(f.src[pgf.Tok.Offset(field.Type.Pos()):pgf.Tok.Offset(field.Type.End())])
+		if len(field.Names) == 0 {
+			old = append(old, param{typ: typ})
+			continue
+		}
+		named = true
+		for _, name := range field.Names {
+			old = append(old, param{name: name.Name, typ: typ, obj: info.Defs[name]})
+		}
+	}
+	if len(old) != c.sig.Params().Len() {
+		return errors.Errorf("unexpected parameters at %s", c.fset.Position(ftype.Pos()))
+	}
+
+	var params []param
+	kept := make(map[int]bool)
+	for _, p := range c.params {
+		if p.OldIndex >= 0 {
+			params = append(params, old[p.OldIndex])
+			kept[p.OldIndex] = true
+			continue
+		}
+		name := p.Name
+		if !named {
+			name = ""
+		}
+		params = append(params, param{name: name, typ: p.Type})
+	}
+	if body != nil {
+		for i, p := range old {
+			if !kept[i] && p.obj != nil && usesObject(info, body, p.obj) {
+				return errors.Errorf("parameter %s is used at %s", p.name, c.fset.Position(ftype.Pos()))
+			}
+		}
+	}
+
+	// The names of the new parameters must not conflict with the other
+	// declarations of the signature, or the free identifiers of the body.
+	names := make(map[string]bool)
+	for _, list := range []*ast.FieldList{recv, ftype.Results} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				names[name.Name] = true
+			}
+		}
+	}
+	for _, p := range params {
+		if p.name == "" || p.name == "_" {
+			continue
+		}
+		if names[p.name] {
+			return errors.Errorf("parameter %s conflicts with another declaration at %s", p.name, c.fset.Position(ftype.Pos()))
+		}
+		names[p.name] = true
+		if p.obj == nil && body != nil && capturesIdent(info, body, p.name) {
+			return errors.Errorf("parameter %s would shadow another declaration at %s", p.name, c.fset.Position(ftype.Pos()))
+		}
+	}
+
+	var b strings.Builder
+	for i, p := range params {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if p.name == "" {
+			b.WriteString(p.typ)
+			continue
+		}
+		b.WriteString(p.name)
+		if i == len(params)-1 || params[i+1].name == "" || params[i+1].typ != p.typ {
+			b.WriteString(" " + p.typ)
+		}
+	}
+	f.edits = append(f.edits, &sigEdit{
+		start:  pgf.Tok.Offset(ftype.Params.Opening) + 1,
+		end:    pgf.Tok.Offset(ftype.Params.Closing),
+		pieces: []sigPiece{{text: b.String()}},
+	})
+	return nil
+}
+
+// usesObject reports whether obj is used in n.
+func usesObject(info *types.Info, n ast.Node, obj types.Object) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && info.Uses[id] == obj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// capturesIdent reports whether a declaration of name in the scope enclosing
+// body would capture a free identifier of body.
+func capturesIdent(info *types.Info, body *ast.BlockStmt, name string) bool {
+	found := false
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.Ident:
+			obj := info.Uses[n]
+			if n.Name != name || obj == nil || body.Pos() <= obj.Pos() && obj.Pos() < body.End() {
+				break
+			}
+			if v, ok := obj.(*types.Var); ok && v.IsField() {
+				break
+			}
+			found = true
+		}
+		return !found
+	}
+	ast.Inspect(body, visit)
+	return found
+}
+
+// updateCall records the edit of the arguments of the call to the changed
+// function whose name is path[0].
+func (c *sigChanger) updateCall(f *sigFile, pgf *ParsedGoFile, info *types.Info, path []ast.Node) error {
+	id := path[0].(*ast.Ident)
+	var fun ast.Expr = id
+	i, recv := 1, 0
+	if sel, ok := path[1].(*ast.SelectorExpr); ok && sel.Sel == id {
+		fun, i = sel, 2
+		if s := info.Selections[sel]; s != nil && s.Kind() == types.MethodExpr {
+			recv = 1
+		}
+	}
+	for ; i < len(path); i++ {
+		paren, ok := path[i].(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = paren
+	}
+	var call *ast.CallExpr
+	if i < len(path) {
+		call, _ = path[i].(*ast.CallExpr)
+	}
+	if call == nil || call.Fun != fun {
+		return errors.Errorf("%s is used as a value at %s", id.Name, c.fset.Position(id.Pos()))
+	}
+	if len(call.Args) < recv {
+		return errors.Errorf("missing receiver at %s", c.fset.Position(call.Pos()))
+	}
+	args := call.Args[recv:]
+	if len(args) == 1 {
+		if tuple, ok := info.TypeOf(args[0]).(*types.Tuple); ok && tuple.Len() != 1 {
+			return errors.Errorf("cannot change the arguments of the multi-valued call at %s", c.fset.Position(args[0].Pos()))
+		}
+	}
+
+	// Group the arguments by parameter.
+	n := c.sig.Params().Len()
+	groups := make([][]ast.Expr, n)
+	for j := 0; j < n && j < len(args); j++ {
+		if c.sig.Variadic() && j == n-1 && !call.Ellipsis.IsValid() {
+			groups[j] = args[j:]
+		} else {
+			groups[j] = args[j : j+1]
+		}
+	}
+	pure := func(group []ast.Expr) bool {
+		for _, arg := range group {
+			if !isPure(info, arg) {
+				return false
+			}
+		}
+		return true
+	}
+	kept := make(map[int]bool)
+	ordered, last := true, -1
+	for _, p := range c.params {
+		if p.OldIndex >= 0 {
+			kept[p.OldIndex] = true
+			ordered = ordered && p.OldIndex > last
+			last = p.OldIndex
+		}
+	}
+	for j, group := range groups {
+		if (!kept[j] || !ordered) && !pure(group) {
+			return errors.Errorf("cannot remove or reorder the arguments with side effects at %s", c.fset.Position(call.Pos()))
+		}
+	}
+
+	var pieces []sigPiece
+	add := func(p sigPiece) {
+		if len(pieces) > 0 {
+			pieces = append(pieces, sigPiece{text: ", "})
+		}
+		pieces = append(pieces, p)
+	}
+	if recv == 1 {
+		add(sigPiece{start: pgf.Tok.Offset(call.Args[0].Pos()), end: pgf.Tok.Offset(call.Args[0].End())})
+	}
+	for _, p := range c.params {
+		if p.OldIndex < 0 {
+			add(sigPiece{text: p.Value})
+			continue
+		}
+		if group := groups[p.OldIndex]; len(group) > 0 {
+			add(sigPiece{start: pgf.Tok.Offset(group[0].Pos()), end: pgf.Tok.Offset(group[len(group)-1].End())})
+		}
+	}
+	if call.Ellipsis.IsValid() {
+		pieces = append(pieces, sigPiece{text: "..."})
+	}
+	f.edits = append(f.edits, &sigEdit{
+		start:  pgf.Tok.Offset(call.Lparen) + 1,
+		end:    pgf.Tok.Offset(call.Rparen),
+		pieces: pieces,
+	})
+	return nil
+}
+
+// fix returns the edits of all files.
+func (c *sigChanger) fix() *analysis.SuggestedFix {
+	fix := &analysis.SuggestedFix{}
+	for _, f := range c.files {
+		sort.Slice(f.edits, func(i, j int) bool {
+			return f.edits[i].start < f.edits[j].start
+		})
+		for _, e := range f.outermost(0, len(f.src)) {
+			fix.TextEdits = append(fix.TextEdits, analysis.TextEdit{
+				Pos:     f.tok.Pos(e.start),
+				End:     f.tok.Pos(e.end),
+				NewText: []byte(f.replacement(e)),
+			})
+		}
+	}
+	return fix
+}
+
+// outermost returns the edits between the offsets start and end that are
+// not contained in other edits.
+func (f *sigFile) outermost(start, end int) []*sigEdit {
+	var edits []*sigEdit
+	last := start
+	for _, e := range f.edits {
+		if e.start >= last && e.end <= end {
+			edits = append(edits, e)
+			last = e.end
+		}
+	}
+	return edits
+}
+
+// replacement returns the text replacing the edit e.
+func (f *sigFile) replacement(e *sigEdit) string {
+	var b strings.Builder
+	for _, p := range e.pieces {
+		if p.text != "" {
+			b.WriteString(p.text)
+			continue
+		}
+		offset := p.start
+		for _, inner := range f.outermost(p.start, p.end) {
+			b.Write(f.src[offset:inner.start])
+			b.WriteString(f.replacement(inner))
+			offset = inner.end
+		}
+		b.Write(f.src[offset:p.end])
+	}
+	return b.String()
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	errors "golang.org/x/xerrors"
+)
+
+// colorTypes maps the names of the image/color types recognized by
+// DocumentColor to the maximum value of their channels.
+var colorTypes = map[string]float64{
+	"RGBA":    math.MaxUint8,
+	"NRGBA":   math.MaxUint8,
+	"RGBA64":  math.MaxUint16,
+	"NRGBA64": math.MaxUint16,
+}
+
+// hexColorRx matches the strings recognized as hexadecimal colors. Three digit
+// colors are not recognized, as they are easily confused with other strings,
+// such as "#add".
+var hexColorRx = regexp.MustCompile(`^#([0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// colorLit is a literal that denotes a color.
+type colorLit struct {
+	node  ast.Expr // an *ast.CompositeLit or *ast.BasicLit
+	typ   string   // the name of the image/color type, or "" for a hexadecimal string
+	color protocol.Color
+}
+
+// DocumentColor returns the colors denoted by the image/color composite
+// literals with constant channels and the hexadecimal color strings of fh.
+func DocumentColor(ctx context.Context, snapshot Snapshot, fh FileHandle) ([]protocol.ColorInformation, error) {
+	ctx, done := event.Start(ctx, "source.DocumentColor")
+	defer done()
+
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, err
+	}
+	var infos []protocol.ColorInformation
+	for _, lit := range colorLiterals(pkg, pgf) {
+		rng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, lit.node.Pos(), lit.node.End()).Range()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, protocol.ColorInformation{Range: rng, Color: lit.color})
+	}
+	return infos, nil
+}
+
+// ColorPresentation returns the edit that replaces the color literal at rng
+// with a literal of the same form denoting color.
+func ColorPresentation(ctx context.Context, snapshot Snapshot, fh FileHandle, rng protocol.Range, color protocol.Color) ([]protocol.ColorPresentation, error) {
+	ctx, done := event.Start(ctx, "source.ColorPresentation")
+	defer done()
+
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, err
+	}
+	for _, lit := range colorLiterals(pkg, pgf) {
+		litRng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, lit.node.Pos(), lit.node.End()).Range()
+		if err != nil {
+			return nil, err
+		}
+		if litRng != rng {
+			continue
+		}
+		var text string
+		if lit.typ == "" {
+			text = formatHexColor(color)
+		} else {
+			// Keep the type of the literal as spelled, or elided as in
+			// []color.RGBA{{255, 0, 0, 255}}.
+			var typExpr string
+			if typ := lit.node.(*ast.CompositeLit).Type; typ != nil {
+				start, end := snapshot.FileSet().Position(typ.Pos()).Offset, snapshot.FileSet().Position(typ.End()).Offset
+				typExpr = string(pgf.Src[start:end])
+			}
+			text = formatColorLit(typExpr, lit.typ, color)
+		}
+		return []protocol.ColorPresentation{{
+			Label:    text,
+			TextEdit: protocol.TextEdit{Range: rng, NewText: text},
+		}}, nil
+	}
+	return nil, errors.Errorf("no color literal at %v", rng)
+}
+
+// colorLiterals returns the color literals of pgf.
+func colorLiterals(pkg Package, pgf *ParsedGoFile) []colorLit {
+	info := pkg.GetTypesInfo()
+	var lits []colorLit
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			typ := colorType(info.TypeOf(n))
+			if typ == "" {
+				return true
+			}
+			if color, ok := compositeColor(info, n, colorTypes[typ], !strings.HasPrefix(typ, "N")); ok {
+				lits = append(lits, colorLit{node: n, typ: typ, color: color})
+			}
+			return false
+		case *ast.BasicLit:
+			if n.Kind != token.STRING {
+				return false
+			}
+			s, err := strconv.Unquote(n.Value)
+			if err != nil || !hexColorRx.MatchString(s) {
+				return false
+			}
+			lits = append(lits, colorLit{node: n, color: hexColor(s[1:])})
+		}
+		return true
+	})
+	return lits
+}
+
+// colorType returns the name of the image/color type of T, or "" if T is not
+// a recognized color type.
+func colorType(T types.Type) string {
+	named, ok := T.(*types.Named)
+	if !ok {
+		return ""
+	}
+	obj := named.Obj()
+	if obj.Pkg() == nil || obj.Pkg().Path() != "image/color" {
+		return ""
+	}
+	if _, ok := colorTypes[obj.Name()]; !ok {
+		return ""
+	}
+	return obj.Name()
+}
+
+// compositeColor returns the color of a composite literal whose channels are
+// all constants. Premultiplied channels are converted to the straight alpha
+// of protocol.Color.
+func compositeColor(info *types.Info, lit *ast.CompositeLit, max float64, premultiplied bool) (protocol.Color, bool) {
+	channels := map[string]float64{"A": 0}
+	names := []string{"R", "G", "B", "A"}
+	if len(lit.Elts) > len(names) {
+		return protocol.Color{}, false
+	}
+	for i, elt := range lit.Elts {
+		name := names[i]
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				return protocol.Color{}, false
+			}
+			name, elt = key.Name, kv.Value
+		}
+		tv, ok := info.Types[elt]
+		if !ok || tv.Value == nil {
+			return protocol.Color{}, false
+		}
+		v, ok := constant.Float64Val(constant.ToFloat(tv.Value))
+		if !ok {
+			return protocol.Color{}, false
+		}
+		channels[name] = v
+	}
+	color := protocol.Color{Alpha: channels["A"] / max}
+	r, g, b := channels["R"], channels["G"], channels["B"]
+	if premultiplied {
+		if channels["A"] == 0 {
+			return color, true
+		}
+		max = channels["A"]
+	}
+	color.Red, color.Green, color.Blue = r/max, g/max, b/max
+	return color, true
+}
+
+// hexColor returns the color denoted by the hexadecimal digits s, in the
+// form rrggbb or rrggbbaa.
+func hexColor(s string) protocol.Color {
+	channel := func(i int) float64 {
+		v, _ := strconv.ParseUint(s[2*i:2*i+2], 16, 8)
+		return float64(v) / math.MaxUint8
+	}
+	color := protocol.Color{Red: channel(0), Green: channel(1), Blue: channel(2), Alpha: 1}
+	if len(s) == 8 {
+		color.Alpha = channel(3)
+	}
+	return color
+}
+
+// formatHexColor formats color as a quoted hexadecimal string, omitting the
+// alpha channel of opaque colors.
+func formatHexColor(color protocol.Color) string {
+	r, g, b, a := scaleColor(color, math.MaxUint8, false)
+	if a == math.MaxUint8 {
+		return fmt.Sprintf(`"#%02x%02x%02x"`, r, g, b)
+	}
+	return fmt.Sprintf(`"#%02x%02x%02x%02x"`, r, g, b, a)
+}
+
+// formatColorLit formats color as a keyed composite literal of the image/color
+// type typ, spelled typExpr. The type is elided if typExpr is empty.
+func formatColorLit(typExpr, typ string, color protocol.Color) string {
+	max := colorTypes[typ]
+	r, g, b, a := scaleColor(color, max, !strings.HasPrefix(typ, "N"))
+	digits := 2
+	if max == math.MaxUint16 {
+		digits = 4
+	}
+	return fmt.Sprintf("%s{R: 0x%0*x, G: 0x%0*x, B: 0x%0*x, A: 0x%0*x}", typExpr, digits, r, digits, g, digits, b, digits, a)
+}
+
+// scaleColor returns the channels of color scaled to [0, max], premultiplying
+// the color channels by alpha if requested.
+func scaleColor(color protocol.Color, max float64, premultiplied bool) (r, g, b, a uint64) {
+	scale := func(v float64) uint64 {
+		return uint64(math.Round(math.Max(0, math.Min(1, v)) * max))
+	}
+	alpha := 1.0
+	if premultiplied {
+		alpha = math.Max(0, math.Min(1, color.Alpha))
+	}
+	return scale(color.Red * alpha), scale(color.Green * alpha), scale(color.Blue * alpha), scale(color.Alpha)
+}
@@ -6,7 +6,11 @@ package source
 
 import (
 	"context"
+	"go/ast"
+	"go/token"
 
+	"github.com/kent0106/gotools/internal/analysisinternal"
+	"github.com/kent0106/gotools/internal/event"
 	"github.com/kent0106/gotools/internal/lsp/protocol"
 	"github.com/kent0106/gotools/internal/span"
 )
@@ -50,6 +54,7 @@ func Analyze(ctx context.Context, snapshot Snapshot, pkg Package, includeConveni
 	if err != nil {
 		return nil, err
 	}
+	analysisDiagnostics = applyIgnores(ctx, snapshot, pkg, analyzers, analysisDiagnostics)
 
 	reports := map[span.URI][]*Diagnostic{}
 	// Report diagnostics and errors from root analyzers.
@@ -59,6 +64,57 @@ func Analyze(ctx context.Context, snapshot Snapshot, pkg Package, includeConveni
 	return reports, nil
 }
 
+// applyIgnores removes from the diagnostics of the analyzers those
+// suppressed by the //lint:ignore directives of the package, and adds
+// diagnostics for the unused and malformed directives.
+func applyIgnores(ctx context.Context, snapshot Snapshot, pkg Package, analyzers []*Analyzer, diagnostics []*Diagnostic) []*Diagnostic {
+	fset := snapshot.FileSet()
+	var files []*ast.File
+	for _, pgf := range pkg.CompiledGoFiles() {
+		files = append(files, pgf.File)
+	}
+	ig := analysisinternal.ParseIgnores(fset, files)
+
+	var result []*Diagnostic
+	for _, diag := range diagnostics {
+		if diag.Analyzer != nil {
+			posn := token.Position{Filename: diag.URI.Filename(), Line: int(diag.Range.Start.Line) + 1}
+			if ig.Ignored(diag.Analyzer.Analyzer.Name, posn) {
+				continue
+			}
+		}
+		result = append(result, diag)
+	}
+
+	ran := make(map[string]bool)
+	for _, a := range analyzers {
+		if a.IsEnabled(snapshot.View()) {
+			ran[a.Analyzer.Name] = true
+		}
+	}
+	for _, p := range ig.Problems(func(name string) bool { return ran[name] }) {
+		for _, pgf := range pkg.CompiledGoFiles() {
+			if pgf.Tok != fset.File(p.Pos) {
+				continue
+			}
+			rng, err := NewMappedRange(fset, pgf.Mapper, p.Pos, p.End).Range()
+			if err != nil {
+				event.Error(ctx, "computing the range of a //lint:ignore directive", err)
+				break
+			}
+			result = append(result, &Diagnostic{
+				URI:      pgf.URI,
+				Range:    rng,
+				Severity: protocol.SeverityWarning,
+				Source:   analysisinternal.IgnoreAnalyzerName,
+				Message:  p.Message,
+			})
+			break
+		}
+	}
+	return result
+}
+
 func FileDiagnostics(ctx context.Context, snapshot Snapshot, uri span.URI) (VersionedFileIdentity, []*Diagnostic, error) {
 	fh, err := snapshot.GetVersionedFile(ctx, uri)
 	if err != nil {
@@ -0,0 +1,433 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/imports"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+	"github.com/kent0106/gotools/internal/typeparams"
+)
+
+// A switchFill holds the cases missing from a switch statement.
+type switchFill struct {
+	stmt     ast.Stmt                  // the *ast.SwitchStmt or *ast.TypeSwitchStmt
+	body     *ast.BlockStmt            // the body of stmt
+	typ      types.Type                // the type of the tag, or of the operand of a type switch
+	cases    []string                  // the expressions of the missing cases
+	qf       types.Qualifier           // the qualifier of the file containing stmt
+	pkg      *types.Package            // the package containing stmt
+	scope    *types.Scope              // the innermost scope containing stmt
+	imported map[string]*types.PkgName // the imports of the file, keyed by path
+	added    []*types.Package          // the packages to import for the cases
+}
+
+// fillSwitch inserts the missing cases into the switch statement at the
+// given range.
+func fillSwitch(ctx context.Context, snapshot Snapshot, fh VersionedFileHandle, pRng protocol.Range) (*analysis.SuggestedFix, error) {
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
+	if err != nil {
+		return nil, err
+	}
+	sf, err := findSwitchFill(ctx, snapshot, pkg, pgf, rng, true)
+	if err != nil {
+		return nil, fmt.Errorf("fillSwitch: %v", err)
+	}
+
+	// The cases are inserted before the default case, if any, so that it
+	// stays last, or before the closing brace.
+	pos := sf.body.Rbrace
+	for _, clause := range sf.body.List {
+		if isDefaultClause(clause) {
+			pos = clause.Pos()
+			break
+		}
+	}
+	tok := pgf.Tok
+	line := string(pgf.Src[tok.Offset(tok.LineStart(tok.Line(sf.stmt.Pos()))):])
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	insert := tok.LineStart(tok.Line(pos))
+	// The default case or the closing brace may follow other code on its
+	// line, as in "switch x {}".
+	inline := strings.TrimSpace(string(pgf.Src[tok.Offset(insert):tok.Offset(pos)])) != ""
+	var b strings.Builder
+	if inline {
+		insert = pos
+		b.WriteString("\n")
+	}
+	for _, c := range sf.cases {
+		fmt.Fprintf(&b, "%scase %s:\n", indent, c)
+	}
+	if inline {
+		b.WriteString(indent)
+	}
+
+	var edits []analysis.TextEdit
+	if len(sf.added) > 0 {
+		var imps []imports.ImportInfo
+		for _, p := range sf.added {
+			imp := imports.ImportInfo{ImportPath: p.Path()}
+			if imports.ImportPathToAssumedName(p.Path()) != p.Name() {
+				imp.Name = p.Name()
+			}
+			imps = append(imps, imp)
+		}
+		importEdits, err := addImportEdits(snapshot, pgf, imps)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range importEdits {
+			rng, err := pgf.Mapper.RangeToSpanRange(e.Range)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, analysis.TextEdit{Pos: rng.Start, End: rng.End, NewText: []byte(e.NewText)})
+		}
+	}
+	edits = append(edits, analysis.TextEdit{Pos: insert, End: insert, NewText: []byte(b.String())})
+	return &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// CanFillSwitch reports whether cases are missing from the switch statement
+// at the given range, and if so returns the title of the fix adding them.
+// As it is called for every code action request, the implementations of the
+// interface of a type switch are only looked for in the current package and
+// in the packages imported by the file; the other workspace packages are
+// only searched when the fix is applied.
+func CanFillSwitch(ctx context.Context, snapshot Snapshot, pkg Package, pgf *ParsedGoFile, rng span.Range) (string, bool) {
+	sf, err := findSwitchFill(ctx, snapshot, pkg, pgf, rng, false)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("Add cases for %s", types.TypeString(sf.typ, sf.qf)), true
+}
+
+// findSwitchFill returns the cases missing from the switch statement whose
+// header contains rng. The missing cases of a switch on a value of a named
+// basic type are the constants of that type. The missing cases of a type
+// switch on an interface are the named types implementing the interface,
+// declared in the current package or in packages that the file imports, and,
+// if workspace is set, in the workspace packages that the file may import.
+func findSwitchFill(ctx context.Context, snapshot Snapshot, pkg Package, pgf *ParsedGoFile, rng span.Range, workspace bool) (*switchFill, error) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.End)
+	sf := &switchFill{
+		qf:       Qualifier(pgf.File, pkg.GetTypes(), pkg.GetTypesInfo()),
+		pkg:      pkg.GetTypes(),
+		imported: make(map[string]*types.PkgName),
+	}
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.SwitchStmt:
+			sf.stmt, sf.body = n, n.Body
+		case *ast.TypeSwitchStmt:
+			sf.stmt, sf.body = n, n.Body
+		default:
+			continue
+		}
+		break
+	}
+	if sf.stmt == nil || rng.Start > sf.body.Lbrace {
+		return nil, fmt.Errorf("no switch statement selected")
+	}
+
+	sf.scope = sf.pkg.Scope().Innermost(sf.stmt.Pos())
+	if sf.scope == nil {
+		return nil, fmt.Errorf("no scope for the switch statement")
+	}
+	for _, imp := range pgf.File.Imports {
+		var obj types.Object
+		if imp.Name != nil {
+			obj = pkg.GetTypesInfo().Defs[imp.Name]
+		} else {
+			obj = pkg.GetTypesInfo().Implicits[imp]
+		}
+		if pkgName, ok := obj.(*types.PkgName); ok {
+			sf.imported[pkgName.Imported().Path()] = pkgName
+		}
+	}
+
+	var err error
+	switch stmt := sf.stmt.(type) {
+	case *ast.SwitchStmt:
+		err = sf.enumCases(pkg, stmt)
+	case *ast.TypeSwitchStmt:
+		err = sf.typeCases(ctx, snapshot, pkg, stmt, workspace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sf.cases) == 0 {
+		return nil, fmt.Errorf("no case is missing")
+	}
+	return sf, nil
+}
+
+// enumCases sets the missing cases of a switch on a value of a named basic
+// type to the constants of that type with distinct values.
+func (sf *switchFill) enumCases(pkg Package, stmt *ast.SwitchStmt) error {
+	if stmt.Tag == nil {
+		return fmt.Errorf("the switch has no tag")
+	}
+	info := pkg.GetTypesInfo()
+	named, ok := info.TypeOf(stmt.Tag).(*types.Named)
+	if !ok || typeparams.ForNamed(named).Len() > 0 {
+		return fmt.Errorf("the tag is not of a named type")
+	}
+	if _, ok := named.Underlying().(*types.Basic); !ok {
+		return fmt.Errorf("%s is not a basic type", named.Obj().Name())
+	}
+	sf.typ = named
+	declPkg := named.Obj().Pkg()
+	if declPkg == nil || !sf.canRefer(declPkg) {
+		return fmt.Errorf("the package of %s cannot be referred to", named.Obj().Name())
+	}
+
+	var covered []constant.Value
+	for _, clause := range stmt.Body.List {
+		for _, e := range clause.(*ast.CaseClause).List {
+			if v := info.Types[e].Value; v != nil {
+				covered = append(covered, v)
+			}
+		}
+	}
+	isCovered := func(v constant.Value) bool {
+		for _, w := range covered {
+			if constant.Compare(v, token.EQL, w) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range scopeObjects(declPkg.Scope()) {
+		c, ok := c.(*types.Const)
+		if !ok || !types.Identical(c.Type(), named) || !isAccessible(c, pkg.GetTypes()) {
+			continue
+		}
+		if isCovered(c.Val()) {
+			continue
+		}
+		covered = append(covered, c.Val())
+		sf.use(declPkg)
+		name := c.Name()
+		if q := sf.qf(declPkg); q != "" {
+			name = q + "." + name
+		}
+		sf.cases = append(sf.cases, name)
+	}
+	return nil
+}
+
+// typeCases sets the missing cases of a type switch on a value of an
+// interface type to the named types implementing the interface. The workspace
+// packages that the file does not import are only searched if workspace is
+// set.
+func (sf *switchFill) typeCases(ctx context.Context, snapshot Snapshot, pkg Package, stmt *ast.TypeSwitchStmt, workspace bool) error {
+	var assert *ast.TypeAssertExpr
+	switch s := stmt.Assign.(type) {
+	case *ast.ExprStmt:
+		assert, _ = s.X.(*ast.TypeAssertExpr)
+	case *ast.AssignStmt:
+		if len(s.Rhs) == 1 {
+			assert, _ = s.Rhs[0].(*ast.TypeAssertExpr)
+		}
+	}
+	if assert == nil {
+		return fmt.Errorf("no type switch guard")
+	}
+	info := pkg.GetTypesInfo()
+	typ := info.TypeOf(assert.X)
+	if typ == nil {
+		return fmt.Errorf("no type for the type switch guard")
+	}
+	iface, ok := typ.Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 {
+		return fmt.Errorf("the type switch is not on a non-empty interface")
+	}
+	sf.typ = typ
+
+	var covered []types.Type
+	for _, clause := range stmt.Body.List {
+		for _, e := range clause.(*ast.CaseClause).List {
+			if t := info.TypeOf(e); t != nil {
+				covered = append(covered, t)
+			}
+		}
+	}
+	isCovered := func(t types.Type) bool {
+		for _, u := range covered {
+			if types.Identical(t, u) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Only the types of workspace packages are considered, as the
+	// dependencies may declare many implementations of common interfaces,
+	// such as error. The packages that the file does not import are those
+	// that it may import without creating a cycle.
+	pkgs := []*types.Package{pkg.GetTypes()}
+	if workspace {
+		active, err := snapshot.ActivePackages(ctx)
+		if err != nil {
+			return err
+		}
+		seen := map[string]bool{pkg.PkgPath(): true}
+		memo := make(map[string]bool)
+		for _, p := range active {
+			if p.ForTest() != "" || p.Name() == "main" || seen[p.PkgPath()] {
+				continue
+			}
+			seen[p.PkgPath()] = true
+			if pkgName, ok := sf.imported[p.PkgPath()]; ok {
+				pkgs = append(pkgs, pkgName.Imported())
+			} else if !importsPackage(p, pkg.PkgPath(), memo) {
+				pkgs = append(pkgs, p.GetTypes())
+			}
+		}
+	} else {
+		for _, pkgName := range sf.imported {
+			if pkgName.Imported() != pkg.GetTypes() {
+				pkgs = append(pkgs, pkgName.Imported())
+			}
+		}
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		// The current package comes first.
+		if (pkgs[i] == pkg.GetTypes()) != (pkgs[j] == pkg.GetTypes()) {
+			return pkgs[i] == pkg.GetTypes()
+		}
+		return pkgs[i].Path() < pkgs[j].Path()
+	})
+	for _, p := range pkgs {
+		if !sf.canRefer(p) {
+			continue
+		}
+		for _, obj := range scopeObjects(p.Scope()) {
+			tn, ok := obj.(*types.TypeName)
+			if !ok || tn.IsAlias() || !isAccessible(tn, pkg.GetTypes()) {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || IsInterface(named) || typeparams.ForNamed(named).Len() > 0 {
+				continue
+			}
+			var t types.Type
+			switch {
+			case types.Implements(named, iface):
+				t = named
+			case types.Implements(types.NewPointer(named), iface):
+				t = types.NewPointer(named)
+			default:
+				continue
+			}
+			if isCovered(t) {
+				continue
+			}
+			covered = append(covered, t)
+			sf.use(p)
+			sf.cases = append(sf.cases, types.TypeString(t, sf.qf))
+		}
+	}
+	return nil
+}
+
+// canRefer reports whether the objects of p may be referred to in the switch
+// statement: p is the current package, a package imported by the file, or a
+// package whose import would neither be invalid nor shadowed by another
+// declaration of its name.
+func (sf *switchFill) canRefer(p *types.Package) bool {
+	if p == sf.pkg {
+		return true
+	}
+	if pkgName, ok := sf.imported[p.Path()]; ok {
+		return pkgName.Name() != "_"
+	}
+	if !IsValidImport(sf.pkg.Path(), p.Path()) {
+		return false
+	}
+	for _, q := range sf.added {
+		if q.Path() == p.Path() {
+			return true
+		}
+		if q.Name() == p.Name() {
+			return false
+		}
+	}
+	_, obj := sf.scope.LookupParent(p.Name(), sf.stmt.Pos())
+	return obj == nil
+}
+
+// use records that a case refers to the objects of p, which must be imported
+// if the file does not import it yet.
+func (sf *switchFill) use(p *types.Package) {
+	if p == sf.pkg {
+		return
+	}
+	if _, ok := sf.imported[p.Path()]; ok {
+		return
+	}
+	for _, q := range sf.added {
+		if q.Path() == p.Path() {
+			return
+		}
+	}
+	sf.added = append(sf.added, p)
+}
+
+// importsPackage reports whether pkg imports the package with the given path,
+// directly or indirectly. memo holds the results for the packages visited by
+// previous calls with the same path.
+func importsPackage(pkg Package, path string, memo map[string]bool) bool {
+	if imports, ok := memo[pkg.PkgPath()]; ok {
+		return imports
+	}
+	memo[pkg.PkgPath()] = false
+	for _, imp := range pkg.Imports() {
+		if imp.PkgPath() == path || importsPackage(imp, path, memo) {
+			memo[pkg.PkgPath()] = true
+			return true
+		}
+	}
+	return false
+}
+
+// scopeObjects returns the objects of the scope in the order of their
+// declarations.
+func scopeObjects(scope *types.Scope) []types.Object {
+	var objs []types.Object
+	for _, name := range scope.Names() {
+		objs = append(objs, scope.Lookup(name))
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return objs[i].Pos() < objs[j].Pos()
+	})
+	return objs
+}
+
+// isAccessible reports whether obj may be referred to from pkg.
+func isAccessible(obj types.Object, pkg *types.Package) bool {
+	return obj.Pkg() == pkg || obj.Exported()
+}
+
+func isDefaultClause(stmt ast.Stmt) bool {
+	clause, ok := stmt.(*ast.CaseClause)
+	return ok && clause.List == nil
+}
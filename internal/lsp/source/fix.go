@@ -36,6 +36,15 @@ const (
 	ExtractVariable = "extract_variable"
 	ExtractFunction = "extract_function"
 	ExtractMethod   = "extract_method"
+	InlineCall      = "inline_call"
+	InlineVariable  = "inline_variable"
+	RemoveParameter = "remove_parameter"
+	FillSwitch      = "fill_switch"
+	InvertIf        = "invert_if"
+	IfElseToSwitch  = "if_else_to_switch"
+	SplitIf         = "split_if"
+	JoinIf          = "join_if"
+	EarlyReturn     = "early_return"
 )
 
 // suggestedFixes maps a suggested fix command id to its handler.
@@ -45,6 +54,15 @@ var suggestedFixes = map[string]SuggestedFixFunc{
 	ExtractVariable: singleFile(extractVariable),
 	ExtractFunction: singleFile(extractFunction),
 	ExtractMethod:   singleFile(extractMethod),
+	InlineCall:      inlineCall,
+	InlineVariable:  singleFile(inlineVariable),
+	RemoveParameter: removeUnusedParameter,
+	FillSwitch:      fillSwitch,
+	InvertIf:        singleFile(invertIf),
+	IfElseToSwitch:  singleFile(ifElseToSwitch),
+	SplitIf:         singleFile(splitIf),
+	JoinIf:          singleFile(joinIf),
+	EarlyReturn:     singleFile(earlyReturn),
 }
 
 // singleFile calls analyzers that expect inputs for a single file
@@ -80,9 +98,15 @@ func ApplyFix(ctx context.Context, fix string, snapshot Snapshot, fh VersionedFi
 	if suggestion == nil {
 		return nil, nil
 	}
+	return suggestedFixEdits(ctx, snapshot, suggestion)
+}
+
+// suggestedFixEdits converts the edits of fix, which may span several files,
+// to document edits.
+func suggestedFixEdits(ctx context.Context, snapshot Snapshot, fix *analysis.SuggestedFix) ([]protocol.TextDocumentEdit, error) {
 	fset := snapshot.FileSet()
 	editsPerFile := map[span.URI]*protocol.TextDocumentEdit{}
-	for _, edit := range suggestion.TextEdits {
+	for _, edit := range fix.TextEdits {
 		spn, err := span.NewRange(fset, edit.Pos, edit.End).Span()
 		if err != nil {
 			return nil, err
@@ -16,12 +16,14 @@ import (
 	"strings"
 	"text/scanner"
 
+	"github.com/kent0106/gotools/go/ast/astutil"
 	"github.com/kent0106/gotools/internal/event"
 	"github.com/kent0106/gotools/internal/imports"
 	"github.com/kent0106/gotools/internal/lsp/diff"
 	"github.com/kent0106/gotools/internal/lsp/lsppos"
 	"github.com/kent0106/gotools/internal/lsp/protocol"
 	"github.com/kent0106/gotools/internal/span"
+	errors "golang.org/x/xerrors"
 )
 
 // Format formats a file with a given range.
@@ -68,6 +70,160 @@ func Format(ctx context.Context, snapshot Snapshot, fh FileHandle) ([]protocol.T
 	return computeTextEdits(ctx, snapshot, pgf, formatted)
 }
 
+// FormatRange formats the lines of a file spanned by rng. The selected
+// lines are formatted as a self-contained list of declarations or
+// statements, so that the rest of the file is left untouched. An error is
+// returned if the selection cannot be parsed in isolation.
+func FormatRange(ctx context.Context, snapshot Snapshot, fh FileHandle, rng protocol.Range) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.FormatRange")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	spn, err := pgf.Mapper.RangeSpan(rng)
+	if err != nil {
+		return nil, err
+	}
+	start, end := lineBounds(pgf.Src, spn.Start().Offset(), spn.End().Offset())
+	edits, err := formatLines(ctx, snapshot, pgf, start, end)
+	if err != nil {
+		return nil, errors.Errorf("selection cannot be formatted in isolation: %w", err)
+	}
+	return edits, nil
+}
+
+// FormatOnType formats the code that was just completed by typing ch at
+// pos. When ch is "}", the statement or declaration closed by the brace is
+// formatted; when ch is a newline, the preceding line is formatted if it
+// consists of complete statements or declarations. Since the user is in the
+// middle of typing, code that cannot be formatted yields no edits rather
+// than an error.
+func FormatOnType(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position, ch string) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.FormatOnType")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	spn, err := pgf.Mapper.PointSpan(pos)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := spn.Range(pgf.Mapper.Converter)
+	if err != nil {
+		return nil, err
+	}
+	tok := snapshot.FileSet().File(pgf.File.Pos())
+	if tok == nil {
+		return nil, errors.Errorf("no file for %s", fh.URI())
+	}
+	var node ast.Node
+	switch ch {
+	case "}":
+		if offset := tok.Offset(rng.Start); offset == 0 || pgf.Src[offset-1] != '}' {
+			return nil, nil
+		}
+		node = closedByBrace(pgf.File, rng.Start-1)
+	case "\n":
+		line := tok.Line(rng.Start)
+		if line <= 1 {
+			return nil, nil
+		}
+		node = completedLine(tok, pgf.File, pgf.Src, line-1)
+	}
+	if node == nil {
+		return nil, nil
+	}
+	start, end := lineBounds(pgf.Src, tok.Offset(node.Pos()), tok.Offset(node.End()))
+	edits, err := formatLines(ctx, snapshot, pgf, start, end)
+	if err != nil {
+		return nil, nil
+	}
+	return edits, nil
+}
+
+// closedByBrace returns the outermost statement or declaration that ends
+// with the closing brace at rbrace, or nil if there is none.
+func closedByBrace(f *ast.File, rbrace token.Pos) ast.Node {
+	path, _ := astutil.PathEnclosingInterval(f, rbrace, rbrace+1)
+	var result ast.Node
+	for _, n := range path {
+		if n.End() != rbrace+1 {
+			break
+		}
+		switch n.(type) {
+		case ast.Stmt, ast.Decl:
+			result = n
+		}
+	}
+	return result
+}
+
+// completedLine returns the outermost statement or declaration that begins
+// at the start of the given line of a file and ends on the same line, or
+// nil if the line does not start with a complete statement or declaration.
+func completedLine(tok *token.File, f *ast.File, src []byte, line int) ast.Node {
+	start := tok.Offset(tok.LineStart(line))
+	for start < len(src) && (src[start] == ' ' || src[start] == '\t') {
+		start++
+	}
+	if start >= len(src) || src[start] == '\n' {
+		return nil
+	}
+	pos := tok.Pos(start)
+	path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+	var result ast.Node
+	for _, n := range path {
+		if n.Pos() != pos {
+			break
+		}
+		switch n.(type) {
+		case ast.Stmt, ast.Decl:
+			if tok.Line(n.End()) == line {
+				result = n
+			}
+		}
+	}
+	return result
+}
+
+// formatLines formats src[start:end] of pgf, which must consist of whole
+// lines, as a list of declarations or statements, and returns the edits
+// that replace the original text with the formatted text.
+func formatLines(ctx context.Context, snapshot Snapshot, pgf *ParsedGoFile, start, end int) ([]protocol.TextEdit, error) {
+	selected := pgf.Src[start:end]
+	if len(bytes.TrimSpace(selected)) == 0 {
+		return nil, nil
+	}
+	// format.Source accepts partial source files consisting of declarations
+	// or statements, and preserves the indentation of the first line.
+	formatted, err := format.Source(selected)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Write(pgf.Src[:start])
+	buf.Write(formatted)
+	buf.Write(pgf.Src[end:])
+	return computeTextEdits(ctx, snapshot, pgf, buf.String())
+}
+
+// lineBounds expands the byte range [start, end) of src to cover whole
+// lines, including the trailing newline of the last line.
+func lineBounds(src []byte, start, end int) (int, int) {
+	start = bytes.LastIndexByte(src[:start], '\n') + 1
+	if end > start && src[end-1] == '\n' {
+		return start, end
+	}
+	if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+		return start, end + i + 1
+	}
+	return start, len(src)
+}
+
 func formatSource(ctx context.Context, fh FileHandle) ([]byte, error) {
 	_, done := event.Start(ctx, "source.formatSource")
 	defer done()
@@ -105,6 +261,44 @@ func AllImportsFixes(ctx context.Context, snapshot Snapshot, fh FileHandle) (all
 	return allFixEdits, editsPerFix, nil
 }
 
+// AllImportFixes returns the fixes to the imports of the file, without
+// computing their edits. See ImportFixesEdits.
+func AllImportFixes(ctx context.Context, snapshot Snapshot, fh FileHandle) (fixes []*imports.ImportFix, err error) {
+	ctx, done := event.Start(ctx, "source.AllImportFixes")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshot.RunProcessEnvFunc(ctx, func(opts *imports.Options) error {
+		fixes, err = imports.FixImports(pgf.URI.Filename(), pgf.Src, opts)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("AllImportFixes: %v", err)
+	}
+	return fixes, nil
+}
+
+// ImportFixesEdits returns the edits applying the given fixes, as returned by
+// AllImportFixes, to the imports of the file.
+func ImportFixesEdits(ctx context.Context, snapshot Snapshot, fh FileHandle, fixes []*imports.ImportFix) (edits []protocol.TextEdit, err error) {
+	ctx, done := event.Start(ctx, "source.ImportFixesEdits")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshot.RunProcessEnvFunc(ctx, func(opts *imports.Options) error {
+		edits, err = computeFixEdits(snapshot, pgf, opts, fixes)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("ImportFixesEdits: %v", err)
+	}
+	return edits, nil
+}
+
 // computeImportEdits computes a set of edits that perform one or all of the
 // necessary import fixes.
 func computeImportEdits(snapshot Snapshot, pgf *ParsedGoFile, options *imports.Options) (allFixEdits []protocol.TextEdit, editsPerFix []*ImportFix, err error) {
@@ -138,7 +332,13 @@ func computeImportEdits(snapshot Snapshot, pgf *ParsedGoFile, options *imports.O
 
 // ComputeOneImportFixEdits returns text edits for a single import fix.
 func ComputeOneImportFixEdits(snapshot Snapshot, pgf *ParsedGoFile, fix *imports.ImportFix) ([]protocol.TextEdit, error) {
-	options := &imports.Options{
+	return computeFixEdits(snapshot, pgf, importFixOptions(snapshot), []*imports.ImportFix{fix})
+}
+
+// importFixOptions returns the options used to apply import fixes chosen
+// by the user, rather than found by goimports.
+func importFixOptions(snapshot Snapshot) *imports.Options {
+	return &imports.Options{
 		LocalPrefix: snapshot.View().Options().Local,
 		// Defaults.
 		AllErrors:  true,
@@ -148,7 +348,6 @@ func ComputeOneImportFixEdits(snapshot Snapshot, pgf *ParsedGoFile, fix *imports
 		TabIndent:  true,
 		TabWidth:   8,
 	}
-	return computeFixEdits(snapshot, pgf, options, []*imports.ImportFix{fix})
 }
 
 func computeFixEdits(snapshot Snapshot, pgf *ParsedGoFile, options *imports.Options, fixes []*imports.ImportFix) ([]protocol.TextEdit, error) {
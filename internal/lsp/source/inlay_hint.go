@@ -0,0 +1,311 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/lsppos"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	errors "golang.org/x/xerrors"
+)
+
+const (
+	ParameterNames             = "parameterNames"
+	AssignVariableTypes        = "assignVariableTypes"
+	RangeVariableTypes         = "rangeVariableTypes"
+	ConstantValues             = "constantValues"
+	CompositeLiteralFieldNames = "compositeLiteralFields"
+)
+
+// maxLabelLength is the number of characters beyond which the labels of hints
+// are truncated.
+const maxLabelLength = 28
+
+// A Hint describes a kind of inlay hint, which may be enabled with the hints
+// setting.
+type Hint struct {
+	Name string
+	Doc  string
+	run  inlayHintFunc
+}
+
+// An inlayHintFunc returns the hints of its kind for node. The children of
+// node are visited separately.
+type inlayHintFunc func(node ast.Node, hc *hintContext) []protocol.InlayHint
+
+// AllInlayHints are the kinds of inlay hints provided by gopls, keyed by the
+// name enabling them in the hints setting.
+var AllInlayHints = map[string]*Hint{
+	ParameterNames: {
+		Name: ParameterNames,
+		Doc:  "Enable/disable inlay hints for parameter names:\n```go\n\tparseInt(/* str: */ \"123\", /* radix: */ 8)\n```",
+		run:  parameterNames,
+	},
+	AssignVariableTypes: {
+		Name: AssignVariableTypes,
+		Doc:  "Enable/disable inlay hints for variable types in assign statements:\n```go\n\ti/* int */, j/* int */ := 0, len(r)-1\n```",
+		run:  assignVariableTypes,
+	},
+	RangeVariableTypes: {
+		Name: RangeVariableTypes,
+		Doc:  "Enable/disable inlay hints for variable types in range statements:\n```go\n\tfor k/* int */, v/* string */ := range []string{} {\n\t\tfmt.Println(k, v)\n\t}\n```",
+		run:  rangeVariableTypes,
+	},
+	ConstantValues: {
+		Name: ConstantValues,
+		Doc:  "Enable/disable inlay hints for constant values:\n```go\n\tconst (\n\t\tKindNone   Kind = iota/* = 0 */\n\t\tKindPrint/*  = 1 */\n\t\tKindPrintf/* = 2 */\n\t\tKindErrorf/* = 3 */\n\t)\n```",
+		run:  constantValues,
+	},
+	CompositeLiteralFieldNames: {
+		Name: CompositeLiteralFieldNames,
+		Doc:  "Enable/disable inlay hints for composite literal field names:\n```go\n\t{/* in: */ \"Hello, world\", /* want: */ \"dlrow ,olleH\"}\n```",
+		run:  compositeLiteralFields,
+	},
+}
+
+// hintContext holds the information shared by the inlay hints of a file.
+type hintContext struct {
+	tok    *token.File
+	mapper *lsppos.Mapper
+	info   *types.Info
+	qf     types.Qualifier
+}
+
+// position returns the protocol position of pos.
+func (hc *hintContext) position(pos token.Pos) protocol.Position {
+	line, char := hc.mapper.Position(hc.tok.Offset(pos))
+	return protocol.Position{Line: uint32(line), Character: uint32(char)}
+}
+
+// InlayHint returns the enabled inlay hints of the file for the nodes
+// overlapping pRng, or of the whole file if pRng is empty.
+func InlayHint(ctx context.Context, snapshot Snapshot, fh FileHandle, pRng protocol.Range) ([]protocol.InlayHint, error) {
+	ctx, done := event.Start(ctx, "source.InlayHint")
+	defer done()
+
+	var enabled []inlayHintFunc
+	for name, on := range snapshot.View().Options().Hints {
+		if h, ok := AllInlayHints[name]; ok && on {
+			enabled = append(enabled, h.run)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, nil
+	}
+
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for InlayHint: %w", err)
+	}
+	hc := &hintContext{
+		tok:    pgf.Tok,
+		mapper: lsppos.NewMapper(pgf.Src),
+		info:   pkg.GetTypesInfo(),
+		qf:     Qualifier(pgf.File, pkg.GetTypes(), pkg.GetTypesInfo()),
+	}
+	start, end := pgf.File.Pos(), pgf.File.End()
+	if pRng.Start != pRng.End {
+		rng, err := pgf.Mapper.RangeToSpanRange(pRng)
+		if err != nil {
+			return nil, err
+		}
+		start, end = rng.Start, rng.End
+	}
+
+	var hints []protocol.InlayHint
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		if n == nil || n.End() < start || n.Pos() > end {
+			return false
+		}
+		for _, run := range enabled {
+			hints = append(hints, run(n, hc)...)
+		}
+		return true
+	})
+	return hints, nil
+}
+
+func parameterNames(node ast.Node, hc *hintContext) []protocol.InlayHint {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	sig, ok := hc.info.TypeOf(call.Fun).(*types.Signature)
+	if !ok {
+		return nil // a conversion or a builtin
+	}
+	params := sig.Params()
+	var hints []protocol.InlayHint
+	for i, arg := range call.Args {
+		if i >= params.Len() {
+			break
+		}
+		param := params.At(i)
+		name := param.Name()
+		if name == "" || name == "_" {
+			continue
+		}
+		// Show the name of the variadic parameter only once.
+		if sig.Variadic() && i == params.Len()-1 && !call.Ellipsis.IsValid() {
+			name += "..."
+		}
+		// The argument already tells what the parameter is.
+		if id, ok := arg.(*ast.Ident); ok && id.Name == param.Name() {
+			continue
+		}
+		hints = append(hints, protocol.InlayHint{
+			Position:     hc.position(arg.Pos()),
+			Label:        buildLabel(name + ":"),
+			Kind:         protocol.Parameter,
+			PaddingRight: true,
+		})
+	}
+	return hints
+}
+
+func assignVariableTypes(node ast.Node, hc *hintContext) []protocol.InlayHint {
+	var names []*ast.Ident
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		if n.Tok != token.DEFINE {
+			return nil
+		}
+		for _, lhs := range n.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok {
+				names = append(names, id)
+			}
+		}
+	case *ast.ValueSpec:
+		if n.Type != nil || len(n.Values) == 0 {
+			return nil
+		}
+		names = n.Names
+	}
+	var hints []protocol.InlayHint
+	for _, id := range names {
+		if hint := variableType(id, hc); hint != nil {
+			hints = append(hints, *hint)
+		}
+	}
+	return hints
+}
+
+func rangeVariableTypes(node ast.Node, hc *hintContext) []protocol.InlayHint {
+	rng, ok := node.(*ast.RangeStmt)
+	if !ok || rng.Tok != token.DEFINE {
+		return nil
+	}
+	var hints []protocol.InlayHint
+	for _, e := range []ast.Expr{rng.Key, rng.Value} {
+		if id, ok := e.(*ast.Ident); ok {
+			if hint := variableType(id, hc); hint != nil {
+				hints = append(hints, *hint)
+			}
+		}
+	}
+	return hints
+}
+
+// variableType returns the hint of the type of the variable declared by id,
+// or nil if id does not declare a variable.
+func variableType(id *ast.Ident, hc *hintContext) *protocol.InlayHint {
+	v, ok := hc.info.Defs[id].(*types.Var)
+	if !ok || id.Name == "_" {
+		return nil
+	}
+	return &protocol.InlayHint{
+		Position:    hc.position(id.End()),
+		Label:       buildLabel(types.TypeString(v.Type(), hc.qf)),
+		Kind:        protocol.Type,
+		PaddingLeft: true,
+	}
+}
+
+func constantValues(node ast.Node, hc *hintContext) []protocol.InlayHint {
+	decl, ok := node.(*ast.GenDecl)
+	if !ok || decl.Tok != token.CONST {
+		return nil
+	}
+	var hints []protocol.InlayHint
+	for _, spec := range decl.Specs {
+		spec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		// The values given by basic literals need no hint, unlike those of
+		// implicitly repeated or computed expressions.
+		show := len(spec.Values) == 0
+		var values []string
+		for i, id := range spec.Names {
+			c, ok := hc.info.Defs[id].(*types.Const)
+			if !ok || c.Val().Kind() == constant.Unknown {
+				values = nil
+				break
+			}
+			if i < len(spec.Values) {
+				if _, ok := spec.Values[i].(*ast.BasicLit); !ok && c.Val().Kind() != constant.Bool {
+					show = true
+				}
+			}
+			values = append(values, c.Val().String())
+		}
+		if !show || len(values) == 0 {
+			continue
+		}
+		hints = append(hints, protocol.InlayHint{
+			Position:    hc.position(spec.End()),
+			Label:       buildLabel("= " + strings.Join(values, ", ")),
+			PaddingLeft: true,
+		})
+	}
+	return hints
+}
+
+func compositeLiteralFields(node ast.Node, hc *hintContext) []protocol.InlayHint {
+	lit, ok := node.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	typ := hc.info.TypeOf(lit)
+	if typ == nil {
+		return nil
+	}
+	// The type of an elided &T{} is *T.
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	strct, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var hints []protocol.InlayHint
+	for i, elt := range lit.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); ok || i >= strct.NumFields() {
+			return nil
+		}
+		hints = append(hints, protocol.InlayHint{
+			Position:     hc.position(elt.Pos()),
+			Label:        buildLabel(strct.Field(i).Name() + ":"),
+			Kind:         protocol.Parameter,
+			PaddingRight: true,
+		})
+	}
+	return hints
+}
+
+func buildLabel(s string) []protocol.InlayHintLabelPart {
+	if utf8.RuneCountInString(s) > maxLabelLength {
+		s = string([]rune(s)[:maxLabelLength]) + "..."
+	}
+	return []protocol.InlayHintLabelPart{{Value: s}}
+}
@@ -0,0 +1,1287 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/go/types/typeutil"
+	"github.com/kent0106/gotools/internal/analysisinternal"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+	"github.com/kent0106/gotools/internal/typeparams"
+)
+
+// inlineCall replaces the call at the given range with the body of the
+// called function.
+func inlineCall(ctx context.Context, snapshot Snapshot, fh VersionedFileHandle, pRng protocol.Range) (*analysis.SuggestedFix, error) {
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
+	if err != nil {
+		return nil, err
+	}
+	in, err := newInliner(snapshot.FileSet(), pkg, pgf, rng)
+	if err != nil {
+		return nil, fmt.Errorf("inlineCall: %v", err)
+	}
+	fix, err := in.inline()
+	if err != nil {
+		return nil, fmt.Errorf("inlineCall: %v", err)
+	}
+	if err := typeCheckEdits(snapshot.FileSet(), pkg, pgf, fix.TextEdits); err != nil {
+		return nil, fmt.Errorf("inlineCall: the inlined code does not type-check: %v", err)
+	}
+	return fix, nil
+}
+
+// CanInlineCall reports whether the call at the given range may be replaced
+// with the body of the called function. Only the call and the signature of
+// the callee are checked, so inlining the call may still fail.
+func CanInlineCall(fset *token.FileSet, pkg Package, pgf *ParsedGoFile, rng span.Range) bool {
+	_, _, _, err := inlinedCall(fset, pkg, pgf, rng)
+	return err == nil
+}
+
+// An inliner replaces a call to a function declared in the current package
+// with the body of the function.
+type inliner struct {
+	fset *token.FileSet
+	pkg  Package
+	info *types.Info
+	pgf  *ParsedGoFile // the file containing the call
+	path []ast.Node    // the path from the call to the root of its file
+	call *ast.CallExpr
+
+	sig     *types.Signature
+	decl    *ast.FuncDecl
+	declPGF *ParsedGoFile // the file declaring the callee
+
+	// params holds the bindings of the receiver and parameters of the callee.
+	params []*inlineParam
+	// locals holds the names declared in the body of the callee. names holds
+	// these names along with the names of its receiver and parameters.
+	locals, names map[string]bool
+	// freeNames holds the names of the free identifiers of the callee.
+	freeNames map[string]bool
+	// repls holds the replacements to apply to the text of the callee's body.
+	repls []inlineRepl
+}
+
+// An inlineParam describes how a parameter (or the receiver) of the callee is
+// bound to its argument.
+type inlineParam struct {
+	obj  *types.Var // nil if the parameter is unnamed or blank
+	arg  string     // the argument, converted to the type of the parameter
+	pure bool       // whether the argument is free of side effects
+
+	// If subst is set, the uses of the parameter are replaced by arg.
+	// Otherwise, if name is not empty, arg is assigned to a variable of that
+	// name. Arguments without side effects of unused parameters are dropped.
+	subst bool
+	name  string
+}
+
+// An inlineRepl replaces the text of the callee between start and end.
+type inlineRepl struct {
+	start, end token.Pos
+	text       string
+}
+
+func newInliner(fset *token.FileSet, pkg Package, pgf *ParsedGoFile, rng span.Range) (*inliner, error) {
+	call, path, callee, err := inlinedCall(fset, pkg, pgf, rng)
+	if err != nil {
+		return nil, err
+	}
+	info := pkg.GetTypesInfo()
+	in := &inliner{
+		fset: fset,
+		pkg:  pkg,
+		info: info,
+		pgf:  pgf,
+		path: path,
+		call: call,
+		sig:  callee.Type().(*types.Signature),
+	}
+	for _, cgf := range pkg.CompiledGoFiles() {
+		for _, decl := range cgf.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && info.Defs[decl.Name] == callee {
+				in.decl, in.declPGF = decl, cgf
+			}
+		}
+	}
+	if in.decl == nil || in.decl.Body == nil {
+		return nil, fmt.Errorf("no body for %s", callee.Name())
+	}
+	if err := in.checkBody(); err != nil {
+		return nil, err
+	}
+	if err := in.checkFreeIdents(); err != nil {
+		return nil, err
+	}
+	if err := in.bindParams(); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// inlinedCall returns the call at the given range, along with the path from
+// the call to the root of its file and the callee, or an error if the
+// signature of the callee or the call prevent inlining.
+func inlinedCall(fset *token.FileSet, pkg Package, pgf *ParsedGoFile, rng span.Range) (*ast.CallExpr, []ast.Node, *types.Func, error) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.End)
+	var call *ast.CallExpr
+	for i, n := range path {
+		if c, ok := n.(*ast.CallExpr); ok && c.Fun.Pos() <= rng.Start && rng.Start <= c.Fun.End() {
+			call, path = c, path[i:]
+			break
+		}
+	}
+	if call == nil {
+		return nil, nil, nil, fmt.Errorf("no call at %s", fset.Position(rng.Start))
+	}
+	callee := typeutil.StaticCallee(pkg.GetTypesInfo(), call)
+	if callee == nil || callee.Pkg() != pkg.GetTypes() {
+		return nil, nil, nil, fmt.Errorf("not a call to a function of package %s", pkg.Name())
+	}
+	sig := callee.Type().(*types.Signature)
+	if typeparams.ForSignature(sig).Len() > 0 || typeparams.RecvTypeParams(sig).Len() > 0 {
+		return nil, nil, nil, fmt.Errorf("cannot inline a call to a generic function")
+	}
+	if sig.Variadic() || call.Ellipsis.IsValid() {
+		return nil, nil, nil, fmt.Errorf("cannot inline a call to a variadic function")
+	}
+	if len(call.Args) != sig.Params().Len() {
+		return nil, nil, nil, fmt.Errorf("cannot inline a call with a multi-valued argument")
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		if sig.Results().At(i).Name() != "" {
+			return nil, nil, nil, fmt.Errorf("cannot inline a function with named results")
+		}
+	}
+	return call, path, callee, nil
+}
+
+// checkBody reports an error if the body of the callee uses a construct that
+// cannot be inlined.
+func (in *inliner) checkBody() error {
+	var err error
+	ast.Inspect(in.decl.Body, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			err = fmt.Errorf("cannot inline a function containing a defer statement")
+		case *ast.LabeledStmt:
+			err = fmt.Errorf("cannot inline a function containing labels")
+		case *ast.CallExpr:
+			if id, ok := astutil.Unparen(n.Fun).(*ast.Ident); ok {
+				if b, ok := in.info.Uses[id].(*types.Builtin); ok && b.Name() == "recover" {
+					err = fmt.Errorf("cannot inline a function calling recover")
+				}
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// checkFreeIdents reports an error if a free identifier of the callee would
+// refer to a different object at the call site. It also records the names
+// declared by the callee.
+func (in *inliner) checkFreeIdents() error {
+	scope := in.pkg.GetTypes().Scope().Innermost(in.call.Pos())
+	if scope == nil {
+		return fmt.Errorf("no scope for the call")
+	}
+	in.locals = make(map[string]bool)
+	in.freeNames = make(map[string]bool)
+	var err error
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// The selected field or method does not depend on the scope.
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.Ident:
+			if in.info.Defs[n] != nil && n.Name != "_" && in.decl.Body.Pos() <= n.Pos() {
+				in.locals[n.Name] = true
+			}
+			obj := in.info.Uses[n]
+			if obj == nil || in.declares(obj) {
+				return true
+			}
+			if v, ok := obj.(*types.Var); ok && v.IsField() {
+				return true // a key of a struct literal
+			}
+			in.freeNames[n.Name] = true
+			_, found := scope.LookupParent(n.Name, in.call.Pos())
+			if pkgName, ok := obj.(*types.PkgName); ok {
+				if found, ok := found.(*types.PkgName); ok && found.Imported() == pkgName.Imported() {
+					return true
+				}
+			} else if found == obj {
+				return true
+			}
+			err = fmt.Errorf("%s refers to a different object at the call site", n.Name)
+		}
+		return true
+	}
+	if in.decl.Recv != nil {
+		ast.Inspect(in.decl.Recv, visit)
+	}
+	ast.Inspect(in.decl.Type, visit)
+	ast.Inspect(in.decl.Body, visit)
+	return err
+}
+
+// declares reports whether obj is declared by the callee.
+func (in *inliner) declares(obj types.Object) bool {
+	return obj.Pkg() == in.pkg.GetTypes() && in.decl.Pos() <= obj.Pos() && obj.Pos() < in.decl.End()
+}
+
+// bindParams determines how the receiver and parameters of the callee are
+// bound to the arguments of the call.
+func (in *inliner) bindParams() error {
+	type binding struct {
+		v      *types.Var
+		arg    ast.Expr
+		prefix string // an operator applied to the receiver, if any
+	}
+	var bindings []binding
+	if recv := in.sig.Recv(); recv != nil {
+		sel, ok := astutil.Unparen(in.call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return fmt.Errorf("cannot inline a call to a method value")
+		}
+		selection := in.info.Selections[sel]
+		if selection == nil || selection.Kind() != types.MethodVal || len(selection.Index()) > 1 {
+			return fmt.Errorf("cannot inline a call to a method expression or promoted method")
+		}
+		_, recvPtr := recv.Type().(*types.Pointer)
+		_, argPtr := in.info.TypeOf(sel.X).Underlying().(*types.Pointer)
+		b := binding{v: recv, arg: sel.X}
+		if recvPtr && !argPtr {
+			b.prefix = "&"
+		} else if !recvPtr && argPtr {
+			b.prefix = "*"
+		}
+		bindings = append(bindings, b)
+	}
+	for i, arg := range in.call.Args {
+		bindings = append(bindings, binding{v: in.sig.Params().At(i), arg: arg})
+	}
+	in.names = make(map[string]bool)
+	for name := range in.locals {
+		in.names[name] = true
+	}
+	for _, b := range bindings {
+		if b.v.Name() != "" && b.v.Name() != "_" {
+			in.names[b.v.Name()] = true
+		}
+	}
+
+	// Arguments with side effects may change the value of other arguments,
+	// which must then be evaluated in order.
+	impure := false
+	for _, b := range bindings {
+		if !isPure(in.info, b.arg) {
+			impure = true
+		}
+	}
+	for _, b := range bindings {
+		p := &inlineParam{pure: isPure(in.info, b.arg)}
+		if b.v.Name() != "" && b.v.Name() != "_" {
+			p.obj = b.v
+		}
+		arg := in.callSiteText(b.arg)
+		if b.prefix != "" {
+			if !isPrimary(b.arg) {
+				arg = "(" + arg + ")"
+			}
+			arg = b.prefix + arg
+		} else {
+			arg = in.convert(arg, in.info.TypeOf(b.arg), b.v.Type())
+		}
+		p.arg = arg
+		uses := in.uses(p.obj)
+		constant := in.info.Types[b.arg].Value != nil
+		switch {
+		case len(uses) == 0:
+			if !p.pure {
+				p.name = "_"
+			}
+		case in.isDuplicable(b.arg, b.prefix) && (!impure || constant || b.prefix == "&") && !in.modified(p.obj) && !in.shadowed(b.arg, p.obj.Name()) && in.isStable(b.arg, b.prefix, p.obj):
+			p.subst = true
+			if b.prefix != "" || (arg == in.callSiteText(b.arg) && !isPrimary(b.arg)) {
+				p.arg = "(" + arg + ")"
+			}
+		default:
+			p.name = p.obj.Name()
+		}
+		in.params = append(in.params, p)
+	}
+	return nil
+}
+
+// uses returns the uses of v in the body of the callee.
+func (in *inliner) uses(v *types.Var) []*ast.Ident {
+	if v == nil {
+		return nil
+	}
+	var uses []*ast.Ident
+	ast.Inspect(in.decl.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && in.info.Uses[id] == v {
+			uses = append(uses, id)
+		}
+		return true
+	})
+	return uses
+}
+
+// modified reports whether the body of the callee may modify the variable v
+// or take its address.
+func (in *inliner) modified(v *types.Var) bool {
+	return mayModify(in.info, in.decl.Body, v)
+}
+
+// mayModify reports whether root may modify the variable v, other than by
+// declaring it, or take its address.
+func mayModify(info *types.Info, root ast.Node, v *types.Var) bool {
+	modifies := func(e ast.Expr) bool {
+		for {
+			switch x := e.(type) {
+			case *ast.ParenExpr:
+				e = x.X
+			case *ast.SelectorExpr:
+				if _, ok := info.TypeOf(x.X).Underlying().(*types.Pointer); ok {
+					return false
+				}
+				e = x.X
+			case *ast.IndexExpr:
+				if _, ok := info.TypeOf(x.X).Underlying().(*types.Array); !ok {
+					return false
+				}
+				e = x.X
+			case *ast.Ident:
+				return info.Uses[x] == v
+			default:
+				return false
+			}
+		}
+	}
+	found := false
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				found = found || modifies(lhs)
+			}
+		case *ast.IncDecStmt:
+			found = found || modifies(n.X)
+		case *ast.RangeStmt:
+			found = found || n.Key != nil && modifies(n.Key) || n.Value != nil && modifies(n.Value)
+		case *ast.UnaryExpr:
+			found = found || n.Op == token.AND && modifies(n.X)
+		case *ast.SelectorExpr:
+			if sel := info.Selections[n]; sel != nil && sel.Kind() == types.MethodVal {
+				if _, ok := sel.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer); ok {
+					found = found || modifies(n.X)
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isStable reports whether the argument e, with the given prefix operator,
+// has the same value at each use of the parameter v in the body of the callee
+// as when the call is evaluated. A variable of the caller that is only
+// accessed by name cannot be modified by the callee. Other variables may be
+// modified by the calls and assignments of the callee preceding the uses.
+func (in *inliner) isStable(e ast.Expr, prefix string, v *types.Var) bool {
+	if prefix == "&" || in.info.Types[e].Value != nil {
+		return true
+	}
+	id, ok := astutil.Unparen(e).(*ast.Ident)
+	if !ok {
+		return true
+	}
+	obj, ok := in.info.Uses[id].(*types.Var)
+	if !ok {
+		return true
+	}
+	if caller := in.callerDecl(); caller != nil && caller.Pos() <= obj.Pos() && obj.Pos() < caller.End() && !escapes(in.info, caller, obj) {
+		return true
+	}
+	return !in.effectBeforeUse(v)
+}
+
+// callerDecl returns the declaration of the function containing the call, or
+// nil if the call is not in a function.
+func (in *inliner) callerDecl() *ast.FuncDecl {
+	for _, n := range in.path {
+		if decl, ok := n.(*ast.FuncDecl); ok {
+			return decl
+		}
+	}
+	return nil
+}
+
+// escapes reports whether the local variable v of the function decl may be
+// accessed other than by name: its address is taken, explicitly or by a call
+// to a pointer method, or it is captured by a function literal.
+func escapes(info *types.Info, decl *ast.FuncDecl, v *types.Var) bool {
+	refers := func(e ast.Expr) bool {
+		found := false
+		ast.Inspect(e, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && info.Uses[id] == v {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+	found := false
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.UnaryExpr:
+			found = found || n.Op == token.AND && refers(n.X)
+		case *ast.SelectorExpr:
+			if sel := info.Selections[n]; sel != nil && sel.Kind() == types.MethodVal {
+				if _, ok := sel.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer); ok {
+					found = found || refers(n.X)
+				}
+			}
+		case *ast.FuncLit:
+			found = found || !(n.Pos() <= v.Pos() && v.Pos() < n.End()) && refers(n)
+		}
+		return !found
+	})
+	return found
+}
+
+// effectBeforeUse reports whether a call, a receive, or an assignment to a
+// variable not declared by the callee may be executed before a use of v in the
+// body of the callee.
+func (in *inliner) effectBeforeUse(v *types.Var) bool {
+	uses := in.uses(v)
+	usedIn := func(n ast.Node) bool {
+		for _, id := range uses {
+			if n.Pos() <= id.Pos() && id.End() <= n.End() {
+				return true
+			}
+		}
+		return false
+	}
+	// assigns reports whether assigning to e may modify a variable that is
+	// not declared by the callee.
+	assigns := func(e ast.Expr) bool {
+		for {
+			switch x := e.(type) {
+			case *ast.ParenExpr:
+				e = x.X
+			case *ast.SelectorExpr:
+				if in.info.Selections[x] == nil {
+					return true // a qualified identifier
+				}
+				if _, ok := in.info.TypeOf(x.X).Underlying().(*types.Pointer); ok {
+					return true
+				}
+				e = x.X
+			case *ast.IndexExpr:
+				if _, ok := in.info.TypeOf(x.X).Underlying().(*types.Array); !ok {
+					return true
+				}
+				e = x.X
+			case *ast.Ident:
+				obj := in.info.ObjectOf(x)
+				return obj != nil && !in.declares(obj)
+			default:
+				return true
+			}
+		}
+	}
+	isEffect := func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if assigns(lhs) {
+					return true
+				}
+			}
+			return false
+		case *ast.IncDecStmt:
+			return assigns(n.X)
+		}
+		return hasEffects(in.info, n)
+	}
+	found := false
+	ast.Inspect(in.decl.Body, func(n ast.Node) bool {
+		if n == nil || found {
+			return false
+		}
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			// A use in a loop may follow the effects of the previous
+			// iteration.
+			if usedIn(n) {
+				ast.Inspect(n, func(n ast.Node) bool {
+					found = found || n != nil && isEffect(n)
+					return !found
+				})
+			}
+		}
+		if isEffect(n) {
+			for _, id := range uses {
+				found = found || n.End() <= id.Pos()
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// shadowed reports whether a free identifier of the expression e would be
+// shadowed by a declaration of the callee, other than the parameter param.
+func (in *inliner) shadowed(e ast.Expr, param string) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			found = in.shadowed(n.X, param)
+			return false
+		case *ast.Ident:
+			found = found || n.Name != param && (in.names[n.Name] || in.locals[n.Name])
+		}
+		return !found
+	})
+	return found
+}
+
+// isPure reports whether the evaluation of e has no side effects.
+func isPure(info *types.Info, e ast.Expr) bool {
+	pure := true
+	ast.Inspect(e, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		pure = pure && !hasEffects(info, n)
+		return pure
+	})
+	return pure
+}
+
+// hasEffects reports whether the evaluation of n, not including its
+// operands, may have side effects: n is a function call or a receive.
+func hasEffects(info *types.Info, n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.CallExpr:
+		tv := info.Types[n.Fun]
+		if tv.IsType() {
+			return false // a conversion
+		}
+		if id, ok := astutil.Unparen(n.Fun).(*ast.Ident); ok && tv.IsBuiltin() {
+			switch id.Name {
+			case "len", "cap", "complex", "real", "imag":
+				return false
+			}
+		}
+		return true
+	case *ast.UnaryExpr:
+		return n.Op == token.ARROW
+	}
+	return false
+}
+
+// isDuplicable reports whether e, with the given prefix operator, may be
+// evaluated several times instead of once.
+func (in *inliner) isDuplicable(e ast.Expr, prefix string) bool {
+	if in.info.Types[e].Value != nil {
+		return prefix == ""
+	}
+	switch e := astutil.Unparen(e).(type) {
+	case *ast.Ident:
+		return e.Name != "_" && prefix != "*"
+	case *ast.BasicLit:
+		return prefix == ""
+	}
+	return false
+}
+
+// isPrimary reports whether e is a primary expression, which does not need
+// to be parenthesized when used as an operand.
+func isPrimary(e ast.Expr) bool {
+	switch e.(type) {
+	case *ast.Ident, *ast.BasicLit, *ast.CompositeLit, *ast.FuncLit, *ast.ParenExpr,
+		*ast.SelectorExpr, *ast.IndexExpr, *ast.SliceExpr, *ast.TypeAssertExpr, *ast.CallExpr:
+		return true
+	}
+	return false
+}
+
+// convert returns the text of an expression of type from converted to the
+// type to, if the types differ.
+func (in *inliner) convert(text string, from, to types.Type) string {
+	if from == nil || types.Identical(from, to) {
+		return text
+	}
+	if b, ok := from.(*types.Basic); ok && b.Info()&types.IsUntyped != 0 && types.Identical(types.Default(from), to) {
+		return text
+	}
+	typ := types.TypeString(to, Qualifier(in.pgf.File, in.pkg.GetTypes(), in.info))
+	if strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "<-") || strings.HasPrefix(typ, "func") {
+		typ = "(" + typ + ")"
+	}
+	return typ + "(" + text + ")"
+}
+
+// callSiteText returns the source of n, a node of the file containing the
+// call.
+func (in *inliner) callSiteText(n ast.Node) string {
+	return string(in.pgf.Src[in.pgf.Tok.Offset(n.Pos()):in.pgf.Tok.Offset(n.End())])
+}
+
+// text returns the source of n, a node of the callee, with the replacements
+// of the inliner applied.
+func (in *inliner) text(n ast.Node) string {
+	return in.textRange(n.Pos(), n.End())
+}
+
+func (in *inliner) textRange(start, end token.Pos) string {
+	tok, src := in.declPGF.Tok, in.declPGF.Src
+	var b strings.Builder
+	pos := start
+	for _, r := range in.repls {
+		if r.start < pos || r.end > end {
+			continue
+		}
+		b.Write(src[tok.Offset(pos):tok.Offset(r.start)])
+		b.WriteString(r.text)
+		pos = r.end
+	}
+	b.Write(src[tok.Offset(pos):tok.Offset(end)])
+	return b.String()
+}
+
+// replace records a replacement of the text of the callee.
+func (in *inliner) replace(start, end token.Pos, text string) {
+	in.repls = append(in.repls, inlineRepl{start, end, text})
+	sort.Slice(in.repls, func(i, j int) bool {
+		return in.repls[i].start < in.repls[j].start
+	})
+}
+
+// substituteParams records the replacement of the uses of each parameter
+// by its argument, or by the variable bound to it if it was renamed.
+func (in *inliner) substituteParams() {
+	for _, p := range in.params {
+		if p.obj == nil || (!p.subst && p.name == p.obj.Name()) {
+			continue
+		}
+		text := p.name
+		if p.subst {
+			text = p.arg
+		}
+		for _, id := range in.uses(p.obj) {
+			in.replace(id.Pos(), id.End(), text)
+		}
+	}
+}
+
+// temps returns the parameters bound to variables.
+func (in *inliner) temps() []*inlineParam {
+	var temps []*inlineParam
+	for _, p := range in.params {
+		if !p.subst && p.name != "" {
+			temps = append(temps, p)
+		}
+	}
+	return temps
+}
+
+// tempDecl returns the statement binding temps to their arguments. All
+// arguments are evaluated before any variable is declared.
+func tempDecl(temps []*inlineParam) string {
+	var names, args []string
+	tok := " = "
+	for _, p := range temps {
+		names = append(names, p.name)
+		args = append(args, p.arg)
+		if p.name != "_" {
+			tok = " := "
+		}
+	}
+	return strings.Join(names, ", ") + tok + strings.Join(args, ", ")
+}
+
+// inline returns the edits inlining the call.
+func (in *inliner) inline() (*analysis.SuggestedFix, error) {
+	// Find the context of the call, ignoring enclosing parentheses.
+	i := 1
+	for i < len(in.path) {
+		if _, ok := in.path[i].(*ast.ParenExpr); !ok {
+			break
+		}
+		i++
+	}
+	parent := in.path[i]
+	var stmt ast.Stmt
+	switch p := parent.(type) {
+	case *ast.GoStmt, *ast.DeferStmt:
+		return nil, fmt.Errorf("cannot inline a call in a go or defer statement")
+	case *ast.ExprStmt:
+		stmt = p
+	case *ast.AssignStmt:
+		if len(p.Rhs) == 1 && (p.Tok == token.ASSIGN || p.Tok == token.DEFINE) {
+			stmt = p
+		}
+	case *ast.ReturnStmt:
+		if len(p.Results) == 1 {
+			stmt = p
+		}
+	}
+	body := in.decl.Body.List
+	if _, ok := stmt.(*ast.ExprStmt); !ok && len(body) == 1 {
+		if ret, ok := body[0].(*ast.ReturnStmt); ok && len(ret.Results) > 0 {
+			return in.inlineExpr(ret.Results, parent, i > 1)
+		}
+	}
+	if stmt == nil {
+		return nil, fmt.Errorf("cannot inline a function with statements into an expression")
+	}
+	return in.inlineStmt(stmt)
+}
+
+// inlineExpr replaces the call with the results of the callee, whose body is
+// a single return statement. The arguments bound to variables are assigned
+// before the statement containing the call.
+func (in *inliner) inlineExpr(results []ast.Expr, parent ast.Node, parenthesized bool) (*analysis.SuggestedFix, error) {
+	n := in.sig.Results().Len()
+	if n > 1 && len(results) > 1 {
+		switch parent.(type) {
+		case *ast.AssignStmt, *ast.ReturnStmt:
+		default:
+			return nil, fmt.Errorf("cannot inline a multi-valued call into an expression")
+		}
+	}
+	var edits []analysis.TextEdit
+	temps := in.temps()
+	if len(temps) > 0 {
+		insert := analysisinternal.StmtToInsertVarBefore(in.path)
+		switch insert.(type) {
+		case *ast.AssignStmt, *ast.ExprStmt, *ast.ReturnStmt, *ast.DeclStmt, *ast.SendStmt, *ast.IncDecStmt:
+		default:
+			return nil, fmt.Errorf("cannot find a location to evaluate the arguments")
+		}
+		for _, n := range in.path {
+			if n == insert {
+				break
+			}
+			if _, ok := n.(*ast.FuncLit); ok {
+				return nil, fmt.Errorf("cannot evaluate the arguments outside of a function literal")
+			}
+		}
+		// Evaluating the arguments before the statement must not change the
+		// order of side effects.
+		pure := true
+		ast.Inspect(insert, func(n ast.Node) bool {
+			if _, ok := n.(*ast.FuncLit); ok || n == in.call {
+				return false
+			}
+			pure = pure && !hasEffects(in.info, n)
+			return pure
+		})
+		if !pure {
+			return nil, fmt.Errorf("cannot evaluate the arguments before the enclosing statement")
+		}
+		// Name the variables so that they do not conflict with the names
+		// in scope at the call site, or the names used by the callee.
+		scopes := CollectScopes(in.info, in.path, in.call.Pos())
+		used := make(map[string]bool)
+		for _, p := range temps {
+			if p.name == "_" {
+				continue
+			}
+			p.name, _ = generateIdentifier(0, p.name, func(name string) bool {
+				return used[name] || in.locals[name] || in.freeNames[name] ||
+					in.pgf.File.Scope.Lookup(name) != nil || !isValidName(name, scopes)
+			})
+			used[p.name] = true
+		}
+		indent := calculateIndentation(in.pgf.Src, in.pgf.Tok, insert)
+		edits = append(edits, analysis.TextEdit{
+			Pos:     insert.Pos(),
+			End:     insert.Pos(),
+			NewText: []byte(tempDecl(temps) + "\n" + indent),
+		})
+	}
+	in.substituteParams()
+	var texts []string
+	for i, r := range results {
+		text := in.text(r)
+		if len(results) == n {
+			if converted := in.convert(text, in.info.TypeOf(r), in.sig.Results().At(i).Type()); converted != text {
+				text = converted
+			} else if n == 1 && !parenthesized && !isPrimary(r) && needsParens(parent, in.call) {
+				text = "(" + text + ")"
+			}
+		}
+		texts = append(texts, text)
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     in.call.Pos(),
+		End:     in.call.End(),
+		NewText: []byte(strings.Join(texts, ", ")),
+	})
+	return &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// needsParens reports whether an expression replacing e in parent must be
+// parenthesized, unless it is a primary expression.
+func needsParens(parent ast.Node, e ast.Expr) bool {
+	switch parent := parent.(type) {
+	case *ast.AssignStmt, *ast.ReturnStmt, *ast.ValueSpec, *ast.CompositeLit, *ast.SendStmt,
+		*ast.IncDecStmt, *ast.IfStmt, *ast.SwitchStmt, *ast.ForStmt, *ast.RangeStmt:
+		return false
+	case *ast.KeyValueExpr:
+		return parent.Value != e
+	case *ast.CallExpr:
+		return parent.Fun == e
+	}
+	return true
+}
+
+// inlineStmt replaces stmt, the statement containing the call, with the body
+// of the callee. The return statements of the callee are replaced by
+// assignments of the results, for an assignment, or by the evaluation of the
+// results, for an expression statement.
+func (in *inliner) inlineStmt(stmt ast.Stmt) (*analysis.SuggestedFix, error) {
+	var b strings.Builder
+	var lhs []string
+	if assign, ok := stmt.(*ast.AssignStmt); ok {
+		for _, e := range assign.Lhs {
+			if !isPure(in.info, e) {
+				return nil, fmt.Errorf("cannot inline into an assignment with side effects")
+			}
+			if in.shadowed(e, "") {
+				return nil, fmt.Errorf("the left-hand side of the assignment would be shadowed")
+			}
+			lhs = append(lhs, in.callSiteText(e))
+			if assign.Tok != token.DEFINE {
+				continue
+			}
+			if id, ok := e.(*ast.Ident); ok && in.info.Defs[id] != nil {
+				// The new variable must not be used by the inlined code.
+				if in.freeNames[id.Name] || in.argsUse(id.Name) {
+					return nil, fmt.Errorf("the declaration of %s would shadow the inlined code", id.Name)
+				}
+				typ := types.TypeString(in.info.Defs[id].Type(), Qualifier(in.pgf.File, in.pkg.GetTypes(), in.info))
+				fmt.Fprintf(&b, "var %s %s\n", id.Name, typ)
+			}
+		}
+	}
+	ret := func(r *ast.ReturnStmt) string {
+		return in.returnText(r, stmt, lhs)
+	}
+	in.substituteParams()
+	_, toReturn := stmt.(*ast.ReturnStmt)
+	if toReturn {
+		// The return statements remain return statements, wherever they are.
+		ast.Inspect(in.decl.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt:
+				in.replace(n.Pos(), n.End(), ret(n))
+			}
+			return true
+		})
+	}
+
+	temps := in.temps()
+	braces := len(temps) > 0
+	for _, s := range in.decl.Body.List {
+		switch s := s.(type) {
+		case *ast.DeclStmt:
+			braces = true
+		case *ast.AssignStmt:
+			braces = braces || s.Tok == token.DEFINE
+		}
+	}
+	if braces {
+		b.WriteString("{")
+	}
+	if len(temps) > 0 {
+		b.WriteString("\n" + tempDecl(temps))
+	}
+	if toReturn {
+		b.WriteString(in.textRange(in.decl.Body.Lbrace+1, in.decl.Body.Rbrace))
+	} else if err := in.emitTail(&b, stmtSeq(in.decl.Body.Lbrace+1, in.decl.Body.List), ret); err != nil {
+		return nil, err
+	}
+	if braces {
+		b.WriteString("\n}")
+	}
+	indent := calculateIndentation(in.pgf.Src, in.pgf.Tok, stmt)
+	text, err := formatStmts(b.String(), indent)
+	if err != nil {
+		return nil, err
+	}
+	edit := analysis.TextEdit{Pos: stmt.Pos(), End: stmt.End(), NewText: []byte(text)}
+	if text == "" {
+		// Delete the line of the statement.
+		edit.Pos -= token.Pos(len(indent))
+		if end := in.pgf.Tok.Offset(stmt.End()); end < len(in.pgf.Src) && in.pgf.Src[end] == '\n' {
+			edit.End++
+		}
+	}
+	return &analysis.SuggestedFix{TextEdits: []analysis.TextEdit{edit}}, nil
+}
+
+// argsUse reports whether an argument of the call uses the given name.
+func (in *inliner) argsUse(name string) bool {
+	found := false
+	ast.Inspect(in.call, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name && n != in.call.Fun {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// returnText returns the replacement of the return statement r of the
+// callee, for a call in stmt.
+func (in *inliner) returnText(r *ast.ReturnStmt, stmt ast.Stmt, lhs []string) string {
+	n := in.sig.Results().Len()
+	var results []string
+	pure := true
+	for i, e := range r.Results {
+		text := in.text(e)
+		if len(r.Results) == n {
+			text = in.convert(text, in.info.TypeOf(e), in.sig.Results().At(i).Type())
+		}
+		results = append(results, text)
+		pure = pure && isPure(in.info, e)
+	}
+	switch stmt.(type) {
+	case *ast.ReturnStmt:
+		if len(results) == 0 {
+			return "return"
+		}
+		return "return " + strings.Join(results, ", ")
+	case *ast.AssignStmt:
+		return strings.Join(lhs, ", ") + " = " + strings.Join(results, ", ")
+	}
+	if len(results) == 0 || pure {
+		return ""
+	}
+	if len(r.Results) == 1 {
+		if call, ok := astutil.Unparen(r.Results[0]).(*ast.CallExpr); ok {
+			if tv := in.info.Types[call.Fun]; !tv.IsType() && !tv.IsBuiltin() {
+				return in.text(call)
+			}
+		}
+	}
+	return strings.Repeat("_, ", n-1) + "_ = " + strings.Join(results, ", ")
+}
+
+// A tailStmt is a statement at the end of the inlined body.
+type tailStmt struct {
+	stmt ast.Stmt
+	from token.Pos // the start of the text preceding the statement
+}
+
+// stmtSeq returns the statements of a list, starting at from.
+func stmtSeq(from token.Pos, stmts []ast.Stmt) []tailStmt {
+	var seq []tailStmt
+	for _, s := range stmts {
+		seq = append(seq, tailStmt{s, from})
+		from = s.End()
+	}
+	return seq
+}
+
+// emitTail writes the statements of seq, which end the inlined body. The
+// return statements must be the last statement executed in seq: when an if
+// statement ending with a return is followed by other statements, these are
+// moved to its else branch.
+func (in *inliner) emitTail(b *strings.Builder, seq []tailStmt, ret func(*ast.ReturnStmt) string) error {
+	for i, ts := range seq {
+		rest := seq[i+1:]
+		if !containsReturn(ts.stmt) {
+			b.WriteString(in.textRange(ts.from, ts.stmt.End()))
+			continue
+		}
+		b.WriteString(in.textRange(ts.from, ts.stmt.Pos()))
+		switch s := ts.stmt.(type) {
+		case *ast.ReturnStmt:
+			// The statements following a return are unreachable.
+			b.WriteString(ret(s))
+			return nil
+		case *ast.IfStmt:
+			return in.emitIf(b, s, rest, ret)
+		case *ast.BlockStmt:
+			if len(rest) > 0 && !terminates(s) {
+				return fmt.Errorf("cannot inline a block containing a return statement")
+			}
+			b.WriteString("{")
+			if err := in.emitTail(b, stmtSeq(s.Lbrace+1, s.List), ret); err != nil {
+				return err
+			}
+			b.WriteString("\n}")
+			return nil
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			if len(rest) > 0 {
+				return fmt.Errorf("cannot inline a %s statement containing a return statement", nodeName(s))
+			}
+			var body *ast.BlockStmt
+			switch s := s.(type) {
+			case *ast.SwitchStmt:
+				body = s.Body
+			case *ast.TypeSwitchStmt:
+				body = s.Body
+			case *ast.SelectStmt:
+				body = s.Body
+			}
+			b.WriteString(in.textRange(s.Pos(), body.Lbrace+1))
+			from := body.Lbrace + 1
+			for _, clause := range body.List {
+				var colon token.Pos
+				var stmts []ast.Stmt
+				switch clause := clause.(type) {
+				case *ast.CaseClause:
+					colon, stmts = clause.Colon, clause.Body
+				case *ast.CommClause:
+					colon, stmts = clause.Colon, clause.Body
+				}
+				b.WriteString(in.textRange(from, colon+1))
+				if err := in.emitTail(b, stmtSeq(colon+1, stmts), ret); err != nil {
+					return err
+				}
+				from = clause.End()
+			}
+			b.WriteString("\n}")
+			return nil
+		default:
+			return fmt.Errorf("cannot inline a %s statement containing a return statement", nodeName(s))
+		}
+	}
+	return nil
+}
+
+// emitIf writes the if statement s, followed by the statements of rest.
+func (in *inliner) emitIf(b *strings.Builder, s *ast.IfStmt, rest []tailStmt, ret func(*ast.ReturnStmt) string) error {
+	var elseSeq []tailStmt
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		elseSeq = []tailStmt{{e, e.Pos()}}
+	case *ast.BlockStmt:
+		elseSeq = stmtSeq(e.Lbrace+1, e.List)
+	}
+	thenSeq := stmtSeq(s.Body.Lbrace+1, s.Body.List)
+	if len(rest) > 0 {
+		if s.Init != nil {
+			return fmt.Errorf("cannot inline an if statement with an initializer followed by other statements")
+		}
+		switch {
+		case terminates(s.Body):
+			elseSeq = append(elseSeq, rest...)
+		case s.Else != nil && terminates(s.Else):
+			thenSeq = append(thenSeq, rest...)
+		default:
+			return fmt.Errorf("cannot inline an if statement that does not return followed by other statements")
+		}
+	}
+	b.WriteString(in.textRange(s.Pos(), s.Body.Lbrace+1))
+	if err := in.emitTail(b, thenSeq, ret); err != nil {
+		return err
+	}
+	b.WriteString("\n}")
+	if len(elseSeq) == 0 {
+		return nil
+	}
+	if e, ok := s.Else.(*ast.IfStmt); ok && len(elseSeq) == 1 {
+		b.WriteString(" else ")
+		return in.emitIf(b, e, nil, ret)
+	}
+	b.WriteString(" else {")
+	if err := in.emitTail(b, elseSeq, ret); err != nil {
+		return err
+	}
+	b.WriteString("\n}")
+	return nil
+}
+
+// containsReturn reports whether n contains a return statement, outside of
+// function literals.
+func containsReturn(n ast.Node) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// terminates reports whether s always ends with a return statement or a call
+// to panic.
+func terminates(s ast.Stmt) bool {
+	switch s := s.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BlockStmt:
+		return len(s.List) > 0 && terminates(s.List[len(s.List)-1])
+	case *ast.IfStmt:
+		return s.Else != nil && terminates(s.Body) && terminates(s.Else)
+	case *ast.ExprStmt:
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "panic" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeName returns a short description of a statement.
+func nodeName(s ast.Stmt) string {
+	switch s.(type) {
+	case *ast.ForStmt, *ast.RangeStmt:
+		return "loop"
+	case *ast.SwitchStmt, *ast.TypeSwitchStmt:
+		return "switch"
+	case *ast.SelectStmt:
+		return "select"
+	}
+	return "compound"
+}
+
+// formatStmts formats a list of statements, indenting all lines but the
+// first one with indent.
+func formatStmts(text, indent string) (string, error) {
+	out, err := format.Source([]byte("package p\nfunc _() {\n" + strings.TrimSpace(text) + "\n}\n"))
+	if err != nil {
+		return "", err
+	}
+	s := string(out)
+	start, end := strings.Index(s, "{\n")+2, strings.LastIndex(s, "\n}")
+	if end <= start {
+		return "", nil
+	}
+	lines := strings.Split(s[start:end], "\n")
+	for i, line := range lines {
+		line = strings.TrimPrefix(line, "\t")
+		if i > 0 && line != "" {
+			line = indent + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// typeCheckEdits type checks pkg after applying edits to the file pgf. Since
+// the result is only meaningful if pkg itself type checks, it does nothing
+// for packages with errors.
+func typeCheckEdits(fset *token.FileSet, pkg Package, pgf *ParsedGoFile, edits []analysis.TextEdit) error {
+	if pkg.HasListOrParseErrors() || pkg.HasTypeErrors() {
+		return nil
+	}
+	// The edits do not change the imports of the package.
+	info := pkg.GetTypesInfo()
+	imports := make(map[string]*types.Package)
+	for _, cgf := range pkg.CompiledGoFiles() {
+		for _, imp := range cgf.File.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil || path == "C" {
+				return nil
+			}
+			obj := info.Implicits[imp]
+			if imp.Name != nil {
+				obj = info.Defs[imp.Name]
+			}
+			if pkgName, ok := obj.(*types.PkgName); ok {
+				imports[path] = pkgName.Imported()
+			}
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Pos < edits[j].Pos
+	})
+	var src []byte
+	offset := 0
+	for _, edit := range edits {
+		start, end := pgf.Tok.Offset(edit.Pos), pgf.Tok.Offset(edit.End)
+		src = append(src, pgf.Src[offset:start]...)
+		src = append(src, edit.NewText...)
+		offset = end
+	}
+	src = append(src, pgf.Src[offset:]...)
+
+	checkFset := token.NewFileSet()
+	var files []*ast.File
+	for _, cgf := range pkg.CompiledGoFiles() {
+		content := cgf.Src
+		if cgf.URI == pgf.URI {
+			content = src
+		}
+		f, err := parser.ParseFile(checkFset, cgf.URI.Filename(), content, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	var firstErr error
+	cfg := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			if imp, ok := imports[path]; ok {
+				return imp, nil
+			}
+			return nil, fmt.Errorf("no package for import %s", path)
+		}),
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	cfg.Check(pkg.PkgPath(), checkFset, files, nil)
+	return firstErr
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
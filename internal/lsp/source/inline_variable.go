@@ -0,0 +1,264 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/span"
+)
+
+// An inlinedVar is a local variable whose uses may be replaced by its
+// initializer.
+type inlinedVar struct {
+	v    *types.Var
+	stmt ast.Stmt // the declaration of v
+	init ast.Expr // the initializer of v
+	typ  ast.Expr // the type of v, if explicit
+	uses [][]ast.Node
+}
+
+// inlineVariable replaces the uses of the local variable selected by rng with
+// its initializer, and deletes the declaration of the variable.
+func inlineVariable(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, pkg *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	iv, err := findInlinedVar(rng, file, pkg, info)
+	if err != nil {
+		return nil, fmt.Errorf("inlineVariable: %v", err)
+	}
+	tok := fset.File(file.Pos())
+	if tok == nil {
+		return nil, fmt.Errorf("no file for pos %v", fset.Position(file.Pos()))
+	}
+	text := string(src[tok.Offset(iv.init.Pos()):tok.Offset(iv.init.End())])
+	primary := isPrimary(iv.init)
+	// The type of an untyped initializer is given by the explicit type,
+	// which must be kept.
+	tv := info.Types[iv.init]
+	if iv.typ != nil && (tv.Value != nil || !primary || !types.Identical(tv.Type, iv.v.Type())) {
+		typ := string(src[tok.Offset(iv.typ.Pos()):tok.Offset(iv.typ.End())])
+		if strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "<-") || strings.HasPrefix(typ, "func") {
+			typ = "(" + typ + ")"
+		}
+		text, primary = typ+"("+text+")", true
+	}
+
+	// Delete the lines of the declaration if it is alone on them.
+	start, end := iv.stmt.Pos(), iv.stmt.End()
+	lineStart := tok.LineStart(tok.Line(start))
+	if strings.TrimSpace(string(src[tok.Offset(lineStart):tok.Offset(start)])) == "" {
+		rest := src[tok.Offset(end):]
+		if i := strings.IndexByte(string(rest), '\n'); i >= 0 && strings.TrimSpace(string(rest[:i])) == "" {
+			start, end = lineStart, end+token.Pos(i+1)
+		}
+	}
+	edits := []analysis.TextEdit{{Pos: start, End: end}}
+	for _, path := range iv.uses {
+		use := path[0].(*ast.Ident)
+		newText := text
+		if !primary && needsParens(path[1], use) {
+			newText = "(" + newText + ")"
+		}
+		edits = append(edits, analysis.TextEdit{Pos: use.Pos(), End: use.End(), NewText: []byte(newText)})
+	}
+	return &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// CanInlineVariable reports whether the local variable selected by rng can be
+// replaced by its initializer.
+func CanInlineVariable(rng span.Range, file *ast.File, pkg *types.Package, info *types.Info) bool {
+	_, err := findInlinedVar(rng, file, pkg, info)
+	return err == nil
+}
+
+// findInlinedVar returns the local variable selected by rng, or an error if
+// replacing its uses by its initializer could change the meaning of the
+// program.
+func findInlinedVar(rng span.Range, file *ast.File, pkg *types.Package, info *types.Info) (*inlinedVar, error) {
+	path, _ := astutil.PathEnclosingInterval(file, rng.Start, rng.End)
+	if len(path) == 0 {
+		return nil, fmt.Errorf("no identifier selected")
+	}
+	id, ok := path[0].(*ast.Ident)
+	if !ok {
+		return nil, fmt.Errorf("no identifier selected")
+	}
+	v, ok := info.ObjectOf(id).(*types.Var)
+	if !ok || v.IsField() || v.Parent() == nil || v.Parent() == pkg.Scope() {
+		return nil, fmt.Errorf("%s is not a local variable", id.Name)
+	}
+
+	// Find the declaration of v, which must declare only v.
+	declPath, _ := astutil.PathEnclosingInterval(file, v.Pos(), v.Pos())
+	if declID, ok := declPath[0].(*ast.Ident); !ok || len(declPath) < 2 || info.Defs[declID] != v {
+		return nil, fmt.Errorf("no declaration of %s", v.Name())
+	}
+	iv := &inlinedVar{v: v}
+	var block int // the index in declPath of the statement list containing the declaration
+	switch decl := declPath[1].(type) {
+	case *ast.AssignStmt:
+		if decl.Tok == token.DEFINE && len(decl.Lhs) == 1 && len(decl.Rhs) == 1 {
+			iv.stmt, iv.init, block = decl, decl.Rhs[0], 2
+		}
+	case *ast.ValueSpec:
+		if len(decl.Names) == 1 && len(decl.Values) == 1 && len(declPath) > 3 {
+			if gen := declPath[2].(*ast.GenDecl); len(gen.Specs) == 1 {
+				iv.stmt, iv.init, iv.typ, block = declPath[3].(*ast.DeclStmt), decl.Values[0], decl.Type, 4
+			}
+		}
+	}
+	if iv.stmt == nil {
+		return nil, fmt.Errorf("%s is not declared alone with an initializer", v.Name())
+	}
+	if len(declPath) <= block {
+		return nil, fmt.Errorf("no block contains the declaration of %s", v.Name())
+	}
+	switch declPath[block].(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+	default:
+		return nil, fmt.Errorf("the declaration of %s is not a statement of a block", v.Name())
+	}
+	var body ast.Node // the body of the outermost function
+	for _, n := range declPath {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			body = n.Body
+		case *ast.FuncLit:
+			body = n.Body
+		}
+	}
+
+	if !isPure(info, iv.init) || !isValueExpr(info, iv.init) {
+		return nil, fmt.Errorf("the initializer of %s may have side effects or depend on memory", v.Name())
+	}
+	if mayModify(info, declPath[block], v) {
+		return nil, fmt.Errorf("%s may be modified", v.Name())
+	}
+	// The operands of the initializer are the free identifiers of the
+	// initializer and the type, excluding selected names and field names.
+	var operands []*ast.Ident
+	collect := func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && info.Uses[id] != nil {
+					operands = append(operands, id)
+				}
+				return true
+			})
+			return false
+		case *ast.Ident:
+			obj := info.Uses[n]
+			if v, ok := obj.(*types.Var); obj != nil && !(ok && v.IsField()) {
+				operands = append(operands, n)
+			}
+		}
+		return true
+	}
+	ast.Inspect(iv.init, collect)
+	if iv.typ != nil {
+		ast.Inspect(iv.typ, collect)
+	}
+	for _, op := range operands {
+		w, ok := info.Uses[op].(*types.Var)
+		if !ok {
+			continue
+		}
+		if w.Parent() == pkg.Scope() {
+			return nil, fmt.Errorf("the initializer of %s depends on the package-level variable %s", v.Name(), w.Name())
+		}
+		if body == nil || mayModify(info, body, w) {
+			return nil, fmt.Errorf("the initializer of %s depends on %s, which may be modified", v.Name(), w.Name())
+		}
+	}
+
+	var uses []*ast.Ident
+	ast.Inspect(declPath[block], func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && info.Uses[id] == v {
+			uses = append(uses, id)
+		}
+		return true
+	})
+	for _, use := range uses {
+		path, _ := astutil.PathEnclosingInterval(file, use.Pos(), use.End())
+		scope := pkg.Scope().Innermost(use.Pos())
+		if len(path) < 2 || scope == nil {
+			return nil, fmt.Errorf("no scope for %s", use.Name)
+		}
+		for _, op := range operands {
+			if _, obj := scope.LookupParent(op.Name, use.Pos()); obj != info.Uses[op] {
+				return nil, fmt.Errorf("%s would be shadowed by another declaration where %s is used", op.Name, v.Name())
+			}
+		}
+		iv.uses = append(iv.uses, path)
+	}
+	return iv, nil
+}
+
+// isValueExpr reports whether the value of e depends only on its operands,
+// and not on memory that may be modified through another variable. Besides,
+// e must not allocate memory, so that evaluating it several times yields
+// the same value.
+func isValueExpr(info *types.Info, e ast.Expr) bool {
+	ok := true
+	ast.Inspect(e, func(n ast.Node) bool {
+		if e, isExpr := n.(ast.Expr); isExpr && info.Types[e].IsType() {
+			return false
+		}
+		switch n := n.(type) {
+		case nil, *ast.Ident, *ast.BasicLit, *ast.BinaryExpr, *ast.ParenExpr, *ast.KeyValueExpr:
+		case *ast.UnaryExpr:
+			ok = n.Op != token.AND && n.Op != token.ARROW
+		case *ast.SelectorExpr:
+			if sel := info.Selections[n]; sel != nil {
+				ok = sel.Kind() == types.FieldVal && !sel.Indirect()
+				if _, isPtr := info.TypeOf(n.X).Underlying().(*types.Pointer); isPtr {
+					ok = false
+				}
+			}
+		case *ast.IndexExpr:
+			switch info.TypeOf(n.X).Underlying().(type) {
+			case *types.Array:
+			case *types.Basic: // a string
+			default:
+				ok = false
+			}
+		case *ast.SliceExpr:
+			switch info.TypeOf(n.X).Underlying().(type) {
+			case *types.Slice:
+			case *types.Basic: // a string
+			default:
+				ok = false
+			}
+		case *ast.CompositeLit:
+			switch info.TypeOf(n).Underlying().(type) {
+			case *types.Array, *types.Struct:
+			default:
+				ok = false
+			}
+		case *ast.CallExpr:
+			if info.Types[n.Fun].IsType() {
+				break // a conversion
+			}
+			// The only calls of pure expressions are to len, cap and the
+			// complex builtins. The length of maps and channels changes.
+			for _, arg := range n.Args {
+				switch info.TypeOf(arg).Underlying().(type) {
+				case *types.Map, *types.Chan:
+					ok = false
+				}
+			}
+		default:
+			ok = false
+		}
+		return ok
+	})
+	return ok
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+)
+
+// LinkedEditingRange returns the ranges that must be edited together with the
+// identifier at pos. Only labels are supported: editing the name of a label
+// also edits the goto, break, and continue statements that refer to it.
+func LinkedEditingRange(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position) ([]protocol.Range, error) {
+	ctx, done := event.Start(ctx, "source.LinkedEditingRange")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	spn, err := pgf.Mapper.PointSpan(pos)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := spn.Range(pgf.Mapper.Converter)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.Start)
+	if len(path) < 2 {
+		return nil, nil
+	}
+	id, ok := path[0].(*ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+	switch parent := path[1].(type) {
+	case *ast.LabeledStmt:
+		if parent.Label != id {
+			return nil, nil
+		}
+	case *ast.BranchStmt:
+		if parent.Label != id {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	// Labels are scoped to the body of the innermost enclosing function.
+	var body *ast.BlockStmt
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			body = n.Body
+		case *ast.FuncLit:
+			body = n.Body
+		}
+		if body != nil {
+			break
+		}
+	}
+	if body == nil {
+		return nil, nil
+	}
+	var ids []*ast.Ident
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.LabeledStmt:
+			if n.Label.Name == id.Name {
+				ids = append(ids, n.Label)
+			}
+		case *ast.BranchStmt:
+			if n.Label != nil && n.Label.Name == id.Name {
+				ids = append(ids, n.Label)
+			}
+		}
+		return true
+	})
+	var ranges []protocol.Range
+	for _, id := range ids {
+		spn, err := span.NewRange(snapshot.FileSet(), id.Pos(), id.End()).Span()
+		if err != nil {
+			return nil, err
+		}
+		rng, err := pgf.Mapper.Range(spn)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, rng)
+	}
+	return ranges, nil
+}
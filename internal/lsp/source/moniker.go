@@ -0,0 +1,84 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/types"
+
+	"github.com/kent0106/gotools/go/types/objectpath"
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	errors "golang.org/x/xerrors"
+)
+
+// MonikerScheme is the scheme of the monikers computed by gopls.
+const MonikerScheme = "go"
+
+// Moniker returns the moniker of the object referred to by the identifier at
+// pos. The identifier of the moniker is the import path of the package
+// declaring the object, followed by a colon and the object's path within the
+// package, as computed by objectpath.For. A package name has the import path
+// of the package as its identifier.
+//
+// Objects that cannot be referenced from other packages, such as local
+// variables, have no moniker.
+func Moniker(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position) ([]protocol.Moniker, error) {
+	ctx, done := event.Start(ctx, "source.Moniker")
+	defer done()
+
+	ident, err := Identifier(ctx, snapshot, fh, pos)
+	if err != nil {
+		if errors.Is(err, ErrNoIdentFound) || errors.Is(err, errNoObjectFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	obj := ident.Declaration.obj
+	if obj == nil || obj.Pkg() == nil {
+		return nil, nil // e.g. a builtin
+	}
+	m, ok := ObjectMoniker(obj, obj.Pkg() == ident.pkg.GetTypes())
+	if !ok {
+		return nil, nil
+	}
+	return []protocol.Moniker{m}, nil
+}
+
+// ObjectMoniker returns the moniker of obj, as described for Moniker. The
+// moniker is an export moniker if obj is exported by the package being
+// described, and an import moniker otherwise. An unexported object has a
+// local moniker, which is only unique within its project. It reports false if
+// obj cannot be referenced from other packages.
+func ObjectMoniker(obj types.Object, export bool) (protocol.Moniker, bool) {
+	if obj.Pkg() == nil {
+		return protocol.Moniker{}, false
+	}
+	if pkgName, ok := obj.(*types.PkgName); ok {
+		return protocol.Moniker{
+			Scheme:     MonikerScheme,
+			Identifier: pkgName.Imported().Path(),
+			Unique:     protocol.Scheme,
+			Kind:       protocol.Import,
+		}, true
+	}
+	path, err := objectpath.For(obj)
+	if err != nil {
+		return protocol.Moniker{}, false
+	}
+	kind, unique := protocol.Import, protocol.Scheme
+	switch {
+	case !obj.Exported():
+		kind, unique = protocol.Local, protocol.Project
+	case export:
+		kind = protocol.Export
+	}
+	return protocol.Moniker{
+		Scheme:     MonikerScheme,
+		Identifier: obj.Pkg().Path() + ":" + string(path),
+		Unique:     unique,
+		Kind:       kind,
+	}, true
+}
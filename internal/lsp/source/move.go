@@ -0,0 +1,874 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/imports"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+	"github.com/kent0106/gotools/refactor/satisfy"
+	errors "golang.org/x/xerrors"
+)
+
+// MoveDeclaration returns the changes moving the package-level declaration
+// named at pp, along with the methods of a type, to the end of the file dest.
+// If dest does not exist, the changes start with its creation, which the
+// client must support.
+//
+// The file dest may be in the package of the declaration, or in another
+// package of the workspace. In the latter case the references to the moved
+// declaration are qualified with the name of its new package, and the move
+// is refused if it would break a reference to an unexported declaration or
+// the implementation of an interface by unexported methods, create an import
+// cycle, or change the object a name refers to.
+func MoveDeclaration(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position, dest span.URI) ([]protocol.DocumentChanges, error) {
+	ctx, done := event.Start(ctx, "source.MoveDeclaration")
+	defer done()
+
+	qos, err := qualifiedObjsAtProtocolPos(ctx, snapshot, fh.URI(), pp)
+	if err != nil {
+		return nil, err
+	}
+	obj := qos[0].obj
+	if obj.Pkg() == nil || !isPackageLevel(obj) {
+		return nil, errors.Errorf("%s is not a package-level declaration", obj.Name())
+	}
+	if fn, ok := obj.(*types.Func); ok && (fn.Name() == "init" || fn.Name() == "main") {
+		return nil, errors.Errorf("cannot move the %s function", fn.Name())
+	}
+	declURI := span.URIFromPath(snapshot.FileSet().Position(obj.Pos()).Filename)
+	if isTestFile(declURI) {
+		return nil, errors.Errorf("cannot move %s out of a test file", obj.Name())
+	}
+	if isTestFile(dest) {
+		return nil, errors.Errorf("cannot move %s to a test file", obj.Name())
+	}
+	src, err := snapshot.PackageForFile(ctx, declURI, TypecheckFull, NarrowestPackage)
+	if err != nil {
+		return nil, err
+	}
+	if src.IsIllTyped() {
+		return nil, errors.Errorf("package %s is ill typed", src.PkgPath())
+	}
+	obj = src.GetTypes().Scope().Lookup(obj.Name())
+	dst, err := destPackage(ctx, snapshot, src, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mover{
+		snapshot: snapshot,
+		fset:     snapshot.FileSet(),
+		src:      src,
+		dst:      dst,
+		cross:    dst.PkgPath() != src.PkgPath(),
+		obj:      obj,
+		dest:     dest,
+		files:    make(map[span.URI]*moveFile),
+	}
+	if err := m.collectDecls(); err != nil {
+		return nil, err
+	}
+	inDest := true
+	for _, d := range m.decls {
+		inDest = inDest && d.file.pgf.URI == dest
+	}
+	if inDest {
+		return nil, errors.Errorf("%s is already declared in %s", obj.Name(), filepath.Base(dest.Filename()))
+	}
+	if m.cross {
+		if err := m.checkDestScope(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.visitFiles(ctx); err != nil {
+		return nil, err
+	}
+	if m.cross {
+		if err := m.checkSatisfy(); err != nil {
+			return nil, err
+		}
+	}
+	if m.cross && m.srcImportsDst && (m.dstImportsSrc || dependsOn(dst, src.PkgPath(), make(map[string]bool))) {
+		return nil, errors.Errorf("moving %s to package %s would create an import cycle", obj.Name(), dst.Name())
+	}
+	return m.edits(ctx)
+}
+
+// destPackage returns the package of the file dest, which need not exist, as
+// the non-test package of the workspace whose files are in the directory of
+// dest.
+func destPackage(ctx context.Context, snapshot Snapshot, src Package, dest span.URI) (Package, error) {
+	dir := filepath.Dir(dest.Filename())
+	for _, pgf := range src.CompiledGoFiles() {
+		if filepath.Dir(pgf.URI.Filename()) == dir {
+			return src, nil
+		}
+	}
+	active, err := snapshot.ActivePackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range active {
+		if pkg.ForTest() != "" || strings.HasSuffix(pkg.Name(), "_test") {
+			continue
+		}
+		for _, pgf := range pkg.CompiledGoFiles() {
+			if filepath.Dir(pgf.URI.Filename()) == dir {
+				return pkg, nil
+			}
+		}
+	}
+	return nil, errors.Errorf("no package of the workspace in %s", dir)
+}
+
+// dependsOn reports whether pkg imports the package with the given path,
+// directly or not.
+func dependsOn(pkg Package, path string, seen map[string]bool) bool {
+	for _, imp := range pkg.Imports() {
+		if imp.PkgPath() == path {
+			return true
+		}
+		if !seen[imp.ID()] {
+			seen[imp.ID()] = true
+			if dependsOn(imp, path, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTestFile(uri span.URI) bool {
+	return strings.HasSuffix(uri.Filename(), "_test.go")
+}
+
+// A mover computes the edits moving a declaration.
+type mover struct {
+	snapshot Snapshot
+	fset     *token.FileSet
+	src, dst Package // the packages declaring the moved objects, before and after the move
+	cross    bool    // whether src and dst are different packages
+	obj      types.Object
+	dest     span.URI
+	decls    []*movedDecl
+	files    map[span.URI]*moveFile
+	pkgs     []Package // the packages that may refer to the moved object
+
+	srcImportsDst bool                 // whether the non-test files of src will refer to dst
+	dstImportsSrc bool                 // whether the moved declarations refer to src
+	destImports   []imports.ImportInfo // the imports of the moved declarations
+	destNames     []string             // the names of destImports in the moved declarations
+}
+
+// A movedDecl is a declaration, or a specification of a group, moved with
+// the object.
+type movedDecl struct {
+	file       *moveFile
+	node       ast.Node // the *ast.GenDecl, *ast.ValueSpec, *ast.TypeSpec or *ast.FuncDecl
+	keyword    string   // the keyword preceding a specification of a group
+	start, end int      // the offsets of the moved text, including its comments
+	edits      []moveEdit
+}
+
+// A moveFile holds the changes of a file affected by the move.
+type moveFile struct {
+	pgf     *ParsedGoFile
+	pkg     Package
+	edits   []moveEdit
+	imports map[string]string    // the names of the imports of the file, keyed by path
+	uses    map[string]int       // the number of uses of the imports that remain, keyed by path
+	touched map[string]bool      // the paths of the imports whose uses are moved or removed
+	add     []imports.ImportInfo // the imports to add to the file
+}
+
+// A moveEdit replaces the text between two offsets of a file.
+type moveEdit struct {
+	start, end int
+	text       string
+}
+
+// file returns the moveFile of pgf, which belongs to pkg.
+func (m *mover) file(pgf *ParsedGoFile, pkg Package) *moveFile {
+	if f, ok := m.files[pgf.URI]; ok {
+		return f
+	}
+	f := &moveFile{
+		pgf:     pgf,
+		pkg:     pkg,
+		imports: make(map[string]string),
+		uses:    make(map[string]int),
+		touched: make(map[string]bool),
+	}
+	for _, imp := range pgf.File.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := ""
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		f.imports[path] = name
+	}
+	m.files[pgf.URI] = f
+	return f
+}
+
+// collectDecls finds the declaration of the moved object, and the methods
+// declared with it if it is a type.
+func (m *mover) collectDecls() error {
+	for _, pgf := range m.src.CompiledGoFiles() {
+		f := m.file(pgf, m.src)
+		for _, decl := range pgf.File.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				fn, ok := m.src.GetTypesInfo().Defs[decl.Name].(*types.Func)
+				if !ok {
+					continue
+				}
+				if fn == m.obj {
+					m.addDecl(f, decl, decl.Doc, "")
+				} else if recv := recv(fn); recv != nil {
+					if named, ok := Deref(recv.Type()).(*types.Named); ok && named.Obj() == m.obj {
+						m.addDecl(f, decl, decl.Doc, "")
+					}
+				}
+			case *ast.GenDecl:
+				if err := m.collectSpecs(f, decl); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if len(m.decls) == 0 {
+		return errors.Errorf("no declaration of %s", m.obj.Name())
+	}
+	return nil
+}
+
+func (m *mover) collectSpecs(f *moveFile, decl *ast.GenDecl) error {
+	info := m.src.GetTypesInfo()
+	for _, spec := range decl.Specs {
+		var names []*ast.Ident
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			names = []*ast.Ident{spec.Name}
+		case *ast.ValueSpec:
+			names = spec.Names
+		}
+		found := false
+		for _, name := range names {
+			found = found || info.Defs[name] == m.obj
+		}
+		if !found {
+			continue
+		}
+		if len(names) > 1 {
+			return errors.Errorf("cannot move %s, which is declared with other names", m.obj.Name())
+		}
+		if len(decl.Specs) == 1 {
+			m.addDecl(f, decl, decl.Doc, "")
+			return nil
+		}
+		if spec, ok := spec.(*ast.ValueSpec); ok && decl.Tok == token.CONST {
+			// The value of a constant of a group may be implicit, or
+			// depend on its position in the group through iota.
+			usesIota := false
+			ast.Inspect(spec, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && info.Uses[id] == types.Universe.Lookup("iota") {
+					usesIota = true
+				}
+				return !usesIota
+			})
+			if len(spec.Values) == 0 || usesIota {
+				return errors.Errorf("cannot move %s, whose value depends on its position in its group", m.obj.Name())
+			}
+		}
+		var doc *ast.CommentGroup
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			doc = spec.Doc
+		case *ast.ValueSpec:
+			doc = spec.Doc
+		}
+		m.addDecl(f, spec, doc, decl.Tok.String()+" ")
+		return nil
+	}
+	return nil
+}
+
+func (m *mover) addDecl(f *moveFile, node ast.Node, doc *ast.CommentGroup, keyword string) {
+	tok := f.pgf.Tok
+	start, end := node.Pos(), node.End()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	// Include the comment following a specification on its line.
+	var comment *ast.CommentGroup
+	switch node := node.(type) {
+	case *ast.TypeSpec:
+		comment = node.Comment
+	case *ast.ValueSpec:
+		comment = node.Comment
+	case *ast.GenDecl:
+		if !node.Lparen.IsValid() {
+			switch spec := node.Specs[0].(type) {
+			case *ast.TypeSpec:
+				comment = spec.Comment
+			case *ast.ValueSpec:
+				comment = spec.Comment
+			}
+		}
+	}
+	if comment != nil {
+		end = comment.End()
+	}
+	m.decls = append(m.decls, &movedDecl{
+		file:    f,
+		node:    node,
+		keyword: keyword,
+		start:   tok.Offset(start),
+		end:     tok.Offset(end),
+	})
+}
+
+// moved reports whether the given offset of the file is in a moved
+// declaration, which it returns.
+func (m *mover) moved(f *moveFile, offset int) *movedDecl {
+	for _, d := range m.decls {
+		if d.file == f && d.start <= offset && offset < d.end {
+			return d
+		}
+	}
+	return nil
+}
+
+// declaredInMoved reports whether obj is declared in a moved declaration.
+func (m *mover) declaredInMoved(obj types.Object) bool {
+	if obj.Pkg() == nil || obj.Pkg().Path() != m.src.PkgPath() || !obj.Pos().IsValid() {
+		return false
+	}
+	pos := m.fset.Position(obj.Pos())
+	for _, d := range m.decls {
+		if d.file.pgf.URI.Filename() == pos.Filename && d.start <= pos.Offset && pos.Offset < d.end {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDestScope checks that the name of the moved object is not declared
+// in the package or file blocks of the destination package.
+func (m *mover) checkDestScope(ctx context.Context) error {
+	name := m.obj.Name()
+	variants := []Package{m.dst}
+	if len(m.dst.CompiledGoFiles()) > 0 {
+		pkgs, err := m.snapshot.PackagesForFile(ctx, m.dst.CompiledGoFiles()[0].URI, TypecheckWorkspace, true)
+		if err != nil {
+			return err
+		}
+		variants = append(variants, pkgs...)
+	}
+	for _, pkg := range variants {
+		if pkg.PkgPath() != m.dst.PkgPath() {
+			continue
+		}
+		if prev := pkg.GetTypes().Scope().Lookup(name); prev != nil {
+			return errors.Errorf("moving %s %q to package %s would conflict with the %s declared at %s",
+				objectKind(m.obj), name, m.dst.Name(), objectKind(prev), m.fset.Position(prev.Pos()))
+		}
+		for _, f := range pkg.GetSyntax() {
+			if prev := pkg.GetTypesInfo().Scopes[f].Lookup(name); prev != nil {
+				return errors.Errorf("moving %s %q to package %s would conflict with the %s declared at %s",
+					objectKind(m.obj), name, m.dst.Name(), objectKind(prev), m.fset.Position(prev.Pos()))
+			}
+		}
+	}
+	return nil
+}
+
+// visitFiles visits the files of the packages that may refer to the moved
+// object: the package declaring it, its test variants, and their reverse
+// dependencies. Each file is visited once.
+func (m *mover) visitFiles(ctx context.Context) error {
+	variants, err := m.snapshot.PackagesForFile(ctx, m.decls[0].file.pgf.URI, TypecheckWorkspace, true)
+	if err != nil {
+		return err
+	}
+	pkgs := make(map[string]Package)
+	for _, pkg := range variants {
+		pkgs[pkg.ID()] = pkg
+		rdeps, err := m.snapshot.GetReverseDependencies(ctx, pkg.ID())
+		if err != nil {
+			return err
+		}
+		for _, rdep := range rdeps {
+			pkgs[rdep.ID()] = rdep
+		}
+	}
+	var ids []string
+	for id := range pkgs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		m.pkgs = append(m.pkgs, pkgs[id])
+	}
+
+	// The files of the declaring package are visited with its type
+	// information, which the moved declarations are collected from.
+	visited := make(map[span.URI]bool)
+	for _, pgf := range m.src.CompiledGoFiles() {
+		visited[pgf.URI] = true
+		if err := m.visitFile(m.files[pgf.URI]); err != nil {
+			return err
+		}
+	}
+	for _, pkg := range m.pkgs {
+		for _, pgf := range pkg.CompiledGoFiles() {
+			if visited[pgf.URI] {
+				continue
+			}
+			visited[pgf.URI] = true
+			if err := m.visitFile(m.file(pgf, pkg)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSatisfy checks that the move does not break the implementation of an
+// interface by unexported methods. The names of such methods are qualified by
+// their packages, so that a moved method no longer implements the method of
+// an interface of the source package, and conversely, though no reference to
+// them changes.
+func (m *mover) checkSatisfy() error {
+	var finder satisfy.Finder
+	for _, pkg := range m.pkgs {
+		// The package must be free of type errors, as for a renaming.
+		if pkg.HasListOrParseErrors() || pkg.HasTypeErrors() {
+			return errors.Errorf("cannot move %s: package %s has errors", m.obj.Name(), pkg.PkgPath())
+		}
+		finder.Find(pkg.GetTypesInfo(), pkg.GetSyntax())
+	}
+	for c := range finder.Result {
+		iface, ok := c.LHS.Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		for i := 0; i < iface.NumMethods(); i++ {
+			meth := iface.Method(i)
+			if meth.Exported() {
+				continue
+			}
+			impl, _, _ := types.LookupFieldOrMethod(c.RHS, false, meth.Pkg(), meth.Name())
+			if impl == nil || m.declaredInMoved(meth) == m.declaredInMoved(impl) {
+				continue
+			}
+			return errors.Errorf("cannot move %s to package %s: the unexported method %s declared at %s implements the method declared at %s",
+				m.obj.Name(), m.dst.Name(), impl.Name(), m.fset.Position(impl.Pos()), m.fset.Position(meth.Pos()))
+		}
+	}
+	return nil
+}
+
+// visitFile records the changes of the file f: the qualification of its
+// references to the moved object, and the changes of the moved declarations
+// it contains.
+func (m *mover) visitFile(f *moveFile) error {
+	var (
+		stack []ast.Node
+		err   error
+	)
+	ast.Inspect(f.pgf.File, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		if id, ok := n.(*ast.Ident); ok {
+			err = m.visitIdent(f, id, stack)
+		}
+		return true
+	})
+	return err
+}
+
+func (m *mover) visitIdent(f *moveFile, id *ast.Ident, stack []ast.Node) error {
+	info := f.pkg.GetTypesInfo()
+	obj := info.Uses[id]
+	if obj == nil {
+		return nil
+	}
+	tok := f.pgf.Tok
+	var sel *ast.SelectorExpr  // the selector of which id is the selected name
+	var qual *ast.SelectorExpr // the selector of which id is the qualifier
+	if len(stack) > 1 {
+		if s, ok := stack[len(stack)-2].(*ast.SelectorExpr); ok {
+			if s.Sel == id {
+				sel = s
+			} else {
+				qual = s
+			}
+		}
+	}
+
+	if d := m.moved(f, tok.Offset(id.Pos())); d != nil {
+		return m.visitMovedIdent(f, d, id, obj, sel, qual, stack)
+	}
+	if pkgName, ok := obj.(*types.PkgName); ok {
+		f.uses[pkgName.Imported().Path()]++
+		return nil
+	}
+	if !m.cross || !m.declaredInMoved(obj) {
+		return nil
+	}
+
+	// The reference to a moved object from another package must be to an
+	// exported object.
+	pos := m.fset.Position(id.Pos())
+	if !obj.Exported() {
+		return errors.Errorf("cannot move %s to package %s: the unexported %s %s is used at %s",
+			m.obj.Name(), m.dst.Name(), objectKind(obj), obj.Name(), pos)
+	}
+	if !isPackageLevel(obj) {
+		return nil // a field or method
+	}
+	block := enclosingBlock(info, stack)
+	if f.pkg.PkgPath() == m.dst.PkgPath() {
+		// The reference becomes unqualified in the destination package.
+		if sel == nil {
+			return errors.Errorf("cannot move %s: the reference at %s uses a dot import", m.obj.Name(), pos)
+		}
+		if _, prev := block.LookupParent(obj.Name(), id.Pos()); prev != nil {
+			return errors.Errorf("cannot move %s to package %s: the reference at %s would refer to the %s declared at %s",
+				m.obj.Name(), m.dst.Name(), pos, objectKind(prev), m.fset.Position(prev.Pos()))
+		}
+		f.uses[m.src.PkgPath()]--
+		f.touched[m.src.PkgPath()] = true
+		f.edits = append(f.edits, moveEdit{start: tok.Offset(sel.X.Pos()), end: tok.Offset(id.Pos())})
+		return nil
+	}
+	name, err := m.qualifier(f, block, id.Pos(), m.dst)
+	if err != nil {
+		return err
+	}
+	if f.pkg.PkgPath() == m.src.PkgPath() {
+		if !isTestFile(f.pgf.URI) {
+			m.srcImportsDst = true
+		}
+		f.edits = append(f.edits, moveEdit{start: tok.Offset(id.Pos()), end: tok.Offset(id.Pos()), text: name + "."})
+		return nil
+	}
+	if sel == nil {
+		return errors.Errorf("cannot move %s: the reference at %s uses a dot import", m.obj.Name(), pos)
+	}
+	f.uses[m.src.PkgPath()]--
+	f.touched[m.src.PkgPath()] = true
+	f.edits = append(f.edits, moveEdit{start: tok.Offset(sel.X.Pos()), end: tok.Offset(sel.X.End()), text: name})
+	return nil
+}
+
+// visitMovedIdent records the changes of a reference in the moved
+// declaration d.
+func (m *mover) visitMovedIdent(f *moveFile, d *movedDecl, id *ast.Ident, obj types.Object, sel, qual *ast.SelectorExpr, stack []ast.Node) error {
+	tok := f.pgf.Tok
+	if pkgName, ok := obj.(*types.PkgName); ok {
+		path := pkgName.Imported().Path()
+		f.touched[path] = true
+		if m.cross && path == m.dst.PkgPath() {
+			// A qualified reference to the destination package becomes
+			// unqualified, unless a local declaration shadows its name.
+			if qual != nil {
+				if _, prev := enclosingBlock(f.pkg.GetTypesInfo(), stack).LookupParent(qual.Sel.Name, id.Pos()); prev != nil && isLocal(prev) {
+					return errors.Errorf("cannot move %s to package %s: the reference at %s would refer to the %s declared at %s",
+						m.obj.Name(), m.dst.Name(), m.fset.Position(qual.Sel.Pos()), objectKind(prev), m.fset.Position(prev.Pos()))
+				}
+				d.edits = append(d.edits, moveEdit{start: tok.Offset(id.Pos()), end: tok.Offset(qual.Sel.Pos())})
+			}
+			return nil
+		}
+		m.addDestImport(path, pkgName.Name(), pkgName.Imported().Name())
+		return nil
+	}
+	if !m.cross || obj.Pkg() == nil || m.declaredInMoved(obj) {
+		return nil
+	}
+	if obj.Pkg().Path() == m.src.PkgPath() {
+		if !obj.Exported() {
+			return errors.Errorf("cannot move %s to package %s: it refers to the unexported %s %s declared at %s",
+				m.obj.Name(), m.dst.Name(), objectKind(obj), obj.Name(), m.fset.Position(obj.Pos()))
+		}
+		if isPackageLevel(obj) {
+			m.dstImportsSrc = true
+			m.addDestImport(m.src.PkgPath(), m.src.Name(), m.src.Name())
+			d.edits = append(d.edits, moveEdit{start: tok.Offset(id.Pos()), end: tok.Offset(id.Pos()), text: m.src.Name() + "."})
+		}
+		return nil
+	}
+	if isPackageLevel(obj) && sel == nil {
+		return errors.Errorf("cannot move %s: the reference at %s uses a dot import", m.obj.Name(), m.fset.Position(id.Pos()))
+	}
+	return nil
+}
+
+// addDestImport records that the moved declarations refer to the package
+// with the given path by name, which is its declared name unless it is
+// imported with another name.
+func (m *mover) addDestImport(path, name, declared string) {
+	for _, imp := range m.destImports {
+		if imp.ImportPath == path {
+			return
+		}
+	}
+	imp := imports.ImportInfo{ImportPath: path}
+	if name != declared {
+		imp.Name = name
+	}
+	m.destImports = append(m.destImports, imp)
+	m.destNames = append(m.destNames, name)
+}
+
+// qualifier returns the name qualifying a reference at pos in the file f to
+// the package pkg, adding an import of pkg to the file if needed.
+func (m *mover) qualifier(f *moveFile, block *types.Scope, pos token.Pos, pkg Package) (string, error) {
+	name, ok := f.imports[pkg.PkgPath()]
+	switch {
+	case !ok || name == "_":
+		f.imports[pkg.PkgPath()] = ""
+		f.add = append(f.add, imports.ImportInfo{ImportPath: pkg.PkgPath()})
+		name = pkg.Name()
+	case name == "":
+		name = pkg.Name()
+	case name == ".":
+		return "", errors.Errorf("cannot move %s: %s dot imports package %s", m.obj.Name(), filepath.Base(f.pgf.URI.Filename()), pkg.Name())
+	}
+	if _, prev := block.LookupParent(name, pos); prev != nil {
+		if pkgName, ok := prev.(*types.PkgName); !ok || pkgName.Imported().Path() != pkg.PkgPath() {
+			return "", errors.Errorf("cannot move %s to package %s: its name at %s would refer to the %s declared at %s",
+				m.obj.Name(), pkg.Name(), m.fset.Position(pos), objectKind(prev), m.fset.Position(prev.Pos()))
+		}
+	}
+	f.uses[pkg.PkgPath()]++
+	return name, nil
+}
+
+// edits returns the changes of the files affected by the move, starting with
+// the creation of the destination file if it does not exist.
+func (m *mover) edits(ctx context.Context) ([]protocol.DocumentChanges, error) {
+	// The declaration of the moved object comes first, followed by the
+	// methods of a type in their order.
+	for i, d := range m.decls {
+		if d.node.Pos() <= m.obj.Pos() && m.obj.Pos() < d.node.End() {
+			copy(m.decls[1:i+1], m.decls[:i])
+			m.decls[0] = d
+			break
+		}
+	}
+	var texts []string
+	for _, d := range m.decls {
+		f := d.file
+		src := f.pgf.Src
+		sort.Slice(d.edits, func(i, j int) bool { return d.edits[i].start < d.edits[j].start })
+		var b strings.Builder
+		b.WriteString(d.keyword)
+		last := d.start
+		for _, e := range d.edits {
+			b.Write(src[last:e.start])
+			b.WriteString(e.text)
+			last = e.end
+		}
+		b.Write(src[last:d.end])
+		texts = append(texts, b.String())
+
+		// Remove the declaration with the rest of its lines, and one of the
+		// blank lines around it.
+		start, end := d.start, d.end
+		lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+		if len(bytes.TrimSpace(src[lineStart:start])) == 0 {
+			start = lineStart
+		}
+		if i := bytes.IndexByte(src[end:], '\n'); i >= 0 && len(bytes.TrimSpace(src[end:end+i])) == 0 {
+			end += i + 1
+			if j := bytes.IndexByte(src[end:], '\n'); j >= 0 && len(bytes.TrimSpace(src[end:end+j])) == 0 {
+				end += j + 1
+			}
+		}
+		if end == len(src) && start > 0 {
+			// The blank line before a declaration ending the file goes too.
+			prev := bytes.LastIndexByte(src[:start-1], '\n') + 1
+			if len(bytes.TrimSpace(src[prev:start])) == 0 {
+				start = prev
+			}
+		}
+		f.edits = append(f.edits, moveEdit{start: start, end: end})
+	}
+	text := strings.Join(texts, "\n\n") + "\n"
+
+	destFile := m.files[m.dest]
+	if destFile == nil {
+		if pgf, err := m.dst.File(m.dest); err == nil {
+			destFile = m.file(pgf, m.dst)
+		}
+	}
+	if destFile != nil {
+		for i, imp := range m.destImports {
+			name, ok := destFile.imports[imp.ImportPath]
+			if ok && name != "_" {
+				if name != "" && name != m.destNames[i] {
+					return nil, errors.Errorf("cannot move %s: %s imports %s as %s", m.obj.Name(), filepath.Base(m.dest.Filename()), imp.ImportPath, name)
+				}
+				continue
+			}
+			if err := m.checkDestImport(destFile, imp.ImportPath, m.destNames[i]); err != nil {
+				return nil, err
+			}
+			destFile.add = append(destFile.add, imp)
+		}
+		end := len(destFile.pgf.Src)
+		if !bytes.HasSuffix(destFile.pgf.Src, []byte("\n")) {
+			text = "\n" + text
+		}
+		destFile.edits = append(destFile.edits, moveEdit{start: end, end: end, text: "\n" + text})
+	}
+
+	var edits []protocol.TextDocumentEdit
+	for uri, f := range m.files {
+		protocolEdits, err := m.fileEdits(f)
+		if err != nil {
+			return nil, err
+		}
+		if len(protocolEdits) == 0 {
+			continue
+		}
+		fh, err := m.snapshot.GetVersionedFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, documentChange(fh, protocolEdits))
+	}
+	var changes []protocol.DocumentChanges
+	if destFile == nil {
+		if !supportsResourceOperation(m.snapshot, protocol.Create) {
+			return nil, errors.Errorf("%s does not exist and the client cannot create files", m.dest.Filename())
+		}
+		changes = append(changes, protocol.DocumentChanges{
+			CreateFile: &protocol.CreateFile{
+				Kind: string(protocol.Create),
+				URI:  protocol.URIFromSpanURI(m.dest),
+			},
+		})
+		for i, imp := range m.destImports {
+			if err := m.checkDestImport(nil, imp.ImportPath, m.destNames[i]); err != nil {
+				return nil, err
+			}
+		}
+		src, err := addImports(m.snapshot, []byte(fmt.Sprintf("package %s\n", m.dst.Name())), m.destImports)
+		if err != nil {
+			return nil, err
+		}
+		fh, err := m.snapshot.GetVersionedFile(ctx, m.dest)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, documentChange(fh, []protocol.TextEdit{{NewText: string(src) + "\n" + text}}))
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].TextDocument.URI < edits[j].TextDocument.URI
+	})
+	return append(changes, protocol.TextDocumentChanges(edits)...), nil
+}
+
+// checkDestImport checks that an import of the package with the given path
+// and name may be added to the destination file f, which is nil if it does
+// not exist yet.
+func (m *mover) checkDestImport(f *moveFile, path, name string) error {
+	if prev := m.dst.GetTypes().Scope().Lookup(name); prev != nil && m.cross {
+		return errors.Errorf("cannot move %s to package %s: its import of %s would conflict with the %s declared at %s",
+			m.obj.Name(), m.dst.Name(), path, objectKind(prev), m.fset.Position(prev.Pos()))
+	}
+	if f == nil {
+		return nil
+	}
+	for _, imp := range f.pgf.File.Imports {
+		other, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || other == path {
+			continue
+		}
+		if imp.Name != nil && imp.Name.Name == name {
+			return errors.Errorf("cannot move %s: %s imports %s as %s", m.obj.Name(), filepath.Base(m.dest.Filename()), other, name)
+		}
+		if imp.Name == nil {
+			if pkgName, ok := f.pkg.GetTypesInfo().Implicits[imp].(*types.PkgName); ok && pkgName.Name() == name {
+				return errors.Errorf("cannot move %s: %s imports %s as %s", m.obj.Name(), filepath.Base(m.dest.Filename()), other, name)
+			}
+		}
+	}
+	return nil
+}
+
+// fileEdits returns the protocol edits of the file f, including the changes
+// of its imports.
+func (m *mover) fileEdits(f *moveFile) ([]protocol.TextEdit, error) {
+	// Imports are deleted before others are added, so that the import of
+	// a single package replacing another one is not parenthesized.
+	var fixes []*imports.ImportFix
+	for path := range f.touched {
+		name, ok := f.imports[path]
+		if !ok || name == "_" || name == "." || f.uses[path] > 0 {
+			continue
+		}
+		fixes = append(fixes, &imports.ImportFix{
+			StmtInfo: imports.ImportInfo{ImportPath: path, Name: name},
+			FixType:  imports.DeleteImport,
+		})
+	}
+	fixes = append(fixes, addImportFixes(f.add)...)
+	var edits []protocol.TextEdit
+	if len(fixes) > 0 {
+		importEdits, err := computeFixEdits(m.snapshot, f.pgf, importFixOptions(m.snapshot), fixes)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, importEdits...)
+	}
+	for _, e := range f.edits {
+		rng, err := NewMappedRange(m.fset, f.pgf.Mapper, f.pgf.Tok.Pos(e.start), f.pgf.Tok.Pos(e.end)).Range()
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, protocol.TextEdit{Range: rng, NewText: e.text})
+	}
+	return edits, nil
+}
+
+func documentChange(fh VersionedFileHandle, edits []protocol.TextEdit) protocol.TextDocumentEdit {
+	return protocol.TextDocumentEdit{
+		TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+			Version: fh.Version(),
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+				URI: protocol.URIFromSpanURI(fh.URI()),
+			},
+		},
+		Edits: edits,
+	}
+}
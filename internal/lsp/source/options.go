@@ -97,6 +97,8 @@ func DefaultOptions() *Options {
 						protocol.QuickFix:              true,
 						protocol.RefactorRewrite:       true,
 						protocol.RefactorExtract:       true,
+						protocol.RefactorInline:        true,
+						protocol.GoAddTest:             true,
 					},
 					Mod: {
 						protocol.SourceOrganizeImports: true,
@@ -114,6 +116,10 @@ func DefaultOptions() *Options {
 					MemoryMode:                  ModeNormal,
 					DirectoryFilters:            []string{"-node_modules"},
 				},
+				FormattingOptions: FormattingOptions{
+					StructTags:    []string{"json"},
+					StructTagCase: StructTagCamel,
+				},
 				UIOptions: UIOptions{
 					DiagnosticOptions: DiagnosticOptions{
 						DiagnosticsDelay: 250 * time.Millisecond,
@@ -187,6 +193,7 @@ type ClientOptions struct {
 	ConfigurationSupported            bool
 	DynamicConfigurationSupported     bool
 	DynamicWatchedFilesSupported      bool
+	DynamicFileOperationsSupported    bool
 	PreferredContentFormat            protocol.MarkupKind
 	LineFoldingOnly                   bool
 	HierarchicalDocumentSymbolSupport bool
@@ -195,6 +202,8 @@ type ClientOptions struct {
 	RelatedInformationSupported       bool
 	CompletionTags                    bool
 	CompletionDeprecated              bool
+	CodeActionResolveSupported        bool
+	SupportedResourceOperations       []protocol.ResourceOperationKind
 }
 
 // ServerOptions holds LSP-specific configuration that is provided by the
@@ -281,6 +290,7 @@ type UIOptions struct {
 	CompletionOptions
 	NavigationOptions
 	DiagnosticOptions
+	InlayHintOptions
 
 	// Codelenses overrides the enabled/disabled state of code lenses. See the
 	// "Code Lenses" section of the
@@ -354,6 +364,19 @@ type FormattingOptions struct {
 
 	// Gofumpt indicates if we should run gofumpt formatting.
 	Gofumpt bool
+
+	// StructTags lists the keys of the tags that the code actions on a
+	// struct type add to, remove from or normalize in its fields.
+	StructTags []string `status:"experimental"`
+
+	// StructTagCase controls how the names in the tags added or normalized
+	// by the code actions on a struct type are derived from the names of the
+	// fields.
+	StructTagCase StructTagCase `status:"experimental"`
+
+	// StructTagOmitEmpty adds the omitempty option to the tags added or
+	// normalized by the code actions on a struct type.
+	StructTagOmitEmpty bool `status:"experimental"`
 }
 
 type DiagnosticOptions struct {
@@ -399,6 +422,12 @@ type DiagnosticOptions struct {
 	ExperimentalWatchedFileDelay time.Duration `status:"experimental"`
 }
 
+type InlayHintOptions struct {
+	// Hints enables or disables the kinds of inlay hints, keyed by their
+	// names. All the hints are disabled by default.
+	Hints map[string]bool `status:"experimental"`
+}
+
 type NavigationOptions struct {
 	// ImportShortcut specifies whether import statements should link to
 	// documentation or go to definitions.
@@ -568,6 +597,20 @@ const (
 	DynamicSymbols SymbolStyle = "Dynamic"
 )
 
+type StructTagCase string
+
+const (
+	// StructTagCamel derives the names from the names of the fields in
+	// lower camel case, as in "userId".
+	StructTagCamel StructTagCase = "camel"
+	// StructTagSnake derives the names from the names of the fields in
+	// snake case, as in "user_id".
+	StructTagSnake StructTagCase = "snake"
+	// StructTagKebab derives the names from the names of the fields in
+	// kebab case, as in "user-id".
+	StructTagKebab StructTagCase = "kebab"
+)
+
 type HoverKind string
 
 const (
@@ -656,6 +699,9 @@ func (o *Options) ForClientCapabilities(caps protocol.ClientCapabilities) {
 	o.ConfigurationSupported = caps.Workspace.Configuration
 	o.DynamicConfigurationSupported = caps.Workspace.DidChangeConfiguration.DynamicRegistration
 	o.DynamicWatchedFilesSupported = caps.Workspace.DidChangeWatchedFiles.DynamicRegistration
+	if fo := caps.Workspace.FileOperations; fo != nil {
+		o.DynamicFileOperationsSupported = fo.DynamicRegistration && fo.WillRename
+	}
 
 	// Check which types of content format are supported by this client.
 	if hover := caps.TextDocument.Hover; len(hover.ContentFormat) > 0 {
@@ -680,6 +726,19 @@ func (o *Options) ForClientCapabilities(caps protocol.ClientCapabilities) {
 	} else if caps.TextDocument.Completion.CompletionItem.DeprecatedSupport {
 		o.CompletionDeprecated = true
 	}
+	// Check which files and folders the client can create, rename and delete
+	// in workspace edits.
+	if we := caps.Workspace.WorkspaceEdit; we != nil {
+		o.SupportedResourceOperations = we.ResourceOperations
+	}
+	// Check if the client can resolve the edits of code actions lazily.
+	if ca := caps.TextDocument.CodeAction; ca.DataSupport {
+		for _, prop := range ca.ResolveSupport.Properties {
+			if prop == "edit" {
+				o.CodeActionResolveSupported = true
+			}
+		}
+	}
 }
 
 func (o *Options) Clone() *Options {
@@ -706,6 +765,7 @@ func (o *Options) Clone() *Options {
 	}
 	result.Analyses = copyStringMap(o.Analyses)
 	result.Codelenses = copyStringMap(o.Codelenses)
+	result.Hints = copyStringMap(o.Hints)
 
 	copySlice := func(src []string) []string {
 		dst := make([]string, len(src))
@@ -715,6 +775,7 @@ func (o *Options) Clone() *Options {
 	result.SetEnvSlice(o.EnvSlice())
 	result.BuildFlags = copySlice(o.BuildFlags)
 	result.DirectoryFilters = copySlice(o.DirectoryFilters)
+	result.StructTags = copySlice(o.StructTags)
 
 	copyAnalyzerMap := func(src map[string]*Analyzer) map[string]*Analyzer {
 		dst := make(map[string]*Analyzer)
@@ -905,6 +966,9 @@ func (o *Options) set(name string, value interface{}, seen map[string]struct{})
 			result.Replacement = "codelenses"
 		}
 
+	case "hints":
+		result.setBoolMap(&o.Hints)
+
 	case "staticcheck":
 		result.setBool(&o.Staticcheck)
 
@@ -923,6 +987,30 @@ func (o *Options) set(name string, value interface{}, seen map[string]struct{})
 	case "gofumpt":
 		result.setBool(&o.Gofumpt)
 
+	case "structTags":
+		ikeys, ok := value.([]interface{})
+		if !ok {
+			result.errorf("invalid type %T, expect list", value)
+			break
+		}
+		var keys []string
+		for _, ikey := range ikeys {
+			keys = append(keys, fmt.Sprint(ikey))
+		}
+		o.StructTags = keys
+
+	case "structTagCase":
+		if s, ok := result.asOneOf(
+			string(StructTagCamel),
+			string(StructTagSnake),
+			string(StructTagKebab),
+		); ok {
+			o.StructTagCase = StructTagCase(s)
+		}
+
+	case "structTagOmitEmpty":
+		result.setBool(&o.StructTagOmitEmpty)
+
 	case "semanticTokens":
 		result.setBool(&o.SemanticTokens)
 
@@ -1244,7 +1332,7 @@ func defaultAnalyzers() map[string]*Analyzer {
 		shadow.Analyzer.Name:           {Analyzer: shadow.Analyzer, Enabled: false},
 		sortslice.Analyzer.Name:        {Analyzer: sortslice.Analyzer, Enabled: true},
 		testinggoroutine.Analyzer.Name: {Analyzer: testinggoroutine.Analyzer, Enabled: true},
-		unusedparams.Analyzer.Name:     {Analyzer: unusedparams.Analyzer, Enabled: false},
+		unusedparams.Analyzer.Name:     {Analyzer: unusedparams.Analyzer, Fix: RemoveParameter, Enabled: false},
 		unusedwrite.Analyzer.Name:      {Analyzer: unusedwrite.Analyzer, Enabled: false},
 		useany.Analyzer.Name:           {Analyzer: useany.Analyzer, Enabled: true},
 
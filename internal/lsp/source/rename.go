@@ -158,33 +158,7 @@ func Rename(ctx context.Context, s Snapshot, f FileHandle, pp protocol.Position,
 	if err != nil {
 		return nil, err
 	}
-	result := make(map[span.URI][]protocol.TextEdit)
-	for uri, edits := range changes {
-		// These edits should really be associated with FileHandles for maximal correctness.
-		// For now, this is good enough.
-		fh, err := s.GetFile(ctx, uri)
-		if err != nil {
-			return nil, err
-		}
-		data, err := fh.Read()
-		if err != nil {
-			return nil, err
-		}
-		converter := span.NewContentConverter(uri.Filename(), data)
-		m := &protocol.ColumnMapper{
-			URI:       uri,
-			Converter: converter,
-			Content:   data,
-		}
-		// Sort the edits first.
-		diff.SortTextEdits(edits)
-		protocolEdits, err := ToProtocolEdits(m, edits)
-		if err != nil {
-			return nil, err
-		}
-		result[uri] = protocolEdits
-	}
-	return result, nil
+	return toProtocolEditMap(ctx, s, changes)
 }
 
 // Rename all references to the identifier.
@@ -0,0 +1,247 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/diff"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+	errors "golang.org/x/xerrors"
+)
+
+// RenameFile computes the edits needed to keep the workspace building when
+// the directory oldURI is renamed to newURI. Every import of a package in
+// the directory, or in one of its subdirectories, is rewritten to the new
+// import path. If the last element of the directory name changes and the
+// package was named after it, the package clause is renamed too, along with
+// the qualified identifiers that refer to the package in importing files.
+//
+// The edits are expressed in terms of the files' locations before the
+// rename. Renames of individual files require no edits, so RenameFile
+// returns no edits for them.
+func RenameFile(ctx context.Context, s Snapshot, oldURI, newURI span.URI) (map[span.URI][]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.RenameFile")
+	defer done()
+
+	oldDir, newDir := oldURI.Filename(), newURI.Filename()
+	if fi, err := os.Stat(oldDir); err != nil || !fi.IsDir() {
+		return nil, nil
+	}
+	// Find the packages that move along with the directory, including their
+	// test variants, and the files that belong to the renamed directory
+	// itself.
+	moved := make(map[string]string) // old import path -> new import path
+	inDir := make(map[string]bool)   // old import paths of packages in oldDir
+	var pkgs []Package
+	var dirFiles []span.URI
+	seen := make(map[string]bool)
+	if err := filepath.Walk(oldDir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(fpath, ".go") {
+			return nil
+		}
+		uri := span.URIFromPath(fpath)
+		dir := filepath.Dir(fpath)
+		topLevel := dir == oldDir
+		if topLevel {
+			dirFiles = append(dirFiles, uri)
+		}
+		fpkgs, err := s.PackagesForFile(ctx, uri, TypecheckWorkspace, true)
+		if err != nil {
+			// The file may be excluded by build constraints.
+			return nil
+		}
+		for _, pkg := range fpkgs {
+			if seen[pkg.ID()] {
+				continue
+			}
+			seen[pkg.ID()] = true
+			pkgs = append(pkgs, pkg)
+			if pkg.ForTest() != "" {
+				continue
+			}
+			// The package directory keeps its position relative to oldDir.
+			sub, err := filepath.Rel(oldDir, dir)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, filepath.Join(newDir, sub))
+			if err != nil {
+				return err
+			}
+			newPath := path.Join(pkg.PkgPath(), filepath.ToSlash(rel))
+			if strings.HasPrefix(newPath, "..") {
+				return errors.Errorf("cannot determine the new import path of %s", pkg.PkgPath())
+			}
+			moved[pkg.PkgPath()] = newPath
+			inDir[pkg.PkgPath()] = topLevel
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Determine whether the package in the renamed directory changes name.
+	oldName, newName := filepath.Base(oldDir), filepath.Base(newDir)
+	renamePkg := oldName != newName && isValidIdentifier(oldName) && isValidIdentifier(newName)
+
+	changes := make(map[span.URI][]diff.TextEdit)
+	addEdit := func(rng span.Range, newText string) error {
+		spn, err := rng.Span()
+		if err != nil {
+			return err
+		}
+		for _, edit := range changes[spn.URI()] {
+			if edit.Span == spn {
+				return nil // already updated through another package variant
+			}
+		}
+		changes[spn.URI()] = append(changes[spn.URI()], diff.TextEdit{Span: spn, NewText: newText})
+		return nil
+	}
+
+	if renamePkg {
+		for _, uri := range dirFiles {
+			fh, err := s.GetFile(ctx, uri)
+			if err != nil {
+				return nil, err
+			}
+			pgf, err := s.ParseGo(ctx, fh, ParseHeader)
+			if err != nil {
+				return nil, err
+			}
+			name := pgf.File.Name
+			var to string
+			switch name.Name {
+			case oldName:
+				to = newName
+			case oldName + "_test":
+				to = newName + "_test"
+			default:
+				continue
+			}
+			if err := addEdit(span.NewRange(s.FileSet(), name.Pos(), name.End()), to); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		oldPath := pkg.PkgPath()
+		newPath, ok := moved[oldPath]
+		if !ok {
+			continue // an external test package, which cannot be imported
+		}
+		rdeps, err := s.GetReverseDependencies(ctx, pkg.ID())
+		if err != nil {
+			return nil, err
+		}
+		// Only the package in the renamed directory itself changes name.
+		qualify := renamePkg && inDir[oldPath] && pkg.Name() == oldName
+		for _, rdep := range rdeps {
+			info := rdep.GetTypesInfo()
+			for _, pgf := range rdep.CompiledGoFiles() {
+				for _, imp := range pgf.File.Imports {
+					if impPath, _ := strconv.Unquote(imp.Path.Value); impPath != oldPath {
+						continue
+					}
+					newText := strconv.Quote(newPath)
+					if qualify && imp.Name == nil {
+						pkgName, _ := info.Implicits[imp].(*types.PkgName)
+						uses := pkgNameUses(info, pgf.File, pkgName)
+						if canRenameQualifier(rdep, uses, newName) {
+							for _, id := range uses {
+								if err := addEdit(span.NewRange(s.FileSet(), id.Pos(), id.End()), newName); err != nil {
+									return nil, err
+								}
+							}
+						} else {
+							// Keep referring to the package by its old name.
+							newText = oldName + " " + newText
+						}
+					}
+					if err := addEdit(span.NewRange(s.FileSet(), imp.Path.Pos(), imp.Path.End()), newText); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return toProtocolEditMap(ctx, s, changes)
+}
+
+// pkgNameUses returns the identifiers in f that refer to pkgName.
+func pkgNameUses(info *types.Info, f *ast.File, pkgName *types.PkgName) []*ast.Ident {
+	if pkgName == nil {
+		return nil
+	}
+	var uses []*ast.Ident
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && info.Uses[id] == pkgName {
+			uses = append(uses, id)
+		}
+		return true
+	})
+	return uses
+}
+
+// canRenameQualifier reports whether each of the uses of a package name in
+// pkg can be replaced by name without being captured by another
+// declaration in scope.
+func canRenameQualifier(pkg Package, uses []*ast.Ident, name string) bool {
+	for _, id := range uses {
+		scope := pkg.GetTypes().Scope().Innermost(id.Pos())
+		if scope == nil {
+			return false
+		}
+		if _, obj := scope.LookupParent(name, id.Pos()); obj != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// toProtocolEditMap converts edits computed against the snapshot's files
+// into protocol edits.
+//
+// These edits should really be associated with FileHandles for maximal
+// correctness. For now, this is good enough.
+func toProtocolEditMap(ctx context.Context, s Snapshot, changes map[span.URI][]diff.TextEdit) (map[span.URI][]protocol.TextEdit, error) {
+	result := make(map[span.URI][]protocol.TextEdit)
+	for uri, edits := range changes {
+		fh, err := s.GetFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		data, err := fh.Read()
+		if err != nil {
+			return nil, err
+		}
+		m := &protocol.ColumnMapper{
+			URI:       uri,
+			Converter: span.NewContentConverter(uri.Filename(), data),
+			Content:   data,
+		}
+		diff.SortTextEdits(edits)
+		protocolEdits, err := ToProtocolEdits(m, edits)
+		if err != nil {
+			return nil, err
+		}
+		result[uri] = protocolEdits
+	}
+	return result, nil
+}
@@ -0,0 +1,562 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/kent0106/gotools/go/analysis"
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/span"
+)
+
+// An IfRewrite is a rewrite of the if statement at a range, offered as a
+// code action.
+type IfRewrite struct {
+	Fix   string // the name of the suggested fix
+	Title string // the title of the code action
+}
+
+var ifRewrites = []struct {
+	IfRewrite
+	fix singleFileFixFunc
+}{
+	{IfRewrite{InvertIf, "Invert if condition"}, invertIf},
+	{IfRewrite{IfElseToSwitch, "Convert if/else chain to switch"}, ifElseToSwitch},
+	{IfRewrite{SplitIf, "Split && condition into nested ifs"}, splitIf},
+	{IfRewrite{JoinIf, "Join nested ifs into && condition"}, joinIf},
+	{IfRewrite{EarlyReturn, "Replace else with early return"}, earlyReturn},
+}
+
+// IfRewrites returns the rewrites applicable to the if statement whose
+// header contains rng.
+func IfRewrites(fset *token.FileSet, pkg Package, pgf *ParsedGoFile, rng span.Range) []IfRewrite {
+	var rewrites []IfRewrite
+	for _, r := range ifRewrites {
+		if _, err := r.fix(fset, rng, pgf.Src, pgf.File, pkg.GetTypes(), pkg.GetTypesInfo()); err == nil {
+			rewrites = append(rewrites, r.IfRewrite)
+		}
+	}
+	return rewrites
+}
+
+// ifStmtAt returns the innermost if statement whose header, from the if
+// keyword to the opening brace of its body, contains rng, and the path to
+// it.
+func ifStmtAt(file *ast.File, rng span.Range) (*ast.IfStmt, []ast.Node, error) {
+	path, _ := astutil.PathEnclosingInterval(file, rng.Start, rng.End)
+	for i, n := range path {
+		if stmt, ok := n.(*ast.IfStmt); ok && rng.Start >= stmt.Pos() && rng.End <= stmt.Body.Lbrace {
+			return stmt, path[i:], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no if statement selected")
+}
+
+// invertIf negates the condition of an if statement and swaps its branches.
+func invertIf(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, _ *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	stmt, _, err := ifStmtAt(file, rng)
+	if err != nil {
+		return nil, err
+	}
+	els, ok := stmt.Else.(*ast.BlockStmt)
+	if !ok {
+		return nil, fmt.Errorf("the if statement has no else block")
+	}
+	tok := fset.File(stmt.Pos())
+	text := func(from, to token.Pos) []byte {
+		return src[tok.Offset(from):tok.Offset(to)]
+	}
+	return &analysis.SuggestedFix{
+		TextEdits: []analysis.TextEdit{
+			{Pos: stmt.Cond.Pos(), End: stmt.Cond.End(), NewText: []byte(negateCond(src, tok, info, stmt.Cond, token.LowestPrec))},
+			{Pos: stmt.Body.Lbrace + 1, End: stmt.Body.Rbrace, NewText: text(els.Lbrace+1, els.Rbrace)},
+			{Pos: els.Lbrace + 1, End: els.Rbrace, NewText: text(stmt.Body.Lbrace+1, stmt.Body.Rbrace)},
+		},
+	}, nil
+}
+
+// negateCond returns the text of the negation of the boolean expression e,
+// as an operand of a binary operator of precedence prec. Comparisons are
+// inverted, negations are removed, and the negations of conjunctions and
+// disjunctions of such operands are expanded. The result is parenthesized
+// when its precedence is lower than prec.
+func negateCond(src []byte, tok *token.File, info *types.Info, e ast.Expr, prec int) string {
+	text := func(e ast.Expr) string {
+		return string(src[tok.Offset(e.Pos()):tok.Offset(e.End())])
+	}
+	switch e := e.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			if paren, ok := e.X.(*ast.ParenExpr); ok {
+				if bin, ok := paren.X.(*ast.BinaryExpr); ok && bin.Op.Precedence() < prec {
+					return text(paren)
+				}
+				return text(paren.X)
+			}
+			return text(e.X)
+		}
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.GTR, token.LEQ, token.GEQ:
+			// The ordered comparisons of floating-point numbers are not
+			// inverted, as they are all false for NaN.
+			if e.Op != token.EQL && e.Op != token.NEQ && isFloat(info.TypeOf(e.X)) {
+				break
+			}
+			inverse := map[token.Token]token.Token{
+				token.EQL: token.NEQ, token.NEQ: token.EQL,
+				token.LSS: token.GEQ, token.GEQ: token.LSS,
+				token.GTR: token.LEQ, token.LEQ: token.GTR,
+			}[e.Op]
+			opEnd := e.OpPos + token.Pos(len(e.Op.String()))
+			return string(src[tok.Offset(e.Pos()):tok.Offset(e.OpPos)]) + inverse.String() + string(src[tok.Offset(opEnd):tok.Offset(e.End())])
+		case token.LAND, token.LOR:
+			if isLogical(e.X) || isLogical(e.Y) {
+				break
+			}
+			inverse := token.LOR
+			if e.Op == token.LOR {
+				inverse = token.LAND
+			}
+			cond := negateCond(src, tok, info, e.X, inverse.Precedence()) + " " + inverse.String() + " " + negateCond(src, tok, info, e.Y, inverse.Precedence())
+			if inverse.Precedence() < prec {
+				return "(" + cond + ")"
+			}
+			return cond
+		}
+	}
+	if isPrimary(e) {
+		return "!" + text(e)
+	}
+	return "!(" + text(e) + ")"
+}
+
+func isLogical(e ast.Expr) bool {
+	bin, ok := e.(*ast.BinaryExpr)
+	return ok && (bin.Op == token.LAND || bin.Op == token.LOR)
+}
+
+func isFloat(t types.Type) bool {
+	if t == nil {
+		return true
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	return !ok || basic.Info()&(types.IsFloat|types.IsComplex) != 0
+}
+
+// ifElseToSwitch converts a chain of if/else statements comparing the same
+// operand to values into a switch statement on that operand.
+func ifElseToSwitch(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, _ *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	stmt, path, err := ifStmtAt(file, rng)
+	if err != nil {
+		return nil, err
+	}
+	// The conversion starts at the first if statement of the chain.
+	for i := 1; i < len(path); i++ {
+		parent, ok := path[i].(*ast.IfStmt)
+		if !ok || parent.Else != path[i-1] {
+			break
+		}
+		stmt = parent
+	}
+	tok := fset.File(stmt.Pos())
+	text := func(n ast.Node) string {
+		return string(src[tok.Offset(n.Pos()):tok.Offset(n.End())])
+	}
+
+	var (
+		tag    ast.Expr
+		cases  []string // the values of each case
+		bodies []*ast.BlockStmt
+		values []constant.Value
+	)
+	for cur := stmt; ; {
+		if cur != stmt && cur.Init != nil {
+			return nil, fmt.Errorf("an else if statement has an init statement")
+		}
+		var operands []ast.Expr
+		for _, op := range splitBinary(cur.Cond, token.LOR) {
+			cmp, ok := astutil.Unparen(op).(*ast.BinaryExpr)
+			if !ok || cmp.Op != token.EQL {
+				return nil, fmt.Errorf("%s is not a comparison", text(op))
+			}
+			if tag == nil {
+				if !isPure(info, cmp.X) || info.Types[cmp.X].Value != nil {
+					return nil, fmt.Errorf("%s is not a variable", text(cmp.X))
+				}
+				tag = cmp.X
+			}
+			if types.ExprString(cmp.X) != types.ExprString(tag) {
+				return nil, fmt.Errorf("%s does not compare %s", text(op), text(tag))
+			}
+			if v := info.Types[cmp.Y].Value; v != nil {
+				for _, w := range values {
+					if constant.Compare(v, token.EQL, w) {
+						return nil, fmt.Errorf("duplicate value %s", text(cmp.Y))
+					}
+				}
+				values = append(values, v)
+			}
+			operands = append(operands, cmp.Y)
+		}
+		var vals []string
+		for _, op := range operands {
+			vals = append(vals, text(op))
+		}
+		cases = append(cases, strings.Join(vals, ", "))
+		bodies = append(bodies, cur.Body)
+		if next, ok := cur.Else.(*ast.IfStmt); ok {
+			cur = next
+			continue
+		}
+		if els, ok := cur.Else.(*ast.BlockStmt); ok {
+			bodies = append(bodies, els)
+		}
+		break
+	}
+	if len(cases) < 2 {
+		return nil, fmt.Errorf("the if statement has no else if statement")
+	}
+	for _, body := range bodies {
+		if !isMultilineBlock(tok, src, body) {
+			return nil, fmt.Errorf("a block is not formatted on several lines")
+		}
+		if breaksOut(body) {
+			return nil, fmt.Errorf("a block contains a break statement")
+		}
+	}
+	// Only the comments of the blocks are preserved.
+	for _, cg := range file.Comments {
+		if cg.Pos() < stmt.Pos() || cg.End() > stmt.End() {
+			continue
+		}
+		inBlock := false
+		for _, body := range bodies {
+			inBlock = inBlock || body.Lbrace < cg.Pos() && cg.End() < body.Rbrace
+		}
+		if !inBlock {
+			return nil, fmt.Errorf("the if statement has comments outside of its blocks")
+		}
+	}
+
+	indent := calculateIndentation(src, tok, stmt)
+	header := "switch "
+	if stmt.Init != nil {
+		header += text(stmt.Init) + "; "
+	}
+	header += text(tag) + " {\n" + indent + "case " + cases[0] + ":"
+	edits := []analysis.TextEdit{{Pos: stmt.Pos(), End: stmt.Body.Lbrace + 1, NewText: []byte(header)}}
+	for i := 1; i < len(bodies); i++ {
+		clause := indent + "default:"
+		if i < len(cases) {
+			clause = indent + "case " + cases[i] + ":"
+		}
+		edits = append(edits, analysis.TextEdit{
+			Pos:     tok.LineStart(tok.Line(bodies[i-1].Rbrace)),
+			End:     bodies[i].Lbrace + 1,
+			NewText: []byte(clause),
+		})
+	}
+	return &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// splitBinary returns the operands of the chain of op operators in e.
+func splitBinary(e ast.Expr, op token.Token) []ast.Expr {
+	if bin, ok := e.(*ast.BinaryExpr); ok && bin.Op == op {
+		return append(splitBinary(bin.X, op), splitBinary(bin.Y, op)...)
+	}
+	return []ast.Expr{e}
+}
+
+// breaksOut reports whether block contains a break statement without label
+// that is not within a nested for, switch or select statement, which would
+// refer to a switch statement replacing the if statement of block.
+func breaksOut(block *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(block, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+			return false
+		case *ast.BranchStmt:
+			found = found || n.Tok == token.BREAK && n.Label == nil
+		}
+		return !found
+	})
+	return found
+}
+
+// splitIf splits an if statement whose condition is a conjunction into an
+// if statement on its first operand, whose body is an if statement on the
+// others.
+func splitIf(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, _ *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	stmt, _, err := ifStmtAt(file, rng)
+	if err != nil {
+		return nil, err
+	}
+	cond, ok := stmt.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.LAND {
+		return nil, fmt.Errorf("the condition is not a conjunction")
+	}
+	if stmt.Else != nil {
+		return nil, fmt.Errorf("the if statement has an else branch")
+	}
+	tok := fset.File(stmt.Pos())
+	if !isMultilineBlock(tok, src, stmt.Body) {
+		return nil, fmt.Errorf("the block is not formatted on several lines")
+	}
+	operands := splitBinary(cond, token.LAND)
+	first, rest := operands[0], operands[1]
+	text := func(from, to token.Pos) string {
+		return string(src[tok.Offset(from):tok.Offset(to)])
+	}
+	indent := calculateIndentation(src, tok, stmt) + "\t"
+	inner := "if " + text(rest.Pos(), cond.End()) + " {"
+	edits := []analysis.TextEdit{{
+		Pos:     first.End(),
+		End:     stmt.Body.Lbrace + 1,
+		NewText: []byte(" {\n" + indent + inner),
+	}}
+	edits = append(edits, reindentEdits(tok, src, stmt.Body, 1)...)
+	closing := tok.LineStart(tok.Line(stmt.Body.Rbrace))
+	edits = append(edits, analysis.TextEdit{Pos: closing, End: closing, NewText: []byte(indent + "}\n")})
+	return &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// joinIf joins an if statement whose body is only an if statement into an
+// if statement on the conjunction of their conditions.
+func joinIf(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, _ *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	stmt, _, err := ifStmtAt(file, rng)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmt.Body.List) != 1 {
+		return nil, fmt.Errorf("the body is not a single if statement")
+	}
+	inner, ok := stmt.Body.List[0].(*ast.IfStmt)
+	if !ok {
+		return nil, fmt.Errorf("the body is not a single if statement")
+	}
+	if stmt.Else != nil || inner.Else != nil {
+		return nil, fmt.Errorf("the if statements have else branches")
+	}
+	if inner.Init != nil {
+		return nil, fmt.Errorf("the nested if statement has an init statement")
+	}
+	tok := fset.File(stmt.Pos())
+	if !isMultilineBlock(tok, src, stmt.Body) || !isMultilineBlock(tok, src, inner.Body) {
+		return nil, fmt.Errorf("a block is not formatted on several lines")
+	}
+	for _, cg := range file.Comments {
+		if stmt.Body.Lbrace < cg.Pos() && cg.End() < stmt.Body.Rbrace && (cg.End() < inner.Body.Lbrace || cg.Pos() > inner.Body.Rbrace) {
+			return nil, fmt.Errorf("the body has comments outside of the nested if statement")
+		}
+	}
+	text := func(e ast.Expr) string {
+		return string(src[tok.Offset(e.Pos()):tok.Offset(e.End())])
+	}
+	innerCond := text(inner.Cond)
+	if bin, ok := inner.Cond.(*ast.BinaryExpr); ok && bin.Op == token.LOR {
+		innerCond = "(" + innerCond + ")"
+	}
+	var edits []analysis.TextEdit
+	if bin, ok := stmt.Cond.(*ast.BinaryExpr); ok && bin.Op == token.LOR {
+		edits = append(edits, analysis.TextEdit{Pos: stmt.Cond.Pos(), End: stmt.Cond.Pos(), NewText: []byte("(")})
+		edits = append(edits, analysis.TextEdit{Pos: stmt.Cond.End(), End: stmt.Cond.End(), NewText: []byte(")")})
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     stmt.Cond.End(),
+		End:     inner.Body.Lbrace + 1,
+		NewText: []byte(" && " + innerCond + " {"),
+	})
+	edits = append(edits, reindentEdits(tok, src, inner.Body, -1)...)
+	edits = append(edits, analysis.TextEdit{
+		Pos: tok.LineStart(tok.Line(inner.Body.Rbrace)),
+		End: tok.LineStart(tok.Line(stmt.Body.Rbrace)),
+	})
+	return &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// earlyReturn removes the else branch of an if statement whose body ends
+// with a return, or a similar terminating statement, and moves the
+// statements of the else branch after the if statement. If only the else
+// branch terminates, the condition is inverted first.
+func earlyReturn(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, _ *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	stmt, path, err := ifStmtAt(file, rng)
+	if err != nil {
+		return nil, err
+	}
+	els, ok := stmt.Else.(*ast.BlockStmt)
+	if !ok {
+		return nil, fmt.Errorf("the if statement has no else block")
+	}
+	if stmt.Init != nil {
+		return nil, fmt.Errorf("the if statement has an init statement")
+	}
+	var list []ast.Stmt
+	switch parent := path[1].(type) {
+	case *ast.BlockStmt:
+		list = parent.List
+	case *ast.CaseClause:
+		list = parent.Body
+	case *ast.CommClause:
+		list = parent.Body
+	default:
+		return nil, fmt.Errorf("the if statement is not in a block")
+	}
+	tok := fset.File(stmt.Pos())
+	if !isMultilineBlock(tok, src, stmt.Body) || !isMultilineBlock(tok, src, els) {
+		return nil, fmt.Errorf("a block is not formatted on several lines")
+	}
+
+	// The declarations of the hoisted block must not conflict with those
+	// of the enclosing block, nor shadow the declarations used after the if
+	// statement.
+	hoisted := els
+	if !jumpsOut(stmt.Body) {
+		if !jumpsOut(els) {
+			return nil, fmt.Errorf("no branch of the if statement returns")
+		}
+		hoisted = stmt.Body
+	}
+	scope := info.Scopes[hoisted]
+	outer := info.Scopes[path[1]]
+	if len(path) > 2 {
+		// The scope of a function body is that of its type.
+		switch fn := path[2].(type) {
+		case *ast.FuncDecl:
+			outer = info.Scopes[fn.Type]
+		case *ast.FuncLit:
+			outer = info.Scopes[fn.Type]
+		}
+	}
+	if scope == nil || outer == nil {
+		return nil, fmt.Errorf("no scope for the if statement")
+	}
+	for _, name := range scope.Names() {
+		if outer.Lookup(name) != nil {
+			return nil, fmt.Errorf("%s is already declared", name)
+		}
+		for _, s := range list {
+			if s.Pos() > stmt.End() && refersTo(s, name) {
+				return nil, fmt.Errorf("%s is used after the if statement", name)
+			}
+		}
+	}
+
+	text := func(from, to token.Pos) string {
+		return string(src[tok.Offset(from):tok.Offset(to)])
+	}
+	if hoisted == els {
+		edits := []analysis.TextEdit{{Pos: stmt.Body.Rbrace + 1, End: els.Lbrace + 1}}
+		edits = append(edits, reindentEdits(tok, src, els, -1)...)
+		edits = append(edits, analysis.TextEdit{Pos: tok.LineStart(tok.Line(els.Rbrace)) - 1, End: els.Rbrace + 1})
+		return &analysis.SuggestedFix{TextEdits: edits}, nil
+	}
+
+	// The else branch becomes the body of the inverted if statement, and
+	// the body follows it.
+	body := text(stmt.Body.Lbrace+1, tok.LineStart(tok.Line(stmt.Body.Rbrace)))
+	var b strings.Builder
+	b.WriteString(text(els.Lbrace+1, tok.LineStart(tok.Line(els.Rbrace))))
+	b.WriteString(calculateIndentation(src, tok, stmt) + "}")
+	b.WriteString(strings.TrimSuffix(applyEdits(body, stmt.Body.Lbrace+1, reindentEdits(tok, src, stmt.Body, -1)), "\n"))
+	return &analysis.SuggestedFix{
+		TextEdits: []analysis.TextEdit{
+			{Pos: stmt.Cond.Pos(), End: stmt.Cond.End(), NewText: []byte(negateCond(src, tok, info, stmt.Cond, token.LowestPrec))},
+			{Pos: stmt.Body.Lbrace + 1, End: els.Rbrace + 1, NewText: []byte(b.String())},
+		},
+	}, nil
+}
+
+// applyEdits returns text, which starts at pos, with the sorted edits
+// applied.
+func applyEdits(text string, pos token.Pos, edits []analysis.TextEdit) string {
+	var b strings.Builder
+	last := 0
+	for _, edit := range edits {
+		start, end := int(edit.Pos-pos), int(edit.End-pos)
+		b.WriteString(text[last:start])
+		b.Write(edit.NewText)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// jumpsOut reports whether block ends with a return, branch or panic
+// statement.
+func jumpsOut(block *ast.BlockStmt) bool {
+	if n := len(block.List); n > 0 {
+		if _, ok := block.List[n-1].(*ast.BranchStmt); ok {
+			return true
+		}
+	}
+	return terminates(block)
+}
+
+// refersTo reports whether n contains an identifier with the given name.
+func refersTo(n ast.Node, name string) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isMultilineBlock reports whether the braces of block are on different
+// lines, with the closing brace first on its line and the statements after
+// the line of the opening brace, as formatted by gofmt.
+func isMultilineBlock(tok *token.File, src []byte, block *ast.BlockStmt) bool {
+	open, close := tok.Line(block.Lbrace), tok.Line(block.Rbrace)
+	if open == close {
+		return false
+	}
+	if len(block.List) > 0 && tok.Line(block.List[0].Pos()) == open {
+		return false
+	}
+	start := tok.Offset(tok.LineStart(close))
+	return strings.TrimLeft(string(src[start:tok.Offset(block.Rbrace)]), " \t") == ""
+}
+
+// reindentEdits returns the edits adding (delta > 0) or removing (delta <
+// 0) a tab at the start of the lines between the braces of block. Empty
+// lines, and lines within raw string literals, are left unchanged.
+func reindentEdits(tok *token.File, src []byte, block *ast.BlockStmt, delta int) []analysis.TextEdit {
+	var raw [][2]token.Pos
+	ast.Inspect(block, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && strings.HasPrefix(lit.Value, "`") {
+			raw = append(raw, [2]token.Pos{lit.Pos(), lit.End()})
+		}
+		return true
+	})
+	var edits []analysis.TextEdit
+	for line := tok.Line(block.Lbrace) + 1; line < tok.Line(block.Rbrace); line++ {
+		start := tok.LineStart(line)
+		inRaw := false
+		for _, r := range raw {
+			inRaw = inRaw || r[0] < start && start < r[1]
+		}
+		off := tok.Offset(start)
+		if inRaw || off >= len(src) || src[off] == '\n' {
+			continue
+		}
+		switch {
+		case delta > 0:
+			edits = append(edits, analysis.TextEdit{Pos: start, End: start, NewText: []byte("\t")})
+		case src[off] == '\t':
+			edits = append(edits, analysis.TextEdit{Pos: start, End: start + 1})
+		}
+	}
+	return edits
+}
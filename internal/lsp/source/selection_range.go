@@ -0,0 +1,54 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+)
+
+// SelectionRange returns the ranges of the syntax nodes enclosing pos,
+// ordered from the innermost node (typically an identifier) outwards to the
+// file. Each range contains the one before it. A node whose range is the same
+// as that of its child, such as an expression statement wrapping a call, is
+// reported only once.
+func SelectionRange(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position) ([]protocol.Range, error) {
+	ctx, done := event.Start(ctx, "source.SelectionRange")
+	defer done()
+
+	// The syntax tree is sufficient here, so there is no need to type-check.
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	spn, err := pgf.Mapper.PointSpan(pos)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := spn.Range(pgf.Mapper.Converter)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.Start)
+	if len(path) == 0 {
+		return nil, fmt.Errorf("no enclosing position found for %v:%v", int(pos.Line), int(pos.Character))
+	}
+	var ranges []protocol.Range
+	for _, n := range path {
+		rng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, n.Pos(), n.End()).Range()
+		if err != nil {
+			return nil, err
+		}
+		if len(ranges) > 0 && ranges[len(ranges)-1] == rng {
+			continue
+		}
+		ranges = append(ranges, rng)
+	}
+	return ranges, nil
+}
@@ -361,6 +361,36 @@ func (r *runner) FoldingRanges(t *testing.T, spn span.Span) {
 	r.foldingRanges(t, "foldingRange-lineFolding", uri, string(data), ranges)
 }
 
+func (r *runner) SelectionRanges(t *testing.T, spn span.Span) {
+	uri := spn.URI()
+	fh, err := r.snapshot.GetFile(r.ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := r.data.Mapper(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := m.Location(spn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rngs, err := source.SelectionRange(r.ctx, r.snapshot, fh, loc.Range.Start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tests.SelectionRangesString(m, rngs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := string(r.data.Golden("selectionrange_"+tests.SpanName(spn), uri.Filename(), func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+	if diff := tests.Diff(t, want, got); diff != "" {
+		t.Errorf("selection ranges failed for %s, diff:\n%v", spn, diff)
+	}
+}
+
 func (r *runner) foldingRanges(t *testing.T, prefix string, uri span.URI, data string, ranges []*source.FoldingRangeInfo) {
 	t.Helper()
 	// Fold all ranges.
@@ -682,6 +712,32 @@ func (r *runner) Highlight(t *testing.T, src span.Span, locations []span.Span) {
 	}
 }
 
+func (r *runner) LinkedEditingRange(t *testing.T, src span.Span, want []span.Span) {
+	m, srcRng, err := spanToRange(r.data, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fh, err := r.snapshot.GetFile(r.ctx, src.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rngs, err := source.LinkedEditingRange(r.ctx, r.snapshot, fh, srcRng.Start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []span.Span
+	for _, rng := range rngs {
+		spn, err := m.RangeSpan(rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, spn)
+	}
+	if diff := tests.DiffSpans(want, got); diff != "" {
+		t.Errorf("linked editing ranges at %v failed:\n%s", src, diff)
+	}
+}
+
 func (r *runner) Hover(t *testing.T, src span.Span, text string) {
 	ctx := r.ctx
 	_, srcRng, err := spanToRange(r.data, src)
@@ -967,7 +1023,10 @@ func (r *runner) SuggestedFix(t *testing.T, spn span.Span, actionKinds []string,
 }
 func (r *runner) FunctionExtraction(t *testing.T, start span.Span, end span.Span) {}
 func (r *runner) MethodExtraction(t *testing.T, start span.Span, end span.Span)   {}
+func (r *runner) StructTags(t *testing.T, spn span.Span, tag tests.StructTag)     {}
 func (r *runner) CodeLens(t *testing.T, uri span.URI, want []protocol.CodeLens)   {}
+func (r *runner) RangeFormat(t *testing.T, start span.Span, end span.Span)        {}
+func (r *runner) OnTypeFormat(t *testing.T, spn span.Span, ch string)             {}
 func (r *runner) AddImport(t *testing.T, uri span.URI, expectedImport string)     {}
 
 func spanToRange(data *tests.Data, spn span.Span) (*protocol.ColumnMapper, protocol.Range, error) {
@@ -0,0 +1,344 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+)
+
+// A TagEdit describes a change of the tags of the fields of a struct type.
+type TagEdit struct {
+	// Add lists the keys of the tags added to the fields lacking them.
+	Add []string
+	// Remove lists the keys of the tags removed from the fields.
+	Remove []string
+	// Normalize lists the keys of the tags whose names are derived anew
+	// from the names of the fields. The tags of all the fields are also
+	// rewritten in the canonical form.
+	Normalize []string
+	// Case controls how the names are derived from the names of the fields.
+	Case StructTagCase
+	// OmitEmpty adds the omitempty option to the added and normalized tags.
+	OmitEmpty bool
+}
+
+// ModifyTags returns the edits changing the tags of the fields of the struct
+// type enclosing the given range.
+func ModifyTags(ctx context.Context, snapshot Snapshot, fh VersionedFileHandle, pRng protocol.Range, edit TagEdit) ([]protocol.TextDocumentEdit, error) {
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
+	if err != nil {
+		return nil, err
+	}
+	st := enclosingStructType(pgf, rng)
+	if st == nil {
+		return nil, fmt.Errorf("no struct type selected")
+	}
+	src, err := modifyTags(pgf, st, edit)
+	if err != nil {
+		return nil, err
+	}
+	if src == nil {
+		return nil, nil
+	}
+	edits, err := computeTextEdits(ctx, snapshot, pgf, string(src))
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.TextDocumentEdit{documentChange(fh, edits)}, nil
+}
+
+// CanModifyTags reports whether the edit changes the tags of the struct type
+// enclosing the given range.
+func CanModifyTags(pgf *ParsedGoFile, rng span.Range, edit TagEdit) bool {
+	st := enclosingStructType(pgf, rng)
+	if st == nil {
+		return false
+	}
+	src, err := modifyTags(pgf, st, edit)
+	return err == nil && src != nil
+}
+
+// enclosingStructType returns the innermost struct type with fields
+// enclosing rng, or declared by the type spec enclosing rng, or nil.
+func enclosingStructType(pgf *ParsedGoFile, rng span.Range) *ast.StructType {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.End)
+	for _, n := range path {
+		st, ok := n.(*ast.StructType)
+		if spec, isSpec := n.(*ast.TypeSpec); isSpec {
+			st, ok = spec.Type.(*ast.StructType)
+		}
+		if ok {
+			if len(st.Fields.List) == 0 {
+				return nil
+			}
+			return st
+		}
+	}
+	return nil
+}
+
+// modifyTags returns the source of the file with the tags of the fields of
+// st changed by the edit and the struct type formatted, or nil if the tags
+// are unchanged.
+func modifyTags(pgf *ParsedGoFile, st *ast.StructType, edit TagEdit) ([]byte, error) {
+	tok := pgf.Tok
+	start, end := tok.Offset(st.Pos()), tok.Offset(st.End())
+	var b strings.Builder
+	last := start
+	replace := func(from, to token.Pos, text string) {
+		b.Write(pgf.Src[last:tok.Offset(from)])
+		b.WriteString(text)
+		last = tok.Offset(to)
+	}
+	changed := false
+	for _, field := range st.Fields.List {
+		var name string
+		if len(field.Names) == 1 && field.Names[0].IsExported() {
+			name = field.Names[0].Name
+		}
+		var tags []structTag
+		if field.Tag != nil {
+			value, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			if tags, err = parseStructTag(value); err != nil {
+				return nil, fmt.Errorf("invalid tag of field %s: %v", fieldName(field), err)
+			}
+		}
+		newTags, ok := edit.apply(tags, name)
+		if !ok {
+			continue
+		}
+		switch {
+		case len(newTags) == 0:
+			if field.Tag != nil {
+				replace(field.Type.End(), field.Tag.End(), "")
+				changed = true
+			}
+		case field.Tag == nil:
+			replace(field.Type.End(), field.Type.End(), " "+formatStructTag(newTags))
+			changed = true
+		default:
+			if lit := formatStructTag(newTags); lit != field.Tag.Value {
+				replace(field.Tag.Pos(), field.Tag.End(), lit)
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return nil, nil
+	}
+	b.Write(pgf.Src[last:end])
+
+	// The struct type is formatted as a declaration so that the tags are
+	// aligned, and indented as the line on which it starts.
+	lineStart := tok.Offset(tok.LineStart(tok.Line(st.Pos())))
+	line := string(pgf.Src[lineStart:start])
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	const prefix = "type _ "
+	formatted, err := format.Source([]byte(indent + prefix + b.String()))
+	if err != nil {
+		return nil, err
+	}
+	formatted = formatted[len(indent)+len(prefix):]
+
+	var src []byte
+	src = append(src, pgf.Src[:start]...)
+	src = append(src, formatted...)
+	src = append(src, pgf.Src[end:]...)
+	return src, nil
+}
+
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	return types.ExprString(field.Type)
+}
+
+// apply returns the tags of a field changed by the edit, and whether the
+// tag of the field must be rewritten. Tags are only added to and
+// normalized in the fields declaring a single exported name.
+func (e TagEdit) apply(tags []structTag, name string) ([]structTag, bool) {
+	changed := false
+	var result []structTag
+	for _, t := range tags {
+		if containsString(e.Remove, t.key) {
+			changed = true
+			continue
+		}
+		if name != "" && containsString(e.Normalize, t.key) {
+			if value := e.value(name, t.value); value != t.value {
+				t.value = value
+				changed = true
+			}
+		}
+		result = append(result, t)
+	}
+	if name != "" {
+	add:
+		for _, key := range e.Add {
+			for _, t := range result {
+				if t.key == key {
+					continue add
+				}
+			}
+			result = append(result, structTag{key: key, value: e.value(name, "")})
+			changed = true
+		}
+	}
+	return result, changed || len(e.Normalize) > 0 && len(tags) > 0
+}
+
+// value returns the value of a tag of the field named name, derived from
+// its previous value old.
+func (e TagEdit) value(name, old string) string {
+	if old == "-" {
+		// The field is ignored.
+		return old
+	}
+	opts := strings.Split(old, ",")
+	opts[0] = e.Case.transform(name)
+	if e.OmitEmpty && !containsString(opts[1:], "omitempty") {
+		opts = append(opts, "omitempty")
+	}
+	return strings.Join(opts, ",")
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// transform returns the name of a tag derived from the name of a field.
+func (c StructTagCase) transform(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if c == StructTagCamel && i > 0 {
+			r, size := utf8.DecodeRuneInString(w)
+			w = string(unicode.ToUpper(r)) + w[size:]
+		}
+		words[i] = w
+	}
+	switch c {
+	case StructTagSnake:
+		return strings.Join(words, "_")
+	case StructTagKebab:
+		return strings.Join(words, "-")
+	default:
+		return strings.Join(words, "")
+	}
+}
+
+// splitWords splits a Go identifier into words at the underscores and at
+// the changes of case, keeping the digits with the preceding letters and
+// the initialisms together, as in "HTTPServer2Addr" and "user_ID".
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		prev := runes[i-1]
+		if !unicode.IsUpper(prev) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// A structTag is a key:"value" pair of a struct tag.
+type structTag struct {
+	key, value string
+}
+
+// parseStructTag parses a struct tag following the convention described by
+// reflect.StructTag.
+func parseStructTag(tag string) ([]structTag, error) {
+	var tags []structTag
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			return tags, nil
+		}
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, fmt.Errorf("bad syntax for struct tag pair")
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, fmt.Errorf("bad syntax for struct tag value")
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, fmt.Errorf("bad syntax for struct tag value")
+		}
+		tag = tag[i+1:]
+		tags = append(tags, structTag{key: key, value: value})
+	}
+}
+
+// formatStructTag returns the literal of a struct tag, with its pairs
+// separated by single spaces.
+func formatStructTag(tags []structTag) string {
+	var pairs []string
+	for _, t := range tags {
+		pairs = append(pairs, t.key+":"+strconv.Quote(t.value))
+	}
+	tag := strings.Join(pairs, " ")
+	if strconv.CanBackquote(tag) {
+		return "`" + tag + "`"
+	}
+	return strconv.Quote(tag)
+}
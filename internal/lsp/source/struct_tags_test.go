@@ -0,0 +1,63 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStructTagCase(t *testing.T) {
+	for _, test := range []struct {
+		name                string
+		camel, snake, kebab string
+	}{
+		{"ID", "id", "id", "id"},
+		{"UserID", "userId", "user_id", "user-id"},
+		{"HTTPServer", "httpServer", "http_server", "http-server"},
+		{"Server2Addr", "server2Addr", "server2_addr", "server2-addr"},
+		{"Max_Size", "maxSize", "max_size", "max-size"},
+		{"ÉtéDate", "étéDate", "été_date", "été-date"},
+	} {
+		for c, want := range map[StructTagCase]string{
+			StructTagCamel: test.camel,
+			StructTagSnake: test.snake,
+			StructTagKebab: test.kebab,
+		} {
+			if got := c.transform(test.name); got != want {
+				t.Errorf("%s name of %s = %q, want %q", c, test.name, got, want)
+			}
+		}
+	}
+}
+
+func TestParseStructTag(t *testing.T) {
+	for _, test := range []struct {
+		tag  string
+		want []structTag
+	}{
+		{``, nil},
+		{`json:"a,omitempty"`, []structTag{{"json", "a,omitempty"}}},
+		{` json:"a"   db:"b\"c" `, []structTag{{"json", "a"}, {"db", `b"c`}}},
+		{`json:a`, nil},
+		{`json:"a`, nil},
+		{`:"a"`, nil},
+	} {
+		got, err := parseStructTag(test.tag)
+		if test.want == nil && test.tag != "" {
+			if err == nil {
+				t.Errorf("parseStructTag(%q) succeeded, want an error", test.tag)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseStructTag(%q) failed: %v", test.tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseStructTag(%q) = %v, want %v", test.tag, got, test.want)
+		}
+	}
+}
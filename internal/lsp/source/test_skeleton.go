@@ -0,0 +1,389 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kent0106/gotools/go/ast/astutil"
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/imports"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/span"
+	"github.com/kent0106/gotools/internal/typeparams"
+	errors "golang.org/x/xerrors"
+)
+
+// AddTest returns the changes adding a table-driven test of the function or
+// method declared at pRng to the test file next to its file. If the test file
+// does not exist yet, the changes start with its creation, which the client
+// must support.
+func AddTest(ctx context.Context, snapshot Snapshot, fh VersionedFileHandle, pRng protocol.Range) ([]protocol.DocumentChanges, error) {
+	ctx, done := event.Start(ctx, "source.AddTest")
+	defer done()
+
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for AddTest: %w", err)
+	}
+	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
+	if err != nil {
+		return nil, err
+	}
+	fn, err := testedFunc(pkg, pgf, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	testURI := span.URIFromPath(strings.TrimSuffix(fh.URI().Filename(), ".go") + "_test.go")
+	testFH, err := snapshot.GetVersionedFile(ctx, testURI)
+	if err != nil {
+		return nil, err
+	}
+	tb := &testBuilder{
+		fn:    fn,
+		pkg:   pkg.GetTypes(),
+		names: make(map[string]string),
+	}
+	var testPGF *ParsedGoFile
+	if _, err := testFH.Read(); err == nil {
+		testPGF, err = snapshot.ParseGo(ctx, testFH, ParseFull)
+		if err != nil {
+			return nil, err
+		}
+		if testPGF.File.Name == nil {
+			return nil, errors.Errorf("%s has no package clause", testURI.Filename())
+		}
+		tb.external = testPGF.File.Name.Name != pkg.GetTypes().Name()
+		for _, imp := range testPGF.File.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			name := ""
+			if imp.Name != nil {
+				if imp.Name.Name == "_" {
+					continue
+				}
+				name = imp.Name.Name
+			}
+			tb.names[path] = name
+		}
+	}
+	if tb.external && !fn.Exported() {
+		return nil, errors.Errorf("%s is not exported and cannot be tested from the external test package", fn.Name())
+	}
+	if tb.external {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			if named, ok := Deref(recv.Type()).(*types.Named); ok && !named.Obj().Exported() {
+				return nil, errors.Errorf("%s is not exported and cannot be tested from the external test package", named.Obj().Name())
+			}
+		}
+	}
+
+	name := testName(fn)
+	if testPGF != nil {
+		for _, decl := range testPGF.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil && decl.Name.Name == name {
+				return nil, errors.Errorf("%s is already declared in %s", name, testURI.Filename())
+			}
+		}
+	}
+	test, err := tb.build(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		changes []protocol.DocumentChanges
+		edits   []protocol.TextEdit
+	)
+	if testPGF == nil {
+		if !supportsResourceOperation(snapshot, protocol.Create) {
+			return nil, errors.Errorf("%s does not exist and the client cannot create files", testURI.Filename())
+		}
+		changes = append(changes, protocol.DocumentChanges{
+			CreateFile: &protocol.CreateFile{
+				Kind: string(protocol.Create),
+				URI:  protocol.URIFromSpanURI(testURI),
+			},
+		})
+		src, err := addImports(snapshot, []byte(fmt.Sprintf("package %s\n", pkg.GetTypes().Name())), tb.added)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, protocol.TextEdit{
+			NewText: string(src) + "\n" + test,
+		})
+	} else {
+		if len(tb.added) > 0 {
+			importEdits, err := addImportEdits(snapshot, testPGF, tb.added)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, importEdits...)
+		}
+		end := testPGF.Tok.Pos(testPGF.Tok.Size())
+		rng, err := NewMappedRange(snapshot.FileSet(), testPGF.Mapper, end, end).Range()
+		if err != nil {
+			return nil, err
+		}
+		text := "\n" + test
+		if !bytes.HasSuffix(testPGF.Src, []byte("\n")) {
+			text = "\n" + text
+		}
+		edits = append(edits, protocol.TextEdit{Range: rng, NewText: text})
+	}
+	changes = append(changes, protocol.DocumentChanges{
+		TextDocumentEdit: &protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				Version: testFH.Version(),
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+					URI: protocol.URIFromSpanURI(testURI),
+				},
+			},
+			Edits: edits,
+		},
+	})
+	return changes, nil
+}
+
+// CanAddTest reports whether a test may be generated for the function or
+// method whose declaration header contains rng, and if so returns the title
+// of the action generating it.
+func CanAddTest(pkg Package, pgf *ParsedGoFile, rng span.Range) (string, bool) {
+	if strings.HasSuffix(pgf.URI.Filename(), "_test.go") {
+		return "", false
+	}
+	fn, err := testedFunc(pkg, pgf, rng)
+	if err != nil {
+		return "", false
+	}
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		if named, ok := Deref(recv.Type()).(*types.Named); ok {
+			name = named.Obj().Name() + "." + name
+		}
+	}
+	return fmt.Sprintf("Add test for %s", name), true
+}
+
+// testedFunc returns the function or method whose declaration header, from
+// the func keyword to the opening brace of its body, contains rng.
+func testedFunc(pkg Package, pgf *ParsedGoFile, rng span.Range) (*types.Func, error) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.End)
+	var decl *ast.FuncDecl
+	for _, n := range path {
+		if n, ok := n.(*ast.FuncDecl); ok {
+			decl = n
+			break
+		}
+	}
+	if decl == nil || decl.Body == nil || rng.Start > decl.Body.Lbrace {
+		return nil, errors.Errorf("no function declaration selected")
+	}
+	fn, ok := pkg.GetTypesInfo().Defs[decl.Name].(*types.Func)
+	if !ok {
+		return nil, errors.Errorf("no type information for %s", decl.Name.Name)
+	}
+	if decl.Recv == nil && (fn.Name() == "main" || fn.Name() == "init") {
+		return nil, errors.Errorf("cannot test %s", fn.Name())
+	}
+	sig := fn.Type().(*types.Signature)
+	if typeparams.ForSignature(sig).Len() > 0 || typeparams.RecvTypeParams(sig).Len() > 0 {
+		return nil, errors.Errorf("cannot add a test for the generic function %s", fn.Name())
+	}
+	return fn, nil
+}
+
+// testName returns the name of the test of fn, following the convention of
+// the examples of the testing package: TestF for a function F, TestT_M for a
+// method M of a type T, and Test_f for an unexported function f.
+func testName(fn *types.Func) string {
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		if named, ok := Deref(recv.Type()).(*types.Named); ok {
+			name = named.Obj().Name() + "_" + name
+		}
+	}
+	if r, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(r) {
+		name = "_" + name
+	}
+	return "Test" + name
+}
+
+// A testBuilder generates the table-driven test of a function.
+type testBuilder struct {
+	fn       *types.Func
+	pkg      *types.Package       // the package declaring fn
+	external bool                 // whether the test is in an external test package
+	names    map[string]string    // the names of the imports of the test file, keyed by path
+	added    []imports.ImportInfo // the imports to add to the test file
+}
+
+// qualifier returns the name referring to p in the test file, adding an
+// import of p if it is not imported yet.
+func (tb *testBuilder) qualifier(p *types.Package) string {
+	if p == tb.pkg && !tb.external {
+		return ""
+	}
+	return tb.importName(p.Path(), p.Name())
+}
+
+// importName returns the name referring to the package with the given path
+// and declared name in the test file, adding an import of it if needed.
+func (tb *testBuilder) importName(path, name string) string {
+	if local, ok := tb.names[path]; ok {
+		switch local {
+		case "":
+			return name
+		case ".":
+			return ""
+		}
+		return local
+	}
+	tb.names[path] = ""
+	tb.added = append(tb.added, imports.ImportInfo{ImportPath: path})
+	return name
+}
+
+// A testField is a field of the struct describing the test cases.
+type testField struct {
+	name, typ string
+}
+
+// build returns the formatted declaration of the test of tb.fn with the
+// given name.
+func (tb *testBuilder) build(name string) (string, error) {
+	sig := tb.fn.Type().(*types.Signature)
+	var (
+		fields  = []testField{{"name", "string"}}
+		used    = map[string]bool{"name": true}
+		args    []string
+		callee  = tb.fn.Name()
+		display = tb.fn.Name()
+	)
+	// field adds a field for a parameter or the receiver, whose name is
+	// changed if it is missing or used by another field.
+	field := func(v *types.Var, alt string) string {
+		n := v.Name()
+		if n == "" || n == "_" || used[n] || strings.HasPrefix(n, "want") {
+			n = alt
+		}
+		used[n] = true
+		fields = append(fields, testField{n, types.TypeString(v.Type(), tb.qualifier)})
+		return n
+	}
+	if recv := sig.Recv(); recv != nil {
+		callee = "tt." + field(recv, "recv") + "." + callee
+		if named, ok := Deref(recv.Type()).(*types.Named); ok {
+			display = named.Obj().Name() + "." + display
+		}
+	} else if q := tb.qualifier(tb.pkg); q != "" {
+		callee = q + "." + callee
+	}
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		n := field(params.At(i), fmt.Sprintf("arg%d", i))
+		if sig.Variadic() && i == params.Len()-1 {
+			n += "..."
+		}
+		args = append(args, "tt."+n)
+	}
+
+	// The results other than a final error are compared with the wanted
+	// ones, and the error is checked against wantErr.
+	results := sig.Results()
+	var wants []types.Type
+	hasErr := false
+	for i := 0; i < results.Len(); i++ {
+		typ := results.At(i).Type()
+		if i == results.Len()-1 && types.Identical(typ, types.Universe.Lookup("error").Type()) {
+			hasErr = true
+			break
+		}
+		wants = append(wants, typ)
+	}
+	var gots []string
+	for i, typ := range wants {
+		suffix := ""
+		if i > 0 {
+			suffix = strconv.Itoa(i)
+		}
+		gots = append(gots, "got"+suffix)
+		fields = append(fields, testField{"want" + suffix, types.TypeString(typ, tb.qualifier)})
+	}
+	if hasErr {
+		fields = append(fields, testField{"wantErr", "bool"})
+	}
+
+	var b bytes.Buffer
+	testing := tb.importName("testing", "testing")
+	fmt.Fprintf(&b, "func %s(t *%s.T) {\n", name, testing)
+	fmt.Fprintf(&b, "tests := []struct {\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%s %s\n", f.name, f.typ)
+	}
+	fmt.Fprintf(&b, "}{\n// TODO: Add test cases.\n}\n")
+	fmt.Fprintf(&b, "for _, tt := range tests {\n")
+	fmt.Fprintf(&b, "t.Run(tt.name, func(t *%s.T) {\n", testing)
+	call := fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", "))
+	lhs := gots
+	if hasErr {
+		lhs = append(lhs, "err")
+	}
+	switch {
+	case len(lhs) == 0:
+		fmt.Fprintf(&b, "%s\n", call)
+	case len(gots) == 0:
+		fmt.Fprintf(&b, "if err := %s; (err != nil) != tt.wantErr {\n", call)
+		fmt.Fprintf(&b, "t.Errorf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\n}\n", display)
+	default:
+		// A single result is declared in the condition comparing it.
+		init := fmt.Sprintf("%s := %s", strings.Join(lhs, ", "), call)
+		if len(lhs) > 1 {
+			fmt.Fprintf(&b, "%s\n", init)
+			init = ""
+		}
+		if hasErr {
+			fmt.Fprintf(&b, "if (err != nil) != tt.wantErr {\n")
+			fmt.Fprintf(&b, "t.Errorf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\nreturn\n}\n", display)
+		}
+		for i, got := range gots {
+			want := "want" + strings.TrimPrefix(got, "got")
+			if init != "" {
+				fmt.Fprintf(&b, "if %s; ", init)
+			} else {
+				fmt.Fprintf(&b, "if ")
+			}
+			if _, ok := wants[i].Underlying().(*types.Basic); ok {
+				fmt.Fprintf(&b, "%s != tt.%s {\n", got, want)
+			} else {
+				fmt.Fprintf(&b, "!%s.DeepEqual(%s, tt.%s) {\n", tb.importName("reflect", "reflect"), got, want)
+			}
+			label := ""
+			if i > 0 {
+				label = " " + got
+			}
+			fmt.Fprintf(&b, "t.Errorf(\"%s()%s = %%v, want %%v\", %s, tt.%s)\n}\n", display, label, got, want)
+		}
+	}
+	fmt.Fprintf(&b, "})\n}\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return "", errors.Errorf("formatting %s: %w", name, err)
+	}
+	return string(src), nil
+}
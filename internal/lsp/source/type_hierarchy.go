@@ -0,0 +1,179 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"github.com/kent0106/gotools/internal/event"
+	"github.com/kent0106/gotools/internal/lsp/protocol"
+	"github.com/kent0106/gotools/internal/typeparams"
+	errors "golang.org/x/xerrors"
+)
+
+// PrepareTypeHierarchy returns the TypeHierarchyItem of the named type at the
+// position within the file.
+func PrepareTypeHierarchy(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "source.PrepareTypeHierarchy")
+	defer done()
+
+	qo, named, err := namedTypeAt(ctx, snapshot, fh, pp)
+	if err != nil || named == nil {
+		return nil, err
+	}
+	item, err := toProtocolTypeHierarchyItem(snapshot, qo.pkg, named)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.TypeHierarchyItem{item}, nil
+}
+
+// Supertypes returns the interfaces implemented by the named type at the
+// position within the file, which may itself be an interface.
+func Supertypes(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "source.Supertypes")
+	defer done()
+
+	_, named, err := namedTypeAt(ctx, snapshot, fh, pp)
+	if err != nil || named == nil {
+		return nil, err
+	}
+	T := ensurePointer(named)
+	return relatedTypes(ctx, snapshot, named, func(cand *types.Named) bool {
+		return IsInterface(cand) && types.AssignableTo(T, cand)
+	})
+}
+
+// Subtypes returns the types implementing the interface at the position
+// within the file, including the interfaces embedding it. A concrete type or
+// an empty interface has no subtypes.
+func Subtypes(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "source.Subtypes")
+	defer done()
+
+	_, named, err := namedTypeAt(ctx, snapshot, fh, pp)
+	if err != nil || named == nil {
+		return nil, err
+	}
+	if iface, ok := named.Underlying().(*types.Interface); !ok || iface.NumMethods() == 0 {
+		return nil, nil
+	}
+	return relatedTypes(ctx, snapshot, named, func(cand *types.Named) bool {
+		return types.AssignableTo(ensurePointer(cand), named)
+	})
+}
+
+// namedTypeAt returns the named type referenced at the position within the
+// file, or nil if there is none.
+func namedTypeAt(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position) (qualifiedObject, *types.Named, error) {
+	qos, err := qualifiedObjsAtProtocolPos(ctx, snapshot, fh.URI(), pp)
+	if err != nil {
+		if errors.Is(err, errNoObjectFound) || errors.Is(err, errBuiltin) {
+			return qualifiedObject{}, nil, nil
+		}
+		return qualifiedObject{}, nil, err
+	}
+	qo := qos[0]
+	if _, ok := qo.obj.(*types.TypeName); !ok {
+		return qo, nil, nil
+	}
+	named, ok := qo.obj.Type().(*types.Named)
+	if !ok || typeparams.ForNamed(named).Len() > 0 {
+		return qo, nil, nil
+	}
+	// Follow an alias to the type it denotes.
+	if named.Obj() != qo.obj {
+		qo.obj = named.Obj()
+		qo.pkg, err = FindPackageFromPos(ctx, snapshot, qo.obj.Pos())
+		if err != nil {
+			return qo, nil, err
+		}
+	}
+	return qo, named, nil
+}
+
+// relatedTypes returns the items of the named types of the known packages,
+// other than named itself, that satisfy related. The interfaces with an empty
+// method set are excluded, as every type implements them.
+func relatedTypes(ctx context.Context, snapshot Snapshot, named *types.Named, related func(*types.Named) bool) ([]protocol.TypeHierarchyItem, error) {
+	knownPkgs, err := snapshot.KnownPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		items []protocol.TypeHierarchyItem
+		fset  = snapshot.FileSet()
+		seen  = make(map[token.Position]bool)
+	)
+	seen[fset.Position(named.Obj().Pos())] = true
+	for _, pkg := range knownPkgs {
+		for _, obj := range pkg.GetTypesInfo().Defs {
+			obj, ok := obj.(*types.TypeName)
+			// We ignore aliases 'type M = N' to avoid duplicate reporting
+			// of the Named type N.
+			if !ok || obj.IsAlias() || obj.Pkg() != pkg.GetTypes() {
+				continue
+			}
+			cand, ok := obj.Type().(*types.Named)
+			if !ok || typeparams.ForNamed(cand).Len() > 0 {
+				continue
+			}
+			if IsInterface(cand) && cand.Underlying().(*types.Interface).NumMethods() == 0 {
+				continue
+			}
+			pos := fset.Position(obj.Pos())
+			if seen[pos] || !related(cand) {
+				continue
+			}
+			seen[pos] = true
+			item, err := toProtocolTypeHierarchyItem(snapshot, pkg, cand)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		ii, ij := items[i], items[j]
+		if ii.URI == ij.URI {
+			return protocol.CompareRange(ii.SelectionRange, ij.SelectionRange) < 0
+		}
+		return ii.URI < ij.URI
+	})
+	return items, nil
+}
+
+func toProtocolTypeHierarchyItem(snapshot Snapshot, pkg Package, named *types.Named) (protocol.TypeHierarchyItem, error) {
+	obj := named.Obj()
+	declMappedRange, err := objToMappedRange(snapshot, pkg, obj)
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+	rng, err := declMappedRange.Range()
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+	kind := protocol.Class
+	switch named.Underlying().(type) {
+	case *types.Interface:
+		kind = protocol.Interface
+	case *types.Struct:
+		kind = protocol.Struct
+	}
+	return protocol.TypeHierarchyItem{
+		Name:           obj.Name(),
+		Kind:           kind,
+		Tags:           []protocol.SymbolTag{},
+		Detail:         fmt.Sprintf("%s • %s", obj.Pkg().Path(), filepath.Base(declMappedRange.URI().Filename())),
+		URI:            protocol.DocumentURI(declMappedRange.URI()),
+		Range:          rng,
+		SelectionRange: rng,
+	}, nil
+}
@@ -450,6 +450,27 @@ func CompareURI(left, right span.URI) int {
 	return 1
 }
 
+// ExcludedByDirectoryFilters reports whether path, a slash-separated path
+// relative to the workspace folder, is excluded by the given directory
+// filters, as described by the directoryFilters setting.
+func ExcludedByDirectoryFilters(path string, filters []string) bool {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	excluded := false
+	for _, filter := range filters {
+		op, prefix := filter[0], filter[1:]
+		// Non-empty prefixes have to be precise directory matches.
+		if prefix != "" {
+			prefix = prefix + "/"
+			path = path + "/"
+		}
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		excluded = op == '-'
+	}
+	return excluded
+}
+
 // InDir checks whether path is in the file tree rooted at dir.
 // InDir makes some effort to succeed even in the presence of symbolic links.
 //
@@ -522,6 +543,17 @@ func inDirLex(dir, path string) bool {
 	}
 }
 
+// supportsResourceOperation reports whether the client of the snapshot can
+// apply resource operations of the given kind in workspace edits.
+func supportsResourceOperation(snapshot Snapshot, kind protocol.ResourceOperationKind) bool {
+	for _, k := range snapshot.View().Options().SupportedResourceOperations {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
 // IsValidImport returns whether importPkgPath is importable
 // by pkgPath
 func IsValidImport(pkgPath, importPkgPath string) bool {
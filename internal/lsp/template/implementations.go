@@ -150,6 +150,60 @@ func References(ctx context.Context, snapshot source.Snapshot, fh source.FileHan
 	return ans, nil
 }
 
+// LinkedEditingRange returns the ranges in the template file that must be
+// edited together with the template name at loc: the name in a
+// {{define "x"}} (or {{block "x"}}) action and in every {{template "x"}}
+// action of the file.
+func LinkedEditingRange(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, loc protocol.Position) ([]protocol.Range, error) {
+	if skipTemplates(snapshot) {
+		return nil, nil
+	}
+	buf, err := fh.Read()
+	if err != nil {
+		return nil, err
+	}
+	p := parseBuffer(buf)
+	if p.ParseErr != nil {
+		return nil, nil
+	}
+	var ans []protocol.Range
+	for _, s := range p.linkedTemplateNames(p.FromPosition(loc)) {
+		ans = append(ans, p.Range(s.start, s.length))
+	}
+	return ans, nil
+}
+
+// linkedTemplateNames returns the occurrences of the template name at pos,
+// in its definition and in template invocations, or nil if there is no
+// template name at pos.
+func (p *Parsed) linkedTemplateNames(pos int) []symbol {
+	isTemplateName := func(s symbol) bool {
+		return s.kind == protocol.Namespace || s.kind == protocol.Package
+	}
+	var name string
+	for _, s := range p.SymsAtPos(pos) {
+		if isTemplateName(s) {
+			name = s.name
+			break
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	var ans []symbol
+	for _, s := range p.symbols {
+		if s.name != name || !isTemplateName(s) {
+			continue
+		}
+		// {{block "x"}} both defines and invokes x.
+		if len(ans) > 0 && ans[len(ans)-1].start == s.start {
+			continue
+		}
+		ans = append(ans, s)
+	}
+	return ans
+}
+
 func SemanticTokens(ctx context.Context, snapshot source.Snapshot, spn span.URI, add func(line, start, len uint32), d func() []uint32) (*protocol.SemanticTokens, error) {
 	if skipTemplates(snapshot) {
 		return nil, nil
@@ -53,6 +53,33 @@ func TestSymbols(t *testing.T) {
 	}
 }
 
+func TestLinkedTemplateNames(t *testing.T) {
+	p := parseBuffer([]byte(`{{define "zzz"}}{{.}}{{end}}
+{{template "zzz"}}{{block "bbb" .}}{{template "zzz"}}{{end}}{{template "bbb"}}`))
+	if p.ParseErr != nil {
+		t.Fatal(p.ParseErr)
+	}
+	tests := []struct {
+		pos  int
+		want string
+	}{
+		{10, "{10,3,zzz,Namespace,true} {41,3,zzz,Package,false} {76,3,zzz,Package,false}"},
+		{42, "{10,3,zzz,Namespace,true} {41,3,zzz,Package,false} {76,3,zzz,Package,false}"},
+		{56, "{56,3,bbb,Namespace,true} {101,3,bbb,Package,false}"},
+		{102, "{56,3,bbb,Namespace,true} {101,3,bbb,Package,false}"},
+		{20, ""},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, s := range p.linkedTemplateNames(test.pos) {
+			got = append(got, s.String())
+		}
+		if strings.Join(got, " ") != test.want {
+			t.Errorf("at %d: got %v, want %s", test.pos, got, test.want)
+		}
+	}
+}
+
 func TestWordAt(t *testing.T) {
 	want := []string{"", "", "$A", "$A", "", "", "", "", "", "",
 		"", "", "", "if", "if", "", "$A", "$A", "", "",
@@ -0,0 +1,9 @@
+package cyclic
+
+import "github.com/kent0106/gotools/internal/lsp/fillswitch"
+
+// Octagon is not a case of the switches of package fillswitch, which cannot
+// import this package.
+type Octagon fillswitch.Square
+
+func (Octagon) Sides() int { return 8 }
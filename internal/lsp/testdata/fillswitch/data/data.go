@@ -0,0 +1,19 @@
+package data
+
+type Direction int
+
+const (
+	North Direction = iota
+	East
+	South
+	West
+	unknown
+)
+
+type Shape interface {
+	Sides() int
+}
+
+type Triangle struct{}
+
+func (Triangle) Sides() int { return 3 }
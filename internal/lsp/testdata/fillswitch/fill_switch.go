@@ -0,0 +1,56 @@
+package fillswitch
+
+import "github.com/kent0106/gotools/internal/lsp/fillswitch/data"
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+	Crimson = Red
+)
+
+func _(c Color) {
+	switch c { //@suggestedfix("switch", "refactor.rewrite")
+	case Green:
+	}
+}
+
+func _(c Color) {
+	switch c { //@suggestedfix("switch", "refactor.rewrite")
+	case Red:
+		return
+	default:
+		panic(c)
+	}
+}
+
+func _(d data.Direction) {
+	switch d {} //@suggestedfix("switch", "refactor.rewrite")
+}
+
+type Shape interface {
+	Area() float64
+}
+
+type Square struct{}
+
+func (Square) Area() float64 { return 0 }
+func (Square) Sides() int    { return 4 }
+
+type Circle struct{}
+
+func (*Circle) Area() float64 { return 0 }
+
+func _(s Shape) {
+	switch s := s.(type) { //@suggestedfix("switch", "refactor.rewrite")
+	case Square:
+		_ = s
+	}
+}
+
+func _(s data.Shape) {
+	switch s.(type) { //@suggestedfix("switch", "refactor.rewrite")
+	}
+}
@@ -0,0 +1,5 @@
+package polygon
+
+type Hexagon struct{}
+
+func (Hexagon) Sides() int { return 6 }
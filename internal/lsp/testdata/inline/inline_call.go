@@ -0,0 +1,39 @@
+package inline
+
+import "fmt"
+
+func add(a, b int) int {
+	return a + b
+}
+
+func next() int {
+	return 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func logTwice(s string) {
+	fmt.Println(s)
+	fmt.Println(s)
+}
+
+type counter struct{ n int }
+
+func (c *counter) inc() {
+	c.n++
+}
+
+func _() {
+	_ = add(1, 2) * 3 //@suggestedfix("add", "refactor.inline")
+	x := add(next(), 2) //@suggestedfix("add", "refactor.inline")
+	var y int
+	y = abs(x) //@suggestedfix("abs", "refactor.inline")
+	logTwice(fmt.Sprint(y)) //@suggestedfix("logTwice", "refactor.inline")
+	c := &counter{}
+	c.inc() //@suggestedfix("inc", "refactor.inline")
+}
@@ -0,0 +1,18 @@
+package inline
+
+var count int
+
+func g() {
+	count++
+}
+
+func f(a int) {
+	g()
+	println(a)
+}
+
+func _() {
+	f(count) //@suggestedfix("f", "refactor.inline")
+	n := count
+	f(n) //@suggestedfix("f", "refactor.inline")
+}
@@ -0,0 +1,16 @@
+package inline
+
+import "fmt"
+
+type point struct{ x, y int }
+
+func _(a, b int, p point) {
+	sum := a + b
+	fmt.Println(sum * 2) //@suggestedfix("sum", "refactor.inline")
+
+	var ratio float64 = 1
+	fmt.Println(ratio / 2) //@suggestedfix("ratio", "refactor.inline")
+
+	px := p.x
+	fmt.Println(px, px+1) //@suggestedfix("px", "refactor.inline")
+}
@@ -0,0 +1,21 @@
+package linkedediting
+
+func _(xs []int) {
+Outer: //@mark(outerDef, "Outer"),linkedediting(outerDef, outerDef, outerBreak, outerContinue)
+	for _, x := range xs {
+		for range xs {
+			if x > 0 {
+				continue Outer //@mark(outerContinue, "Outer"),linkedediting(outerContinue, outerDef, outerBreak, outerContinue)
+			}
+			break Outer //@mark(outerBreak, "Outer")
+		}
+	}
+
+	func() {
+	Outer: //@mark(innerDef, "Outer"),linkedediting(innerDef, innerDef, innerGoto)
+		goto Outer //@mark(innerGoto, "Outer")
+	}()
+
+	x := 1 //@mark(notLabel, "x"),linkedediting(notLabel)
+	_ = x
+}
@@ -0,0 +1,12 @@
+package structtags
+
+type Person struct { //@tags("Person", "json", "snake")
+	FirstName string
+	LastName  string `json:"last"`
+	Age       int
+}
+
+type Item struct { //@tags("Item", "yaml,xml", "kebab")
+	ItemID    int `db:"id"`
+	UnitPrice float64
+}
@@ -66,14 +66,19 @@ type CaseSensitiveCompletions map[span.Span][]Completion
 type RankCompletions map[span.Span][]Completion
 type FoldingRanges []span.Span
 type Formats []span.Span
+type RangeFormats map[span.Span]span.Span
+type OnTypeFormats map[span.Span]string
+type SelectionRanges []span.Span
 type Imports []span.Span
 type SemanticTokens []span.Span
 type SuggestedFixes map[span.Span][]string
 type FunctionExtractions map[span.Span]span.Span
 type MethodExtractions map[span.Span]span.Span
+type StructTags map[span.Span]StructTag
 type Definitions map[span.Span]Definition
 type Implementations map[span.Span][]span.Span
 type Highlights map[span.Span][]span.Span
+type LinkedEditingRanges map[span.Span][]span.Span
 type References map[span.Span][]span.Span
 type Renames map[span.Span]string
 type PrepareRenames map[span.Span]*source.PrepareItem
@@ -102,14 +107,19 @@ type Data struct {
 	RankCompletions          RankCompletions
 	FoldingRanges            FoldingRanges
 	Formats                  Formats
+	RangeFormats             RangeFormats
+	OnTypeFormats            OnTypeFormats
+	SelectionRanges          SelectionRanges
 	Imports                  Imports
 	SemanticTokens           SemanticTokens
 	SuggestedFixes           SuggestedFixes
 	FunctionExtractions      FunctionExtractions
 	MethodExtractions        MethodExtractions
+	StructTags               StructTags
 	Definitions              Definitions
 	Implementations          Implementations
 	Highlights               Highlights
+	LinkedEditingRanges      LinkedEditingRanges
 	References               References
 	Renames                  Renames
 	PrepareRenames           PrepareRenames
@@ -147,14 +157,19 @@ type Tests interface {
 	RankCompletion(*testing.T, span.Span, Completion, CompletionItems)
 	FoldingRanges(*testing.T, span.Span)
 	Format(*testing.T, span.Span)
+	RangeFormat(*testing.T, span.Span, span.Span)
+	OnTypeFormat(*testing.T, span.Span, string)
+	SelectionRanges(*testing.T, span.Span)
 	Import(*testing.T, span.Span)
 	SemanticTokens(*testing.T, span.Span)
 	SuggestedFix(*testing.T, span.Span, []string, int)
 	FunctionExtraction(*testing.T, span.Span, span.Span)
 	MethodExtraction(*testing.T, span.Span, span.Span)
+	StructTags(*testing.T, span.Span, StructTag)
 	Definition(*testing.T, span.Span, Definition)
 	Implementation(*testing.T, span.Span, []span.Span)
 	Highlight(*testing.T, span.Span, []span.Span)
+	LinkedEditingRange(*testing.T, span.Span, []span.Span)
 	References(*testing.T, span.Span, []span.Span)
 	Rename(*testing.T, span.Span, string)
 	PrepareRename(*testing.T, span.Span, *source.PrepareItem)
@@ -222,6 +237,11 @@ type CallHierarchyResult struct {
 	IncomingCalls, OutgoingCalls []protocol.CallHierarchyItem
 }
 
+type StructTag struct {
+	Add  string
+	Case string
+}
+
 type Link struct {
 	Src          span.Span
 	Target       string
@@ -245,6 +265,7 @@ func DefaultOptions(o *source.Options) {
 			protocol.QuickFix:              true,
 			protocol.RefactorRewrite:       true,
 			protocol.RefactorExtract:       true,
+			protocol.RefactorInline:        true,
 			protocol.SourceFixAll:          true,
 		},
 		source.Mod: {
@@ -299,12 +320,16 @@ func load(t testing.TB, mode string, dir string) *Data {
 		Definitions:              make(Definitions),
 		Implementations:          make(Implementations),
 		Highlights:               make(Highlights),
+		LinkedEditingRanges:      make(LinkedEditingRanges),
 		References:               make(References),
 		Renames:                  make(Renames),
 		PrepareRenames:           make(PrepareRenames),
 		SuggestedFixes:           make(SuggestedFixes),
 		FunctionExtractions:      make(FunctionExtractions),
 		MethodExtractions:        make(MethodExtractions),
+		StructTags:               make(StructTags),
+		RangeFormats:             make(RangeFormats),
+		OnTypeFormats:            make(OnTypeFormats),
 		Symbols:                  make(Symbols),
 		symbolsChildren:          make(SymbolsChildren),
 		symbolInformation:        make(SymbolInformation),
@@ -458,6 +483,9 @@ func load(t testing.TB, mode string, dir string) *Data {
 		"snippet":         datum.collectCompletionSnippets,
 		"fold":            datum.collectFoldingRanges,
 		"format":          datum.collectFormats,
+		"rangeformat":     datum.collectRangeFormats,
+		"ontypeformat":    datum.collectOnTypeFormats,
+		"selectionrange":  datum.collectSelectionRanges,
 		"import":          datum.collectImports,
 		"semantic":        datum.collectSemanticTokens,
 		"godef":           datum.collectDefinitions,
@@ -466,6 +494,7 @@ func load(t testing.TB, mode string, dir string) *Data {
 		"hoverdef":        datum.collectHoverDefinitions,
 		"hover":           datum.collectHovers,
 		"highlight":       datum.collectHighlights,
+		"linkedediting":   datum.collectLinkedEditingRanges,
 		"refs":            datum.collectReferences,
 		"rename":          datum.collectRenames,
 		"prepare":         datum.collectPrepareRenames,
@@ -475,6 +504,7 @@ func load(t testing.TB, mode string, dir string) *Data {
 		"suggestedfix":    datum.collectSuggestedFixes,
 		"extractfunc":     datum.collectFunctionExtractions,
 		"extractmethod":   datum.collectMethodExtractions,
+		"tags":            datum.collectStructTags,
 		"incomingcalls":   datum.collectIncomingCalls,
 		"outgoingcalls":   datum.collectOutgoingCalls,
 		"addimport":       datum.collectAddImports,
@@ -637,6 +667,36 @@ func Run(t *testing.T, tests Tests, data *Data) {
 		}
 	})
 
+	t.Run("RangeFormat", func(t *testing.T) {
+		t.Helper()
+		for start, end := range data.RangeFormats {
+			t.Run(SpanName(start), func(t *testing.T) {
+				t.Helper()
+				tests.RangeFormat(t, start, end)
+			})
+		}
+	})
+
+	t.Run("OnTypeFormat", func(t *testing.T) {
+		t.Helper()
+		for spn, ch := range data.OnTypeFormats {
+			t.Run(SpanName(spn), func(t *testing.T) {
+				t.Helper()
+				tests.OnTypeFormat(t, spn, ch)
+			})
+		}
+	})
+
+	t.Run("SelectionRanges", func(t *testing.T) {
+		t.Helper()
+		for _, spn := range data.SelectionRanges {
+			t.Run(SpanName(spn), func(t *testing.T) {
+				t.Helper()
+				tests.SelectionRanges(t, spn)
+			})
+		}
+	})
+
 	t.Run("Import", func(t *testing.T) {
 		t.Helper()
 		for _, spn := range data.Imports {
@@ -699,6 +759,16 @@ func Run(t *testing.T, tests Tests, data *Data) {
 		}
 	})
 
+	t.Run("StructTags", func(t *testing.T) {
+		t.Helper()
+		for spn, tag := range data.StructTags {
+			t.Run(SpanName(spn), func(t *testing.T) {
+				t.Helper()
+				tests.StructTags(t, spn, tag)
+			})
+		}
+	})
+
 	t.Run("Definition", func(t *testing.T) {
 		t.Helper()
 		for spn, d := range data.Definitions {
@@ -735,6 +805,16 @@ func Run(t *testing.T, tests Tests, data *Data) {
 		}
 	})
 
+	t.Run("LinkedEditingRange", func(t *testing.T) {
+		t.Helper()
+		for pos, locations := range data.LinkedEditingRanges {
+			t.Run(SpanName(pos), func(t *testing.T) {
+				t.Helper()
+				tests.LinkedEditingRange(t, pos, locations)
+			})
+		}
+	})
+
 	t.Run("Hover", func(t *testing.T) {
 		t.Helper()
 		for pos, info := range data.Hovers {
@@ -925,14 +1005,19 @@ func checkData(t *testing.T, data *Data) {
 	fmt.Fprintf(buf, "DiagnosticsCount = %v\n", diagnosticsCount)
 	fmt.Fprintf(buf, "FoldingRangesCount = %v\n", len(data.FoldingRanges))
 	fmt.Fprintf(buf, "FormatCount = %v\n", len(data.Formats))
+	fmt.Fprintf(buf, "RangeFormatCount = %v\n", len(data.RangeFormats))
+	fmt.Fprintf(buf, "OnTypeFormatCount = %v\n", len(data.OnTypeFormats))
+	fmt.Fprintf(buf, "SelectionRangesCount = %v\n", len(data.SelectionRanges))
 	fmt.Fprintf(buf, "ImportCount = %v\n", len(data.Imports))
 	fmt.Fprintf(buf, "SemanticTokenCount = %v\n", len(data.SemanticTokens))
 	fmt.Fprintf(buf, "SuggestedFixCount = %v\n", len(data.SuggestedFixes))
 	fmt.Fprintf(buf, "FunctionExtractionCount = %v\n", len(data.FunctionExtractions))
 	fmt.Fprintf(buf, "MethodExtractionCount = %v\n", len(data.MethodExtractions))
+	fmt.Fprintf(buf, "StructTagsCount = %v\n", len(data.StructTags))
 	fmt.Fprintf(buf, "DefinitionsCount = %v\n", definitionCount)
 	fmt.Fprintf(buf, "TypeDefinitionsCount = %v\n", typeDefinitionCount)
 	fmt.Fprintf(buf, "HighlightsCount = %v\n", len(data.Highlights))
+	fmt.Fprintf(buf, "LinkedEditingRangesCount = %v\n", len(data.LinkedEditingRanges))
 	fmt.Fprintf(buf, "ReferencesCount = %v\n", len(data.References))
 	fmt.Fprintf(buf, "RenamesCount = %v\n", len(data.Renames))
 	fmt.Fprintf(buf, "PrepareRenamesCount = %v\n", len(data.PrepareRenames))
@@ -1138,6 +1223,18 @@ func (data *Data) collectFormats(spn span.Span) {
 	data.Formats = append(data.Formats, spn)
 }
 
+func (data *Data) collectRangeFormats(start span.Span, end span.Span) {
+	data.RangeFormats[start] = end
+}
+
+func (data *Data) collectOnTypeFormats(spn span.Span, ch string) {
+	data.OnTypeFormats[spn] = ch
+}
+
+func (data *Data) collectSelectionRanges(spn span.Span) {
+	data.SelectionRanges = append(data.SelectionRanges, spn)
+}
+
 func (data *Data) collectImports(spn span.Span) {
 	data.Imports = append(data.Imports, spn)
 }
@@ -1169,6 +1266,10 @@ func (data *Data) collectMethodExtractions(start span.Span, end span.Span) {
 	}
 }
 
+func (data *Data) collectStructTags(spn span.Span, add, casing string) {
+	data.StructTags[spn] = StructTag{Add: add, Case: casing}
+}
+
 func (data *Data) collectDefinitions(src, target span.Span) {
 	data.Definitions[src] = Definition{
 		Src: src,
@@ -1260,6 +1361,11 @@ func (data *Data) collectHighlights(src span.Span, expected []span.Span) {
 	data.Highlights[src] = append(data.Highlights[src], expected...)
 }
 
+func (data *Data) collectLinkedEditingRanges(src span.Span, expected []span.Span) {
+	// Declaring linked ranges in a test file: @linkedediting(src, expected1, expected2)
+	data.LinkedEditingRanges[src] = append(data.LinkedEditingRanges[src], expected...)
+}
+
 func (data *Data) collectReferences(src span.Span, expected []span.Span) {
 	data.References[src] = expected
 }
@@ -293,6 +293,28 @@ func DiffCallHierarchyItems(gotCalls []protocol.CallHierarchyItem, expectedCalls
 	return ""
 }
 
+// DiffSpans returns the difference between the expected and actual spans,
+// ignoring their order.
+func DiffSpans(want, got []span.Span) string {
+	sortSpans := func(spans []span.Span) []span.Span {
+		spans = append([]span.Span(nil), spans...)
+		sort.Slice(spans, func(i, j int) bool {
+			return span.Compare(spans[i], spans[j]) < 0
+		})
+		return spans
+	}
+	want, got = sortSpans(want), sortSpans(got)
+	if len(want) != len(got) {
+		return fmt.Sprintf("expected %d spans but got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if span.Compare(want[i], got[i]) != 0 {
+			return fmt.Sprintf("expected span %v but got %v", want[i], got[i])
+		}
+	}
+	return ""
+}
+
 func ToProtocolCompletionItems(items []completion.CompletionItem) []protocol.CompletionItem {
 	var result []protocol.CompletionItem
 	for _, item := range items {
@@ -527,6 +549,26 @@ func WorkspaceSymbolsString(ctx context.Context, data *Data, queryURI span.URI,
 	return strings.Join(filtered, "\n") + "\n", nil
 }
 
+// SelectionRangesString formats the selection ranges rngs, ordered from
+// innermost to outermost, for comparison against a golden file. Long ranges
+// are abbreviated to their first and last few characters.
+func SelectionRangesString(m *protocol.ColumnMapper, rngs []protocol.Range) (string, error) {
+	const maxSnippet = 30
+	var b strings.Builder
+	for _, rng := range rngs {
+		spn, err := m.RangeSpan(rng)
+		if err != nil {
+			return "", err
+		}
+		snippet := string(m.Content[spn.Start().Offset():spn.End().Offset()])
+		if len(snippet) > maxSnippet {
+			snippet = snippet[:maxSnippet/2] + "..." + snippet[len(snippet)-maxSnippet/2:]
+		}
+		fmt.Fprintf(&b, "%d:%d-%d:%d %q\n", spn.Start().Line(), spn.Start().Column(), spn.End().Line(), spn.End().Column(), snippet)
+	}
+	return b.String(), nil
+}
+
 func WorkspaceSymbolsTestTypeToMatcher(typ WorkspaceSymbolsTestType) source.SymbolMatcher {
 	switch typ {
 	case WorkspaceSymbolsFuzzy:
@@ -8,11 +8,14 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kent0106/gotools/internal/event"
 	"github.com/kent0106/gotools/internal/jsonrpc2"
+	"github.com/kent0106/gotools/internal/lsp/debug/tag"
 	"github.com/kent0106/gotools/internal/lsp/protocol"
 	"github.com/kent0106/gotools/internal/lsp/source"
 	"github.com/kent0106/gotools/internal/span"
@@ -174,6 +177,53 @@ func (s *Server) didChangeWatchedFiles(ctx context.Context, params *protocol.Did
 	return s.didModifyFiles(ctx, modifications, FromDidChangeWatchedFiles)
 }
 
+func (s *Server) didRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) error {
+	var modifications []source.FileModification
+	for _, rename := range params.Files {
+		oldURI, newURI := span.URIFromURI(rename.OldURI), span.URIFromURI(rename.NewURI)
+		if !oldURI.IsFile() || !newURI.IsFile() {
+			continue
+		}
+		// Directories are expanded to their known files when the modifications
+		// are processed, but the files of a new directory are not yet known,
+		// so we enumerate them here, skipping the directories excluded by the
+		// directory filters of the view.
+		var folder string
+		var filters []string
+		if view, err := s.session.ViewOf(newURI); err == nil {
+			folder, filters = view.Folder().Filename(), view.Options().DirectoryFilters
+		}
+		var created []span.URI
+		filepath.Walk(newURI.Filename(), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				event.Error(ctx, "enumerating renamed files", err, tag.URI.Of(newURI))
+				return nil
+			}
+			if info.IsDir() {
+				if folder != "" && source.InDir(folder, path) && source.ExcludedByDirectoryFilters(strings.TrimPrefix(path, folder), filters) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			created = append(created, span.URIFromPath(path))
+			return nil
+		})
+		modifications = append(modifications, source.FileModification{
+			URI:    oldURI,
+			Action: source.Delete,
+			OnDisk: true,
+		})
+		for _, uri := range created {
+			modifications = append(modifications, source.FileModification{
+				URI:    uri,
+				Action: source.Create,
+				OnDisk: true,
+			})
+		}
+	}
+	return s.didModifyFiles(ctx, modifications, FromDidChangeWatchedFiles)
+}
+
 func (s *Server) didSave(ctx context.Context, params *protocol.DidSaveTextDocumentParams) error {
 	uri := params.TextDocument.URI.SpanURI()
 	if !uri.IsFile() {